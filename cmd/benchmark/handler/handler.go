@@ -0,0 +1,397 @@
+// Package handler runs a benchmark request: it creates the database adapter, dispatches
+// the requested operation to its strategy and builds the response with the collected
+// metrics. The benchmark function and the per-operation Lambda binaries share it so
+// cold-start tracking, metrics and batching follow a single code path.
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/cmd/benchmark/operations"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/adapters"
+)
+
+// BenchmarkRequest represents a configurable benchmark request
+type BenchmarkRequest struct {
+	DatabaseType  string                 `json:"databaseType"`        // dynamodb, immudb, timestream, redis
+	OperationType string                 `json:"operationType"`       // read-sequential, read-parallel, write, write-batch, update, delete, delete-parallel, mixed, scan, seed, transact-read, query, query-gsi, query-amount, aggregate, count, conditional-write, cold-start
+	Operation     string                 `json:"operation,omitempty"` // Alias of operationType, used when operationType is empty
+	Parameters    map[string]interface{} `json:"parameters"`
+
+	// Set instead of the fields above when the runner sends a compressed request
+	ContentEncoding string `json:"contentEncoding,omitempty"` // gzip
+	Payload         []byte `json:"payload,omitempty"`         // Compressed JSON request, base64-encoded in the envelope
+}
+
+// decodeRequest returns the request held in the compressed payload of request
+func decodeRequest(request BenchmarkRequest) (BenchmarkRequest, error) {
+	if request.ContentEncoding != "gzip" {
+		return request, fmt.Errorf("unsupported content encoding %q", request.ContentEncoding)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(request.Payload))
+	if err != nil {
+		return request, fmt.Errorf("failed to read gzip payload: %w", err)
+	}
+	defer zr.Close()
+
+	var decoded BenchmarkRequest
+	if err := json.NewDecoder(zr).Decode(&decoded); err != nil {
+		return request, fmt.Errorf("failed to decode compressed request: %w", err)
+	}
+	return decoded, nil
+}
+
+// BenchmarkResponse represents the result of a benchmark
+type BenchmarkResponse struct {
+	OperationType          string                 `json:"operationType"`
+	DatabaseType           string                 `json:"databaseType"`
+	Success                bool                   `json:"success"`
+	ErrorMessage           string                 `json:"errorMessage,omitempty"`
+	ItemsProcessed         int                    `json:"itemsProcessed"`
+	TotalDurationNs        int64                  `json:"totalDurationNs"`
+	AvgOperationDurationNs int64                  `json:"avgOperationDurationNs"`
+	Throughput             float64                `json:"throughput"`             // operations per second
+	StoppedEarly           string                 `json:"stoppedEarly,omitempty"` // set when the deadline cut the run short
+	Skipped                bool                   `json:"skipped,omitempty"`      // set when the database does not support the operation
+	SkipReason             string                 `json:"skipReason,omitempty"`
+	Metrics                map[string]interface{} `json:"metrics,omitempty"`
+}
+
+var (
+	// Global metrics collector
+	metricsCollector *metrics.Collector
+
+	// Track cold start
+	isColdStart = true
+
+	// Lifecycle logger; JSON lines when LOG_FORMAT=json, otherwise text through the log package
+	logger *slog.Logger
+)
+
+func init() {
+	// Initialize metrics collector
+	metricsCollector = metrics.NewCollector()
+
+	// Set up logging
+	log.SetOutput(os.Stdout)
+	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.Llongfile)
+	logger = newLogger(os.Getenv("LOG_FORMAT"), os.Stdout)
+
+	logger.Info("Lambda benchmark function initialized", "phase", "init")
+}
+
+// newLogger returns a JSON logger writing to w for format "json", and the default
+// text logger (which writes through the log package) otherwise
+func newLogger(format string, w io.Writer) *slog.Logger {
+	if strings.EqualFold(format, "json") {
+		return slog.New(slog.NewJSONHandler(w, nil))
+	}
+	return slog.Default()
+}
+
+// durationMs converts a duration to fractional milliseconds for log fields
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// defaultMaxRawOperations caps the per-operation records returned with includeRawMetrics
+const defaultMaxRawOperations = 1000
+
+// rawOperation is a per-operation record returned when includeRawMetrics is set
+type rawOperation struct {
+	Type          metrics.OperationType  `json:"type"`
+	StartTime     time.Time              `json:"startTime"`
+	DurationNs    int64                  `json:"durationNs"`
+	ItemCount     int64                  `json:"itemCount"`
+	ByteCount     int64                  `json:"byteCount"`
+	IsColdStart   bool                   `json:"isColdStart"`
+	IsWarmup      bool                   `json:"isWarmup,omitempty"`
+	ErrorMessage  string                 `json:"errorMessage,omitempty"`
+	ErrorCategory metrics.ErrorCategory  `json:"errorCategory,omitempty"`
+	CustomMetrics map[string]interface{} `json:"customMetrics,omitempty"` // e.g. awsRequestId
+}
+
+// rawOperations converts up to limit operation metrics into response records and
+// reports whether the list was truncated
+func rawOperations(ops []*metrics.OperationMetric, limit int) ([]rawOperation, bool) {
+	truncated := false
+	if limit >= 0 && len(ops) > limit {
+		ops = ops[:limit]
+		truncated = true
+	}
+
+	raw := make([]rawOperation, 0, len(ops))
+	for _, op := range ops {
+		raw = append(raw, rawOperation{
+			Type:          op.Type,
+			StartTime:     op.StartTime,
+			DurationNs:    op.Duration.Nanoseconds(),
+			ItemCount:     op.ItemCount,
+			ByteCount:     op.ByteCount,
+			IsColdStart:   op.IsColdStart,
+			IsWarmup:      op.IsWarmup,
+			ErrorMessage:  op.ErrorMessage,
+			ErrorCategory: op.ErrorCategory,
+			CustomMetrics: op.CustomMetrics,
+		})
+	}
+	return raw, truncated
+}
+
+// intParam reads an integer request parameter, which JSON decodes as float64
+func intParam(params map[string]interface{}, key string, defaultValue int) int {
+	switch v := params[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return defaultValue
+}
+
+// stringListParam reads a string list request parameter, which JSON decodes as []interface{}
+func stringListParam(params map[string]interface{}, key string) []string {
+	switch v := params[key].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// dbMetricKeys lists the adapter metrics copied into the benchmark response
+var dbMetricKeys = []string{
+	"readCapacityUnits",
+	"writeCapacityUnits",
+	"throttledOperations",
+	"throttlingExceptions",
+	"conditionalCheckFailed",
+	"failedOperations",
+	"totalOperations",
+}
+
+// selectDBMetrics returns the given keys present in the adapter metrics, or the
+// dbMetricKeys when no keys are given
+func selectDBMetrics(adapterMetrics map[string]interface{}, keys []string) map[string]interface{} {
+	if len(keys) == 0 {
+		keys = dbMetricKeys
+	}
+
+	selected := make(map[string]interface{})
+	for _, key := range keys {
+		if v, ok := adapterMetrics[key]; ok {
+			selected[key] = v
+		}
+	}
+	return selected
+}
+
+// operationFactory builds the operation strategies; RegisterOperation adds to it
+var operationFactory = operations.NewOperationFactory()
+
+// CreateOperationStrategy creates the appropriate operation strategy based on the request
+func CreateOperationStrategy(opType string, params map[string]interface{}) (operations.Operation, error) {
+	// Default parameters
+	defaultParams := map[string]interface{}{
+		"concurrency":    10,
+		"itemCount":      100,
+		"dataSize":       1024, // 1KB
+		"consistentRead": true,
+	}
+
+	// Merge with provided parameters
+	for k, v := range params {
+		if !strings.HasPrefix(k, "db.") {
+			defaultParams[k] = v
+		}
+	}
+
+	// Create the operation registered for the type
+	return operationFactory.CreateOperation(opType, defaultParams)
+}
+
+// RegisterOperation makes a custom operation type available to requests. The builder
+// receives the request parameters merged with the handler defaults.
+func RegisterOperation(opType string, builder func(map[string]interface{}) operations.Operation) {
+	operationFactory.Register(opType, builder)
+}
+
+// HandleRequest is the Lambda handler function
+func HandleRequest(ctx context.Context, request BenchmarkRequest) (BenchmarkResponse, error) {
+	response, _, err := Run(ctx, request)
+	return response, err
+}
+
+// Run executes the benchmark request and also returns the operation result, whose Data
+// holds operation-specific details, such as the IDs read or the not-found count, that
+// the response leaves out
+func Run(ctx context.Context, request BenchmarkRequest) (BenchmarkResponse, operations.OperationResult, error) {
+	startTime := time.Now()
+	var result operations.OperationResult
+	if request.ContentEncoding != "" {
+		decoded, err := decodeRequest(request)
+		if err != nil {
+			return BenchmarkResponse{ErrorMessage: fmt.Sprintf("Invalid request: %v", err)}, result, nil
+		}
+		request = decoded
+	}
+	if request.OperationType == "" {
+		request.OperationType = request.Operation
+	}
+	reqLog := logger.With("database", request.DatabaseType, "operation", request.OperationType)
+	reqLog.Info("benchmark request received", "phase", "received", "parameters", request.Parameters)
+
+	// Initialize response
+	response := BenchmarkResponse{
+		OperationType: request.OperationType,
+		DatabaseType:  request.DatabaseType,
+		Success:       false,
+	}
+
+	// A request with a null or missing parameters field still runs with the defaults
+	if request.Parameters == nil {
+		request.Parameters = make(map[string]interface{})
+	}
+
+	if request.DatabaseType == "" || request.OperationType == "" {
+		errMsg := "Invalid request: databaseType and operationType are required"
+		reqLog.Error(errMsg, "phase", "received")
+		response.ErrorMessage = errMsg
+		return response, result, nil
+	}
+
+	// Bucket size of the throughput series in the metrics summary
+	metricsCollector.SetThroughputBucket(time.Duration(intParam(request.Parameters, "throughputBucketMs", 0)) * time.Millisecond)
+
+	// Start test for metrics collection
+	testName := fmt.Sprintf("%s-%s-%s", request.DatabaseType, request.OperationType, time.Now().Format(time.RFC3339))
+	metricsCollector.StartTest(
+		testName,
+		fmt.Sprintf("%s operations on %s", request.OperationType, request.DatabaseType),
+		request.DatabaseType,
+		map[string]interface{}{
+			"region":        os.Getenv("AWS_REGION"),
+			"operationType": request.OperationType,
+		},
+		request.Parameters,
+	)
+
+	// Create database adapter
+	adapterStart := time.Now()
+	db, err := adapters.Create(ctx, request.DatabaseType, request.Parameters)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to create database adapter: %v", err)
+		reqLog.Error(errMsg, "phase", "adapter", "durationMs", durationMs(time.Since(adapterStart)))
+		response.ErrorMessage = errMsg
+		return response, result, nil
+	}
+	defer db.Close()
+	reqLog.Info("database adapter created", "phase", "adapter", "durationMs", durationMs(time.Since(adapterStart)))
+
+	// Add cold start parameter
+	request.Parameters["isColdStart"] = isColdStart
+
+	// Create operation strategy
+	op, err := CreateOperationStrategy(request.OperationType, request.Parameters)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to create operation strategy: %v", err)
+		reqLog.Error(errMsg, "phase", "strategy")
+		response.ErrorMessage = errMsg
+		return response, result, nil
+	}
+
+	// Execute the operation
+	result, err = op.Execute(ctx, db, metricsCollector)
+	if err != nil {
+		errMsg := fmt.Sprintf("Operation execution failed: %v", err)
+		reqLog.Error(errMsg, "phase", "execute", "durationMs", durationMs(result.TotalDuration))
+		response.ErrorMessage = errMsg
+		return response, result, nil
+	}
+	reqLog.Info("operation complete",
+		"phase", "execute",
+		"durationMs", durationMs(result.TotalDuration),
+		"itemsProcessed", result.ItemsProcessed,
+		"errors", len(result.Errors),
+	)
+
+	// Get metrics
+	collectMetrics := true
+	if v, ok := request.Parameters["collectMetrics"]; ok {
+		if b, ok := v.(bool); ok {
+			collectMetrics = b
+		}
+	}
+
+	testResult := metricsCollector.EndTest(testName)
+	if testResult != nil && collectMetrics {
+		response.Metrics = testResult.Summary
+		// Include adapter-level counters such as consumed capacity and throttling
+		metricKeys := stringListParam(request.Parameters, "metricKeys")
+		if dbMetrics := selectDBMetrics(db.GetMetrics(), metricKeys); len(dbMetrics) > 0 {
+			response.Metrics["dbMetrics"] = dbMetrics
+		}
+
+		// Optionally return per-operation metrics, capped to keep the payload bounded
+		if includeRaw, _ := request.Parameters["includeRawMetrics"].(bool); includeRaw {
+			limit := intParam(request.Parameters, "maxRawOperations", defaultMaxRawOperations)
+			raw, truncated := rawOperations(testResult.Operations, limit)
+			response.Metrics["operations"] = raw
+			if truncated {
+				response.Metrics["operationsTruncated"] = true
+				response.Metrics["operationsTotal"] = len(testResult.Operations)
+			}
+		}
+	}
+
+	// Emit CloudWatch Embedded Metric Format logs if enabled
+	if testResult != nil && os.Getenv("EMIT_EMF") == "true" {
+		if err := metrics.EmitEMF(testResult, os.Stdout); err != nil {
+			reqLog.Warn("failed to emit EMF metrics", "phase", "metrics", "error", err)
+		}
+	}
+
+	// Populate response
+	response.Success = true
+	response.ItemsProcessed = result.ItemsProcessed
+	if note, ok := result.Data["stoppedEarly"].(string); ok {
+		response.StoppedEarly = note
+	}
+	if skipped, _ := result.Data["skipped"].(bool); skipped {
+		response.Skipped = true
+		response.SkipReason, _ = result.Data["skipReason"].(string)
+	}
+	response.TotalDurationNs = result.TotalDuration.Nanoseconds()
+	if result.ItemsProcessed > 0 {
+		response.AvgOperationDurationNs = result.TotalDuration.Nanoseconds() / int64(result.ItemsProcessed)
+		response.Throughput = float64(result.ItemsProcessed) / result.TotalDuration.Seconds()
+	}
+
+	// Log execution time
+	elapsed := time.Since(startTime)
+	reqLog.Info("benchmark completed", "phase", "done", "durationMs", durationMs(elapsed), "throughput", response.Throughput)
+
+	// Reset cold start flag after first invocation
+	isColdStart = false
+
+	return response, result, nil
+}
@@ -0,0 +1,470 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/cmd/benchmark/operations"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// paramsOperation is a custom operation that returns the parameters it was built with
+type paramsOperation struct {
+	params map[string]interface{}
+}
+
+func (op *paramsOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (operations.OperationResult, error) {
+	return operations.OperationResult{Data: op.params}, nil
+}
+
+func TestRegisterOperation(t *testing.T) {
+	RegisterOperation("handler-test-params", func(params map[string]interface{}) operations.Operation {
+		return &paramsOperation{params: params}
+	})
+
+	op, err := CreateOperationStrategy("Handler-Test-Params", map[string]interface{}{
+		"itemCount":    5,
+		"db.tableName": "transactions",
+		"custom":       "value",
+	})
+	if err != nil {
+		t.Fatalf("CreateOperationStrategy() error = %v", err)
+	}
+	result, err := op.Execute(context.Background(), nil, metrics.NewCollector())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// The builder receives the request parameters merged with the defaults, without db.* keys
+	tests := []struct {
+		key     string
+		want    interface{}
+		present bool
+	}{
+		{"itemCount", 5, true},
+		{"custom", "value", true},
+		{"concurrency", 10, true},
+		{"dataSize", 1024, true},
+		{"consistentRead", true, true},
+		{"db.tableName", nil, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := result.Data[tt.key]
+		if ok != tt.present {
+			t.Errorf("parameter %q present = %v, want %v", tt.key, ok, tt.present)
+			continue
+		}
+		if ok && got != tt.want {
+			t.Errorf("parameter %q = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCreateOperationStrategy(t *testing.T) {
+	tests := []struct {
+		opType  string
+		wantErr bool
+	}{
+		{"read-sequential", false},
+		{"read-parallel", false},
+		{"write-batch", false},
+		{"delete-parallel", false},
+		{"MIXED", false},
+		{"cold-start", false},
+		{"no-such-operation", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.opType, func(t *testing.T) {
+			op, err := CreateOperationStrategy(tt.opType, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("CreateOperationStrategy(%q) error = nil, want an error", tt.opType)
+				}
+				return
+			}
+			if err != nil || op == nil {
+				t.Errorf("CreateOperationStrategy(%q) = %v, %v, want an operation", tt.opType, op, err)
+			}
+		})
+	}
+}
+
+// newTestDB registers an in-memory database under the "handler-test" type
+func newTestDB(t *testing.T) *dbtest.DB {
+	t.Helper()
+	db := dbtest.New()
+	dbtest.Register("handler-test", db)
+	return db
+}
+
+func TestRunNilParameters(t *testing.T) {
+	db := newTestDB(t)
+
+	response, _, err := Run(context.Background(), BenchmarkRequest{
+		DatabaseType:  "handler-test",
+		OperationType: "write",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !response.Success {
+		t.Fatalf("Run() failed: %s", response.ErrorMessage)
+	}
+
+	// The handler defaults apply when the request has no parameters
+	if response.ItemsProcessed != 100 {
+		t.Errorf("ItemsProcessed = %d, want the default itemCount of 100", response.ItemsProcessed)
+	}
+	if db.Len() != 100 {
+		t.Errorf("stored %d transactions, want 100", db.Len())
+	}
+}
+
+func TestRunInvalidRequest(t *testing.T) {
+	db := newTestDB(t)
+
+	tests := []struct {
+		name    string
+		request BenchmarkRequest
+		want    string
+	}{
+		{
+			"empty database type",
+			BenchmarkRequest{OperationType: "write"},
+			"Invalid request: databaseType and operationType are required",
+		},
+		{
+			"empty operation type",
+			BenchmarkRequest{DatabaseType: "handler-test"},
+			"Invalid request: databaseType and operationType are required",
+		},
+		{
+			"unknown database type",
+			BenchmarkRequest{DatabaseType: "handler-test-missing", OperationType: "write"},
+			"Failed to create database adapter: unsupported database type: handler-test-missing",
+		},
+		{
+			"unknown operation",
+			BenchmarkRequest{DatabaseType: "handler-test", OperationType: "no-such-operation"},
+			"Failed to create operation strategy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, _, err := Run(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("Run() error = %v, want the failure in the response", err)
+			}
+			if response.Success {
+				t.Fatal("Run() succeeded, want a failure")
+			}
+			if !strings.HasPrefix(response.ErrorMessage, tt.want) {
+				t.Errorf("ErrorMessage = %q, want prefix %q", response.ErrorMessage, tt.want)
+			}
+		})
+	}
+
+	// Only the unknown operation got as far as creating the database, which is closed again
+	if db.Initialized() != 1 || db.Closed() != 1 {
+		t.Errorf("database initialized %d and closed %d times, want 1 and 1", db.Initialized(), db.Closed())
+	}
+}
+
+func TestRunDBMetrics(t *testing.T) {
+	db := newTestDB(t)
+	db.Metrics = map[string]interface{}{
+		"writeCapacityUnits": 100.0,
+		"totalOperations":    100,
+		"cacheHits":          7, // Not a default key
+	}
+
+	response, _, err := Run(context.Background(), BenchmarkRequest{
+		DatabaseType:  "handler-test",
+		OperationType: "write",
+	})
+	if err != nil || !response.Success {
+		t.Fatalf("Run() = %+v, %v, want success", response, err)
+	}
+
+	dbMetrics, ok := response.Metrics["dbMetrics"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("metrics.dbMetrics = %v, want the adapter metrics", response.Metrics["dbMetrics"])
+	}
+	want := map[string]interface{}{
+		"writeCapacityUnits": 100.0,
+		"totalOperations":    100,
+	}
+	if len(dbMetrics) != len(want) {
+		t.Errorf("dbMetrics = %v, want %v", dbMetrics, want)
+	}
+	for key, value := range want {
+		if dbMetrics[key] != value {
+			t.Errorf("dbMetrics[%q] = %v, want %v", key, dbMetrics[key], value)
+		}
+	}
+}
+
+// captureLogs swaps the handler logger for a JSON logger writing to the returned buffer
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := logger
+	logger = newLogger("json", &buf)
+	t.Cleanup(func() { logger = previous })
+	return &buf
+}
+
+// logEntries decodes the JSON log lines in buf keyed by message
+func logEntries(t *testing.T, buf *bytes.Buffer) map[string]map[string]interface{} {
+	t.Helper()
+	entries := make(map[string]map[string]interface{})
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		msg, _ := entry["msg"].(string)
+		entries[msg] = entry
+	}
+	return entries
+}
+
+func TestRunStructuredLogs(t *testing.T) {
+	newTestDB(t)
+	buf := captureLogs(t)
+
+	response, _, err := Run(context.Background(), BenchmarkRequest{
+		DatabaseType:  "handler-test",
+		OperationType: "write",
+		Parameters:    map[string]interface{}{"itemCount": 5},
+	})
+	if err != nil || !response.Success {
+		t.Fatalf("Run() = %+v, %v, want success", response, err)
+	}
+
+	entries := logEntries(t, buf)
+	tests := []struct {
+		msg         string
+		phase       string
+		hasDuration bool
+	}{
+		{"benchmark request received", "received", false},
+		{"database adapter created", "adapter", true},
+		{"operation complete", "execute", true},
+		{"benchmark completed", "done", true},
+	}
+
+	for _, tt := range tests {
+		entry, ok := entries[tt.msg]
+		if !ok {
+			t.Errorf("no %q event in the logs:\n%s", tt.msg, buf)
+			continue
+		}
+		if entry["level"] != "INFO" || entry["phase"] != tt.phase {
+			t.Errorf("%q level = %v, phase = %v, want INFO and %s", tt.msg, entry["level"], entry["phase"], tt.phase)
+		}
+		if entry["database"] != "handler-test" || entry["operation"] != "write" {
+			t.Errorf("%q database = %v, operation = %v, want handler-test and write", tt.msg, entry["database"], entry["operation"])
+		}
+		if _, ok := entry["durationMs"].(float64); ok != tt.hasDuration {
+			t.Errorf("%q durationMs = %v, want present = %v", tt.msg, entry["durationMs"], tt.hasDuration)
+		}
+	}
+	if got := entries["operation complete"]["itemsProcessed"]; got != 5.0 {
+		t.Errorf("itemsProcessed = %v, want 5", got)
+	}
+}
+
+func TestRunStructuredErrorLog(t *testing.T) {
+	buf := captureLogs(t)
+
+	if _, _, err := Run(context.Background(), BenchmarkRequest{DatabaseType: "handler-test"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	entry, ok := logEntries(t, buf)["Invalid request: databaseType and operationType are required"]
+	if !ok {
+		t.Fatalf("no invalid request event in the logs:\n%s", buf)
+	}
+	if entry["level"] != "ERROR" || entry["phase"] != "received" {
+		t.Errorf("level = %v, phase = %v, want ERROR and received", entry["level"], entry["phase"])
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	if newLogger("", &buf) != slog.Default() || newLogger("text", &buf) != slog.Default() {
+		t.Error("newLogger() without LOG_FORMAT=json should return the default text logger")
+	}
+	newLogger("JSON", &buf).Info("hello")
+	if !json.Valid(buf.Bytes()) {
+		t.Errorf("newLogger(%q) wrote %q, want a JSON line", "JSON", buf.String())
+	}
+}
+
+func TestRunRawMetrics(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        map[string]interface{}
+		wantOps       int // -1 when no raw operations are returned
+		wantTruncated bool
+	}{
+		{"not requested", map[string]interface{}{}, -1, false},
+		{"all operations", map[string]interface{}{"includeRawMetrics": true}, 5, false},
+		// JSON requests decode the cap as float64
+		{"capped", map[string]interface{}{"includeRawMetrics": true, "maxRawOperations": float64(3)}, 3, true},
+		{"cap equal to the count", map[string]interface{}{"includeRawMetrics": true, "maxRawOperations": 5}, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			// The second write fails so its error is carried into the raw record
+			db.Hook = func(ctx context.Context, method, uuid string) error {
+				if method == "WriteTransaction" && uuid == "test-account-tx-1" {
+					return context.DeadlineExceeded
+				}
+				return nil
+			}
+
+			tt.params["itemCount"] = 5
+			tt.params["concurrency"] = 1
+			response, _, err := Run(context.Background(), BenchmarkRequest{
+				DatabaseType:  "handler-test",
+				OperationType: "write",
+				Parameters:    tt.params,
+			})
+			if err != nil || !response.Success {
+				t.Fatalf("Run() = %+v, %v, want success", response, err)
+			}
+
+			raw, ok := response.Metrics["operations"].([]rawOperation)
+			if tt.wantOps < 0 {
+				if ok {
+					t.Errorf("operations = %v, want none without includeRawMetrics", raw)
+				}
+				return
+			}
+			if !ok || len(raw) != tt.wantOps {
+				t.Fatalf("operations = %v, want %d records", response.Metrics["operations"], tt.wantOps)
+			}
+
+			failed := 0
+			for _, op := range raw {
+				if op.Type != metrics.WriteOperation || op.ItemCount != 1 || op.ByteCount <= 0 || op.DurationNs <= 0 {
+					t.Errorf("raw operation = %+v, want a measured single write", op)
+				}
+				if op.ErrorMessage != "" {
+					failed++
+					if op.ErrorCategory != metrics.ErrorCategoryTimeout {
+						t.Errorf("raw operation error category = %q, want %q", op.ErrorCategory, metrics.ErrorCategoryTimeout)
+					}
+				}
+			}
+			if failed != 1 {
+				t.Errorf("%d raw operations failed, want 1", failed)
+			}
+
+			truncated, _ := response.Metrics["operationsTruncated"].(bool)
+			if truncated != tt.wantTruncated {
+				t.Errorf("operationsTruncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if total, ok := response.Metrics["operationsTotal"]; ok != tt.wantTruncated || (ok && total != 5) {
+				t.Errorf("operationsTotal = %v, want 5 only when truncated", total)
+			}
+		})
+	}
+}
+
+func TestRunMetricKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		metricKeys interface{}
+		want       map[string]interface{}
+	}{
+		{
+			"default keys",
+			nil,
+			map[string]interface{}{"writeCapacityUnits": 100.0, "throttledOperations": 2, "totalOperations": 100},
+		},
+		{
+			// JSON requests decode the list as []interface{}
+			"requested keys",
+			[]interface{}{"throttledOperations", "writeCapacityUnits"},
+			map[string]interface{}{"throttledOperations": 2, "writeCapacityUnits": 100.0},
+		},
+		{
+			"key outside the defaults",
+			[]string{"cacheHits"},
+			map[string]interface{}{"cacheHits": 7},
+		},
+		{
+			"missing keys are left out",
+			[]string{"totalOperations", "noSuchMetric"},
+			map[string]interface{}{"totalOperations": 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			db.Metrics = map[string]interface{}{
+				"writeCapacityUnits":  100.0,
+				"throttledOperations": 2,
+				"totalOperations":     100,
+				"cacheHits":           7,
+			}
+
+			params := map[string]interface{}{"itemCount": 5}
+			if tt.metricKeys != nil {
+				params["metricKeys"] = tt.metricKeys
+			}
+			response, _, err := Run(context.Background(), BenchmarkRequest{
+				DatabaseType:  "handler-test",
+				OperationType: "write",
+				Parameters:    params,
+			})
+			if err != nil || !response.Success {
+				t.Fatalf("Run() = %+v, %v, want success", response, err)
+			}
+
+			dbMetrics, _ := response.Metrics["dbMetrics"].(map[string]interface{})
+			if len(dbMetrics) != len(tt.want) {
+				t.Errorf("dbMetrics = %v, want %v", dbMetrics, tt.want)
+			}
+			for key, value := range tt.want {
+				if dbMetrics[key] != value {
+					t.Errorf("dbMetrics[%q] = %v, want %v", key, dbMetrics[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestRunMetricKeysNoMatch(t *testing.T) {
+	db := newTestDB(t)
+	db.Metrics = map[string]interface{}{"totalOperations": 100}
+
+	response, _, err := Run(context.Background(), BenchmarkRequest{
+		DatabaseType:  "handler-test",
+		OperationType: "write",
+		Parameters:    map[string]interface{}{"itemCount": 5, "metricKeys": []string{"noSuchMetric"}},
+	})
+	if err != nil || !response.Success {
+		t.Fatalf("Run() = %+v, %v, want success", response, err)
+	}
+
+	// An empty selection leaves dbMetrics out rather than returning an empty map
+	if got, ok := response.Metrics["dbMetrics"]; ok {
+		t.Errorf("dbMetrics = %v, want it left out", got)
+	}
+}
@@ -2,10 +2,15 @@ package operations
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 )
 
-// OperationFactory creates operation instances based on type
+// OperationFactory creates operation instances based on type. Operation types are
+// case-insensitive, and it is safe to register operations while others are created.
 type OperationFactory struct {
+	mu       sync.RWMutex
 	builders map[string]func(map[string]interface{}) Operation
 }
 
@@ -19,17 +24,68 @@ func NewOperationFactory() *OperationFactory {
 	factory.Register("read", func(params map[string]interface{}) Operation {
 		return NewReadOperation(params, getParam(params, "parallel", false))
 	})
+	factory.Register("read-sequential", func(params map[string]interface{}) Operation {
+		return NewReadOperation(params, false)
+	})
+	factory.Register("read-parallel", func(params map[string]interface{}) Operation {
+		return NewReadOperation(params, true)
+	})
 	factory.Register("write", func(params map[string]interface{}) Operation {
 		return NewWriteOperation(params, getParam(params, "batch", false))
 	})
+	factory.Register("write-batch", func(params map[string]interface{}) Operation {
+		return NewWriteOperation(params, true)
+	})
+	factory.Register("update", func(params map[string]interface{}) Operation {
+		return NewUpdateOperation(params)
+	})
+	factory.Register("delete", func(params map[string]interface{}) Operation {
+		return NewDeleteOperation(params, getParam(params, "parallel", false))
+	})
+	factory.Register("delete-parallel", func(params map[string]interface{}) Operation {
+		return NewDeleteOperation(params, true)
+	})
+	factory.Register("mixed", func(params map[string]interface{}) Operation {
+		return NewMixedOperation(params)
+	})
+	factory.Register("scan", func(params map[string]interface{}) Operation {
+		return NewScanOperation(params)
+	})
+	factory.Register("seed", func(params map[string]interface{}) Operation {
+		return NewSeedOperation(params)
+	})
+	factory.Register("transact-read", func(params map[string]interface{}) Operation {
+		return NewTransactReadOperation(params)
+	})
 	factory.Register("query", func(params map[string]interface{}) Operation {
 		return NewQueryOperation(params)
 	})
+	factory.Register("query-gsi", func(params map[string]interface{}) Operation {
+		return NewQueryGSIOperation(params)
+	})
+	factory.Register("query-amount", func(params map[string]interface{}) Operation {
+		return NewQueryAmountOperation(params)
+	})
+	factory.Register("aggregate", func(params map[string]interface{}) Operation {
+		return NewAggregateOperation(params)
+	})
+	factory.Register("count", func(params map[string]interface{}) Operation {
+		return NewCountOperation(params)
+	})
+	factory.Register("conditional-write", func(params map[string]interface{}) Operation {
+		return NewConditionalWriteOperation(params)
+	})
+	factory.Register("cold-start", func(params map[string]interface{}) Operation {
+		return NewColdStartOperation(params)
+	})
 
 	// Register ImmuDB-specific operations
 	factory.Register("immudb_write", func(params map[string]interface{}) Operation {
 		return NewImmuDBWriteOperation(params)
 	})
+	factory.Register("immudb_verified_write", func(params map[string]interface{}) Operation {
+		return NewImmuDBVerifiedWriteOperation(params)
+	})
 	factory.Register("immudb_read", func(params map[string]interface{}) Operation {
 		return NewImmuDBReadOperation(params)
 	})
@@ -40,16 +96,34 @@ func NewOperationFactory() *OperationFactory {
 	return factory
 }
 
-// Register adds a new operation builder to the factory
+// Register adds a new operation builder to the factory, replacing any builder already
+// registered for opType
 func (f *OperationFactory) Register(opType string, builder func(map[string]interface{}) Operation) {
-	f.builders[opType] = builder
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.builders[strings.ToLower(opType)] = builder
 }
 
 // CreateOperation creates a new operation instance based on type
 func (f *OperationFactory) CreateOperation(opType string, params map[string]interface{}) (Operation, error) {
-	builder, ok := f.builders[opType]
+	f.mu.RLock()
+	builder, ok := f.builders[strings.ToLower(opType)]
+	f.mu.RUnlock()
 	if !ok {
 		return nil, fmt.Errorf("unknown operation type: %s", opType)
 	}
 	return builder(params), nil
 }
+
+// Types returns the sorted operation types registered with the factory
+func (f *OperationFactory) Types() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	types := make([]string, 0, len(f.builders))
+	for opType := range f.builders {
+		types = append(types, opType)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,134 @@
+package operations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// countingOperation is a custom operation that reports the itemCount parameter
+type countingOperation struct {
+	params map[string]interface{}
+}
+
+func (op *countingOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	return OperationResult{
+		ItemsProcessed: getParam(op.params, "itemCount", 0),
+		Data:           map[string]interface{}{"custom": true},
+	}, nil
+}
+
+func TestOperationFactoryBuiltins(t *testing.T) {
+	factory := NewOperationFactory()
+
+	tests := []struct {
+		opType       string
+		params       map[string]interface{}
+		wantParallel bool
+		check        func(Operation) (baseOperation, bool)
+	}{
+		{"read", nil, false, readBase},
+		{"read", map[string]interface{}{"parallel": true}, true, readBase},
+		{"read-sequential", nil, false, readBase},
+		{"read-parallel", nil, true, readBase},
+		{"READ-PARALLEL", nil, true, readBase},
+		{"write", nil, false, writeBase},
+		{"write-batch", nil, true, writeBase},
+		{"delete", nil, false, deleteBase},
+		{"delete-parallel", nil, true, deleteBase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.opType, func(t *testing.T) {
+			op, err := factory.CreateOperation(tt.opType, tt.params)
+			if err != nil {
+				t.Fatalf("CreateOperation(%q) error = %v", tt.opType, err)
+			}
+			base, ok := tt.check(op)
+			if !ok {
+				t.Fatalf("CreateOperation(%q) = %T, want a different operation type", tt.opType, op)
+			}
+			if base.isParallel != tt.wantParallel {
+				t.Errorf("CreateOperation(%q) parallel = %v, want %v", tt.opType, base.isParallel, tt.wantParallel)
+			}
+		})
+	}
+}
+
+func TestOperationFactoryUnknownType(t *testing.T) {
+	if _, err := NewOperationFactory().CreateOperation("no-such-operation", nil); err == nil {
+		t.Error("CreateOperation() error = nil, want an error for an unknown type")
+	}
+}
+
+func TestOperationFactoryRegister(t *testing.T) {
+	factory := NewOperationFactory()
+	factory.Register("Custom-Count", func(params map[string]interface{}) Operation {
+		return &countingOperation{params: params}
+	})
+
+	tests := []struct {
+		name   string
+		opType string
+		params map[string]interface{}
+		want   int
+	}{
+		{"registered name", "custom-count", map[string]interface{}{"itemCount": 7}, 7},
+		{"case-insensitive", "CUSTOM-COUNT", map[string]interface{}{"itemCount": float64(3)}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := factory.CreateOperation(tt.opType, tt.params)
+			if err != nil {
+				t.Fatalf("CreateOperation(%q) error = %v", tt.opType, err)
+			}
+			result, err := op.Execute(context.Background(), nil, metrics.NewCollector())
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.ItemsProcessed != tt.want {
+				t.Errorf("ItemsProcessed = %d, want %d", result.ItemsProcessed, tt.want)
+			}
+			if result.Data["custom"] != true {
+				t.Errorf("Data = %v, want the custom operation's result", result.Data)
+			}
+		})
+	}
+
+	// Registering a built-in name replaces it
+	factory.Register("read", func(params map[string]interface{}) Operation {
+		return &countingOperation{params: params}
+	})
+	if op, _ := factory.CreateOperation("read", nil); op == nil {
+		t.Fatal("CreateOperation(read) returned no operation")
+	} else if _, ok := op.(*countingOperation); !ok {
+		t.Errorf("CreateOperation(read) = %T, want the replacement *countingOperation", op)
+	}
+}
+
+func readBase(op Operation) (baseOperation, bool) {
+	read, ok := op.(*ReadOperation)
+	if !ok {
+		return baseOperation{}, false
+	}
+	return read.baseOperation, true
+}
+
+func writeBase(op Operation) (baseOperation, bool) {
+	write, ok := op.(*WriteOperation)
+	if !ok {
+		return baseOperation{}, false
+	}
+	return write.baseOperation, true
+}
+
+func deleteBase(op Operation) (baseOperation, bool) {
+	del, ok := op.(*DeleteOperation)
+	if !ok {
+		return baseOperation{}, false
+	}
+	return del.baseOperation, true
+}
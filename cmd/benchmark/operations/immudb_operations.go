@@ -2,8 +2,10 @@ package operations
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +18,7 @@ type ImmuDBWriteOperation struct {
 	baseOperation
 	numTransactions int
 	accountID       string
+	verified        bool
 }
 
 // NewImmuDBWriteOperation creates a new ImmuDB write operation
@@ -28,9 +31,17 @@ func NewImmuDBWriteOperation(params map[string]interface{}) Operation {
 		},
 		numTransactions: getParam(params, "numTransactions", 10),
 		accountID:       getParam(params, "accountID", fmt.Sprintf("acct-%s", uuid.New().String()[:8])),
+		verified:        getParam(params, "verified", false),
 	}
 }
 
+// NewImmuDBVerifiedWriteOperation creates an ImmuDB write operation that verifies each write
+func NewImmuDBVerifiedWriteOperation(params map[string]interface{}) Operation {
+	op := NewImmuDBWriteOperation(params).(*ImmuDBWriteOperation)
+	op.verified = true
+	return op
+}
+
 // Execute runs the ImmuDB write operation
 func (op *ImmuDBWriteOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
 	result := OperationResult{
@@ -54,10 +65,15 @@ func (op *ImmuDBWriteOperation) Execute(ctx context.Context, db databases.Databa
 	}
 	result.Data["uuids"] = uuids
 	result.Data["accountID"] = op.accountID
+	result.Data["verified"] = op.verified
+
+	writeOptions := &databases.WriteOptions{Verified: op.verified}
+	customMetrics := map[string]interface{}{"verified": op.verified}
 
 	startTime := time.Now()
 
-	// Execute operation based on parallel flag
+	// Execute operation based on parallel flag. Verified writes are issued
+	// individually since verification is per row.
 	if op.isParallel {
 		var wg sync.WaitGroup
 		errChan := make(chan error, len(transactions))
@@ -76,13 +92,16 @@ func (op *ImmuDBWriteOperation) Execute(ctx context.Context, db databases.Databa
 					txSize += 100 // Default estimate if not a string
 				}
 
-				operationErr := collector.MeasureOperation(
+				operationErr := collector.MeasureOperationWithCustomMetrics(
 					metrics.WriteOperation,
 					1, // One transaction
 					txSize,
 					false, // Not a cold start
+					customMetrics,
 					func() error {
-						return db.WriteTransaction(ctx, transaction, &databases.WriteOptions{})
+						reqCtx, cancel := withOperationTimeout(ctx, op.params)
+						defer cancel()
+						return db.WriteTransaction(reqCtx, transaction, writeOptions)
 					},
 				)
 				if operationErr != nil {
@@ -97,6 +116,32 @@ func (op *ImmuDBWriteOperation) Execute(ctx context.Context, db databases.Databa
 		for err := range errChan {
 			result.Errors = append(result.Errors, err)
 		}
+	} else if op.verified {
+		for _, tx := range transactions {
+			txSize := int64(len(tx.UUID) + len(tx.AccountID) +
+				len(tx.TransactionType) + 8)
+			if meta, ok := tx.Metadata.(string); ok {
+				txSize += int64(len(meta))
+			} else {
+				txSize += 100
+			}
+
+			err := collector.MeasureOperationWithCustomMetrics(
+				metrics.WriteOperation,
+				1, // One transaction
+				txSize,
+				false, // Not a cold start
+				customMetrics,
+				func() error {
+					reqCtx, cancel := withOperationTimeout(ctx, op.params)
+					defer cancel()
+					return db.WriteTransaction(reqCtx, tx, writeOptions)
+				},
+			)
+			if err != nil {
+				result.Errors = append(result.Errors, err)
+			}
+		}
 	} else {
 		// Estimate total size for batch metrics
 		totalSize := int64(0)
@@ -112,13 +157,16 @@ func (op *ImmuDBWriteOperation) Execute(ctx context.Context, db databases.Databa
 		}
 
 		// Batch write all transactions
-		err := collector.MeasureOperation(
+		err := collector.MeasureOperationWithCustomMetrics(
 			metrics.BatchOperation,
 			int64(len(transactions)),
 			totalSize,
 			false, // Not a cold start
+			customMetrics,
 			func() error {
-				return db.BatchWriteTransactions(ctx, transactions, &databases.BatchOptions{})
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
+				return db.BatchWriteTransactions(reqCtx, transactions, &databases.BatchOptions{})
 			},
 		)
 		if err != nil {
@@ -174,6 +222,7 @@ func (op *ImmuDBReadOperation) Execute(ctx context.Context, db databases.Databas
 	// Execute operation based on parallel flag
 	if op.isParallel {
 		var wg sync.WaitGroup
+		var notFound atomic.Int64
 		resultLock := sync.Mutex{}
 		errChan := make(chan error, len(op.uuids))
 		txChan := make(chan *databases.Transaction, len(op.uuids))
@@ -193,12 +242,16 @@ func (op *ImmuDBReadOperation) Execute(ctx context.Context, db databases.Databas
 					keySize,
 					false, // Not a cold start
 					func() error {
+						reqCtx, cancel := withOperationTimeout(ctx, op.params)
+						defer cancel()
 						var opErr error
-						tx, opErr = db.ReadTransaction(ctx, op.accountID, txid, &databases.ReadOptions{})
+						tx, opErr = db.ReadTransaction(reqCtx, op.accountID, txid, &databases.ReadOptions{})
 						return opErr
 					},
 				)
-				if err != nil {
+				if errors.Is(err, databases.ErrTransactionNotFound) {
+					notFound.Add(1)
+				} else if err != nil {
 					errChan <- err
 				} else if tx != nil {
 					txChan <- tx
@@ -219,6 +272,7 @@ func (op *ImmuDBReadOperation) Execute(ctx context.Context, db databases.Databas
 			transactions = append(transactions, tx)
 			resultLock.Unlock()
 		}
+		result.Data["notFound"] = notFound.Load()
 	} else {
 		// Create keys structure for BatchReadTransactions
 		keys := make([]struct{ AccountID, UUID string }, len(op.uuids))
@@ -240,8 +294,10 @@ func (op *ImmuDBReadOperation) Execute(ctx context.Context, db databases.Databas
 			totalKeySize,
 			false, // Not a cold start
 			func() error {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
 				var opErr error
-				transactions, opErr = db.BatchReadTransactions(ctx, keys, &databases.BatchOptions{})
+				transactions, opErr = db.BatchReadTransactions(reqCtx, keys, &databases.BatchOptions{})
 				return opErr
 			},
 		)
@@ -331,8 +387,10 @@ func (op *ImmuDBQueryOperation) Execute(ctx context.Context, db databases.Databa
 			querySize,
 			false, // Not a cold start
 			func() error {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
 				var opErr error
-				transactions, opErr = db.QueryTransactionsByTimeRange(ctx, op.accountID, op.startTime, op.endTime, &databases.QueryOptions{})
+				transactions, opErr = db.QueryTransactionsByTimeRange(reqCtx, op.accountID, op.startTime, op.endTime, &databases.QueryOptions{})
 				return opErr
 			},
 		)
@@ -344,8 +402,10 @@ func (op *ImmuDBQueryOperation) Execute(ctx context.Context, db databases.Databa
 			querySize,
 			false, // Not a cold start
 			func() error {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
 				var opErr error
-				transactions, opErr = db.QueryTransactionsByAccount(ctx, op.accountID, &databases.QueryOptions{})
+				transactions, opErr = db.QueryTransactionsByAccount(reqCtx, op.accountID, &databases.QueryOptions{})
 				return opErr
 			},
 		)
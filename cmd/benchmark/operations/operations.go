@@ -2,13 +2,18 @@ package operations
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/workload"
 	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
 )
 
@@ -34,19 +39,69 @@ type baseOperation struct {
 
 // Common utility functions for operations
 
-// getParam retrieves a parameter with type assertion and default value
+// getParam retrieves a parameter with type assertion and default value. Numbers are
+// converted between int, int64 and float64, since JSON decodes every number in the
+// request as float64.
 func getParam[T any](params map[string]interface{}, key string, defaultValue T) T {
-	if val, ok := params[key]; ok {
-		if result, ok := val.(T); ok {
-			return result
-		}
+	val, ok := params[key]
+	if !ok {
+		return defaultValue
+	}
+	if result, ok := val.(T); ok {
+		return result
+	}
+
+	var number float64
+	switch v := val.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	default:
+		return defaultValue
+	}
+
+	var converted interface{}
+	switch any(defaultValue).(type) {
+	case int:
+		converted = int(number)
+	case int64:
+		converted = int64(number)
+	case float64:
+		converted = number
+	default:
+		return defaultValue
+	}
+	return converted.(T)
+}
+
+// withOperationTimeout bounds a single measured call by the perOperationTimeoutMs
+// parameter. A call that exceeds it fails with context.DeadlineExceeded, which the
+// collector records as a timeout, and the operation moves on to the next item.
+func withOperationTimeout(ctx context.Context, params map[string]interface{}) (context.Context, context.CancelFunc) {
+	if timeoutMs := getParam(params, "perOperationTimeoutMs", 0); timeoutMs > 0 {
+		return context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	}
+	return ctx, func() {}
+}
+
+// accountForIndex returns the account of the transaction at index. With numAccounts
+// above 1, transactions are spread round-robin over accountId-0 to accountId-(N-1) so
+// writes land on different partitions instead of a single hot one.
+func accountForIndex(params map[string]interface{}, index int) string {
+	accountID := getParam(params, "accountId", "test-account")
+	numAccounts := getParam(params, "numAccounts", 1)
+	if numAccounts <= 1 {
+		return accountID
 	}
-	return defaultValue
+	return fmt.Sprintf("%s-%d", accountID, index%numAccounts)
 }
 
 // generateTransaction creates a transaction with random or specified data
 func generateTransaction(params map[string]interface{}, index int) *databases.Transaction {
-	accountID := getParam(params, "accountId", "test-account")
+	accountID := accountForIndex(params, index)
 	dataSizeBytes := getParam(params, "dataSize", 1024)
 	useRandomIDs := getParam(params, "useRandomIDs", false)
 
@@ -58,10 +113,6 @@ func generateTransaction(params map[string]interface{}, index int) *databases.Tr
 		transactionID = fmt.Sprintf("%s-tx-%d", accountID, index)
 	}
 
-	// Generate random payload of specified size
-	payload := make([]byte, dataSizeBytes)
-	rand.Read(payload)
-
 	// Create transaction
 	timestamp := time.Now()
 	return &databases.Transaction{
@@ -70,8 +121,95 @@ func generateTransaction(params map[string]interface{}, index int) *databases.Tr
 		Timestamp:       timestamp,
 		Amount:          float64(rand.Intn(10000)) / 100, // Random amount between 0-100
 		TransactionType: databases.Deposit,
-		Metadata:        payload,
+		Metadata:        generateMetadata(getParam(params, "metadataShape", "bytes"), dataSizeBytes),
+	}
+}
+
+// serializedSize returns the length of the JSON encoding of tx, which includes the
+// attribute names, base64-encoded byte payloads and other overhead on top of the
+// requested data size
+func serializedSize(tx *databases.Transaction) int {
+	encoded, err := json.Marshal(tx)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
+
+// Sample values for structured metadata
+var (
+	metadataMerchants  = []string{"Acme Grocery", "Blue Bottle Cafe", "City Transit", "Northwind Books", "Summit Outfitters"}
+	metadataCategories = []string{"groceries", "dining", "transport", "books", "outdoor"}
+	metadataCities     = []string{"Lisbon", "Seattle", "Sao Paulo", "Berlin", "Tokyo"}
+)
+
+// generateMetadata returns a transaction payload of about size bytes. The "json" shape
+// is a nested object with merchant, category and location fields padded to size when
+// serialized as JSON. DynamoDB stores it as a map and the other databases as JSON text;
+// any other shape is random bytes.
+func generateMetadata(shape string, size int) interface{} {
+	if shape != "json" {
+		payload := make([]byte, size)
+		rand.Read(payload)
+		return payload
+	}
+
+	pick := rand.Intn(len(metadataMerchants))
+	metadata := map[string]interface{}{
+		"merchant": map[string]interface{}{
+			"id":   fmt.Sprintf("m-%05d", rand.Intn(100000)),
+			"name": metadataMerchants[pick],
+		},
+		"category": metadataCategories[pick],
+		"location": map[string]interface{}{
+			"city":      metadataCities[rand.Intn(len(metadataCities))],
+			"latitude":  math.Round((rand.Float64()*180-90)*1e4) / 1e4,
+			"longitude": math.Round((rand.Float64()*360-180)*1e4) / 1e4,
+		},
+		"padding": "",
+	}
+
+	// Fill the padding field with the bytes still missing from the serialized size
+	encoded, _ := json.Marshal(metadata)
+	if missing := size - len(encoded); missing > 0 {
+		padding := make([]byte, missing)
+		for i := range padding {
+			padding[i] = paddingAlphabet[rand.Intn(len(paddingAlphabet))]
+		}
+		metadata["padding"] = string(padding)
+	}
+	return metadata
+}
+
+// paddingAlphabet holds characters that JSON encodes as a single byte
+const paddingAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// pause waits for the think time between sequential operations. It is called outside
+// MeasureOperation, so it lowers wall-clock throughput without affecting per-op latency.
+func pause(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopEarly records a partial result when ctx ended before all operations ran.
+// ItemsProcessed is lowered to the completed count and the reason is noted in Data.
+func stopEarly(ctx context.Context, result *OperationResult, completed, total int) {
+	if ctx.Err() == nil || completed >= total {
+		return
 	}
+	result.ItemsProcessed = completed
+	result.Data["stoppedEarly"] = fmt.Sprintf("%v after %d of %d items", ctx.Err(), completed, total)
 }
 
 // Read Operation
@@ -105,22 +243,50 @@ func (op *ReadOperation) Execute(ctx context.Context, db databases.Database, col
 	concurrency := getParam(op.params, "concurrency", 10)
 	isColdStart := getParam(op.params, "isColdStart", false)
 	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+	thinkTime := time.Duration(getParam(op.params, "thinkTimeMs", 0)) * time.Millisecond
+	keyDistribution := getParam(op.params, "keyDistribution", "uniform")
+	zipfianS := getParam(op.params, "zipfianS", 0.99)
+	keySpace := getParam(op.params, "keySpace", count)
 	specificIDs, hasSpecificIDs := op.params["transactionIDs"].([]string)
 
-	// Load IDs to read
-	var transactionIDs []string
+	// Load IDs to read, with the account each one was written under
+	var transactionIDs, accountIDs []string
 	if hasSpecificIDs {
 		transactionIDs = specificIDs
 		count = len(transactionIDs)
+		accountIDs = make([]string, count)
+		for i := range accountIDs {
+			accountIDs[i] = accountID
+		}
 	} else if useRandomIDs {
 		// For random IDs, we need to create transactions first
 		return result, fmt.Errorf("reading random IDs requires pre-generating transactions first")
-	} else {
+	} else if keyDistribution == "zipfian" {
+		// Skewed access over the deterministic key space to surface hot-key effects
+		if keySpace < 1 {
+			return result, fmt.Errorf("keySpace must be positive, got %d", keySpace)
+		}
+		generator, err := workload.NewZipfGenerator(uint64(keySpace), zipfianS)
+		if err != nil {
+			return result, fmt.Errorf("failed to create zipfian generator: %w", err)
+		}
+		transactionIDs = make([]string, count)
+		accountIDs = make([]string, count)
+		for i := 0; i < count; i++ {
+			index := int(generator.Next())
+			accountIDs[i] = accountForIndex(op.params, index)
+			transactionIDs[i] = fmt.Sprintf("%s-tx-%d", accountIDs[i], index)
+		}
+	} else if keyDistribution == "uniform" {
 		// Generate deterministic IDs
 		transactionIDs = make([]string, count)
+		accountIDs = make([]string, count)
 		for i := 0; i < count; i++ {
-			transactionIDs[i] = fmt.Sprintf("%s-tx-%d", accountID, i)
+			accountIDs[i] = accountForIndex(op.params, i)
+			transactionIDs[i] = fmt.Sprintf("%s-tx-%d", accountIDs[i], i)
 		}
+	} else {
+		return result, fmt.Errorf("unsupported key distribution: %s", keyDistribution)
 	}
 
 	// Set options for reads
@@ -132,6 +298,11 @@ func (op *ReadOperation) Execute(ctx context.Context, db databases.Database, col
 	result.ItemsProcessed = count
 	result.Data["transactionIDs"] = transactionIDs
 
+	// Reads that ran, counted so a cancelled run reports a partial result
+	var completed atomic.Int64
+	// Reads of transactions that don't exist, kept apart from genuine failures
+	var notFound atomic.Int64
+
 	// Execute the reads
 	if op.isParallel {
 		// Parallel reads with worker pool
@@ -140,6 +311,10 @@ func (op *ReadOperation) Execute(ctx context.Context, db databases.Database, col
 		semaphore := make(chan struct{}, concurrency)
 
 		for i, id := range transactionIDs {
+			// Stop launching reads once the deadline has passed
+			if ctx.Err() != nil {
+				break
+			}
 			wg.Add(1)
 			semaphore <- struct{}{}
 
@@ -147,22 +322,32 @@ func (op *ReadOperation) Execute(ctx context.Context, db databases.Database, col
 				defer wg.Done()
 				defer func() { <-semaphore }()
 
+				if ctx.Err() != nil {
+					return
+				}
+
 				var readErr error
 
-				err := collector.MeasureOperation(
+				err := collector.MeasureOperationWithResult(
 					metrics.ReadOperation,
 					1, // itemCount
 					int64(dataSizeBytes),
 					isColdStart,
-					func() error {
-						_, readErr = db.ReadTransaction(ctx, accountID, txID, readOptions)
-						return readErr
+					func() (map[string]interface{}, error) {
+						reqCtx, cancel := withOperationTimeout(ctx, op.params)
+						defer cancel()
+						reqCtx, trace := databases.WithRequestTrace(reqCtx)
+						_, readErr = db.ReadTransaction(reqCtx, accountIDs[index], txID, readOptions)
+						return trace.Metrics(), readErr
 					},
 				)
 
-				if err != nil {
+				if errors.Is(err, databases.ErrTransactionNotFound) {
+					notFound.Add(1)
+				} else if err != nil {
 					errorChan <- fmt.Errorf("failed to read transaction %s: %w", txID, err)
 				}
+				completed.Add(1)
 			}(i, id)
 		}
 
@@ -176,28 +361,46 @@ func (op *ReadOperation) Execute(ctx context.Context, db databases.Database, col
 		}
 	} else {
 		// Sequential reads
-		for _, id := range transactionIDs {
+		for i, id := range transactionIDs {
+			if i > 0 {
+				if err := pause(ctx, thinkTime); err != nil {
+					break
+				}
+			}
+			if ctx.Err() != nil {
+				break
+			}
+
 			var readErr error
 
-			err := collector.MeasureOperation(
+			err := collector.MeasureOperationWithResult(
 				metrics.ReadOperation,
 				1, // itemCount
 				int64(dataSizeBytes),
 				isColdStart,
-				func() error {
-					_, readErr = db.ReadTransaction(ctx, accountID, id, readOptions)
-					return readErr
+				func() (map[string]interface{}, error) {
+					reqCtx, cancel := withOperationTimeout(ctx, op.params)
+					defer cancel()
+					reqCtx, trace := databases.WithRequestTrace(reqCtx)
+					_, readErr = db.ReadTransaction(reqCtx, accountIDs[i], id, readOptions)
+					return trace.Metrics(), readErr
 				},
 			)
 
-			if err != nil {
+			if errors.Is(err, databases.ErrTransactionNotFound) {
+				notFound.Add(1)
+			} else if err != nil {
 				result.Errors = append(result.Errors, fmt.Errorf("failed to read transaction %s: %w", id, err))
 			}
+			completed.Add(1)
 		}
 	}
 
+	result.Data["notFound"] = notFound.Load()
+
 	// Calculate total duration
 	result.TotalDuration = time.Since(startTime)
+	stopEarly(ctx, &result, int(completed.Load()), count)
 
 	// Return error if all operations failed
 	if len(result.Errors) == count {
@@ -236,14 +439,31 @@ func (op *WriteOperation) Execute(ctx context.Context, db databases.Database, co
 	concurrency := getParam(op.params, "concurrency", 10)
 	isColdStart := getParam(op.params, "isColdStart", false)
 	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+	thinkTime := time.Duration(getParam(op.params, "thinkTimeMs", 0)) * time.Millisecond
+
+	// Report the serialized item size as the byte count unless the requested size is asked for
+	reportSerialized := getParam(op.params, "itemSizeMode", "serialized") != "requested"
 
 	// Generate transactions
 	transactions := make([]*databases.Transaction, count)
 	transactionIDs := make([]string, count)
+	itemBytes := make([]int, count)
 
+	accountDistribution := make(map[string]int)
+
+	totalSerialized := 0
 	for i := 0; i < count; i++ {
 		transactions[i] = generateTransaction(op.params, i)
 		transactionIDs[i] = transactions[i].UUID
+		accountDistribution[transactions[i].AccountID]++
+
+		itemBytes[i] = dataSizeBytes
+		if serialized := serializedSize(transactions[i]); serialized > 0 {
+			totalSerialized += serialized
+			if reportSerialized {
+				itemBytes[i] = serialized
+			}
+		}
 	}
 
 	// Set options for writes
@@ -255,98 +475,227 @@ func (op *WriteOperation) Execute(ctx context.Context, db databases.Database, co
 	// Update result with actual count
 	result.ItemsProcessed = count
 	result.Data["transactionIDs"] = transactionIDs
+	result.Data["accountDistribution"] = accountDistribution
+	result.Data["requestedItemBytes"] = dataSizeBytes
+	if count > 0 {
+		result.Data["avgSerializedItemBytes"] = totalSerialized / count
+	}
+
+	// Items whose writes ran, counted so a cancelled run reports a partial result
+	var completed atomic.Int64
 
 	// Execute the writes
 	if op.isParallel {
 		// Batch writes
 		numBatches := (count + batchSize - 1) / batchSize
-		var wg sync.WaitGroup
-		errorChan := make(chan error, numBatches)
-		semaphore := make(chan struct{}, concurrency)
 
-		for i := 0; i < numBatches; i++ {
-			wg.Add(1)
-			semaphore <- struct{}{}
-
-			go func(batchIndex int) {
-				defer wg.Done()
-				defer func() { <-semaphore }()
+		writeBatch := func(batchIndex int) error {
+			startIdx := batchIndex * batchSize
+			endIdx := (batchIndex + 1) * batchSize
+			if endIdx > count {
+				endIdx = count
+			}
 
-				startIdx := batchIndex * batchSize
-				endIdx := (batchIndex + 1) * batchSize
-				if endIdx > count {
-					endIdx = count
-				}
+			batch := transactions[startIdx:endIdx]
+			batchSize := len(batch)
+			batchBytes := 0
+			for _, n := range itemBytes[startIdx:endIdx] {
+				batchBytes += n
+			}
 
-				batch := transactions[startIdx:endIdx]
-				batchSize := len(batch)
+			var writeErr error
+			err := collector.MeasureOperationWithResult(
+				metrics.BatchOperation,
+				int64(batchSize),
+				int64(batchBytes),
+				isColdStart,
+				func() (map[string]interface{}, error) {
+					reqCtx, cancel := withOperationTimeout(ctx, op.params)
+					defer cancel()
+					reqCtx, trace := databases.WithRequestTrace(reqCtx)
+					writeErr = db.BatchWriteTransactions(reqCtx, batch, batchOptions)
+					return trace.Metrics(), writeErr
+				},
+			)
+			completed.Add(int64(batchSize))
 
-				var writeErr error
-				err := collector.MeasureOperation(
-					metrics.BatchOperation,
-					int64(batchSize),
-					int64(batchSize*dataSizeBytes),
-					isColdStart,
-					func() error {
-						writeErr = db.BatchWriteTransactions(ctx, batch, batchOptions)
-						return writeErr
-					},
-				)
+			if err != nil {
+				return fmt.Errorf("failed to write batch %d: %w", batchIndex, err)
+			}
+			return nil
+		}
 
-				if err != nil {
-					errorChan <- fmt.Errorf("failed to write batch %d: %w", batchIndex, err)
+		if getParam(op.params, "adaptiveConcurrency", false) {
+			series, errs := writeAdaptive(ctx, numBatches, concurrency, writeBatch)
+			result.Errors = append(result.Errors, errs...)
+			result.Data["concurrencySeries"] = series
+		} else {
+			var wg sync.WaitGroup
+			errorChan := make(chan error, numBatches)
+			semaphore := make(chan struct{}, concurrency)
+
+			for i := 0; i < numBatches; i++ {
+				// Stop launching batches once the deadline has passed
+				if ctx.Err() != nil {
+					break
 				}
-			}(i)
-		}
+				wg.Add(1)
+				semaphore <- struct{}{}
+
+				go func(batchIndex int) {
+					defer wg.Done()
+					defer func() { <-semaphore }()
+
+					if ctx.Err() != nil {
+						return
+					}
+					if err := writeBatch(batchIndex); err != nil {
+						errorChan <- err
+					}
+				}(i)
+			}
 
-		// Wait for all batches to complete
-		wg.Wait()
-		close(errorChan)
+			// Wait for all batches to complete
+			wg.Wait()
+			close(errorChan)
 
-		// Collect errors
-		for err := range errorChan {
-			result.Errors = append(result.Errors, err)
+			// Collect errors
+			for err := range errorChan {
+				result.Errors = append(result.Errors, err)
+			}
 		}
 	} else {
 		// Individual writes
-		for _, tx := range transactions {
+		for i, tx := range transactions {
+			if i > 0 {
+				if err := pause(ctx, thinkTime); err != nil {
+					break
+				}
+			}
+			if ctx.Err() != nil {
+				break
+			}
+
 			var writeErr error
-			err := collector.MeasureOperation(
+			err := collector.MeasureOperationWithResult(
 				metrics.WriteOperation,
 				1, // itemCount
-				int64(dataSizeBytes),
+				int64(itemBytes[i]),
 				isColdStart,
-				func() error {
-					writeErr = db.WriteTransaction(ctx, tx, writeOptions)
-					return writeErr
+				func() (map[string]interface{}, error) {
+					reqCtx, cancel := withOperationTimeout(ctx, op.params)
+					defer cancel()
+					reqCtx, trace := databases.WithRequestTrace(reqCtx)
+					writeErr = db.WriteTransaction(reqCtx, tx, writeOptions)
+					return trace.Metrics(), writeErr
 				},
 			)
 
 			if err != nil {
 				result.Errors = append(result.Errors, fmt.Errorf("failed to write transaction %s: %w", tx.UUID, err))
 			}
+			completed.Add(1)
 		}
 	}
 
 	// Calculate total duration
 	result.TotalDuration = time.Since(startTime)
+	stopEarly(ctx, &result, int(completed.Load()), count)
 
 	// Return error if all operations failed
 	if len(result.Errors) == count {
 		return result, fmt.Errorf("all write operations failed")
 	}
 
+	// Read back a sample of the written items after the measured writes
+	if verifyFraction := getParam(op.params, "verifyFraction", 0.0); verifyFraction > 0 && ctx.Err() == nil {
+		verified, failures := verifyWrites(ctx, db, transactions, verifyFraction)
+		result.Data["verifiedCount"] = verified
+		result.Data["verificationFailures"] = failures
+	}
+
 	return result, nil
 }
 
-// Query Operation
-type QueryOperation struct {
+// writeAdaptive writes numBatches batches with an AIMD controller. Batches run in rounds
+// of the current concurrency, starting at 1: a round without throttling or errors that
+// doesn't lower throughput adds one worker, up to maxConcurrency, and a throttled
+// round halves the workers. It returns the concurrency of each round and the
+// batch errors.
+func writeAdaptive(ctx context.Context, numBatches, maxConcurrency int, writeBatch func(batchIndex int) error) ([]int, []error) {
+	var series []int
+	var errs []error
+
+	current := 1
+	prevThroughput := 0.0
+	for next := 0; next < numBatches && ctx.Err() == nil; {
+		n := min(current, numBatches-next)
+		series = append(series, current)
+
+		roundStart := time.Now()
+		roundErrs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				roundErrs[i] = writeBatch(next + i)
+			}(i)
+		}
+		wg.Wait()
+		throughput := float64(n) / time.Since(roundStart).Seconds()
+		next += n
+
+		throttled, failed := false, false
+		for _, err := range roundErrs {
+			if err != nil {
+				errs = append(errs, err)
+				failed = true
+				throttled = throttled || metrics.IsThrottled(err)
+			}
+		}
+
+		switch {
+		case throttled:
+			current = max(1, current/2)
+		case !failed && throughput >= prevThroughput:
+			current = min(maxConcurrency, current+1)
+		}
+		prevThroughput = throughput
+	}
+
+	return series, errs
+}
+
+// verifyWrites reads back a random sample of fraction of the written transactions and
+// returns how many were checked and how many were missing or unreadable. The reads are
+// not measured, so they don't affect the reported write latency.
+func verifyWrites(ctx context.Context, db databases.Database, transactions []*databases.Transaction, fraction float64) (int, int) {
+	sampleSize := int(math.Ceil(float64(len(transactions)) * math.Min(fraction, 1)))
+	readOptions := &databases.ReadOptions{ConsistentRead: true}
+
+	verified, failures := 0, 0
+	for _, i := range rand.Perm(len(transactions))[:sampleSize] {
+		if ctx.Err() != nil {
+			break
+		}
+		tx := transactions[i]
+		if _, err := db.ReadTransaction(ctx, tx.AccountID, tx.UUID, readOptions); err != nil {
+			failures++
+		}
+		verified++
+	}
+	return verified, failures
+}
+
+// Update Operation
+type UpdateOperation struct {
 	baseOperation
 }
 
-// NewQueryOperation creates a new query operation
-func NewQueryOperation(params map[string]interface{}) *QueryOperation {
-	return &QueryOperation{
+// NewUpdateOperation creates a new update operation
+func NewUpdateOperation(params map[string]interface{}) *UpdateOperation {
+	return &UpdateOperation{
 		baseOperation: baseOperation{
 			params:     params,
 			isParallel: false,
@@ -354,8 +703,8 @@ func NewQueryOperation(params map[string]interface{}) *QueryOperation {
 	}
 }
 
-// Execute runs the query operation
-func (op *QueryOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+// Execute runs the update operation against previously written transactions
+func (op *UpdateOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
 	startTime := time.Now()
 	result := OperationResult{
 		Errors: []error{},
@@ -363,93 +712,1423 @@ func (op *QueryOperation) Execute(ctx context.Context, db databases.Database, co
 	}
 
 	// Get parameters
-	accountID := getParam(op.params, "accountId", "test-account")
+	count := getParam(op.params, "itemCount", 100)
 	isColdStart := getParam(op.params, "isColdStart", false)
+	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+	condition := getParam(op.params, "condition", "")
 
-	// Get start and end times with proper type conversion
-	var startDate, endDate time.Time
-	startTimestamp, hasStartTime := op.params["startTime"]
-	endTimestamp, hasEndTime := op.params["endTime"]
-
-	if hasStartTime {
-		if ts, ok := startTimestamp.(time.Time); ok {
-			startDate = ts
-		} else if str, ok := startTimestamp.(string); ok {
-			// Try to parse RFC3339 time
-			if t, err := time.Parse(time.RFC3339, str); err == nil {
-				startDate = t
-			}
-		}
+	// Generate updated versions of the deterministic transactions written by WriteOperation
+	transactions := make([]*databases.Transaction, count)
+	transactionIDs := make([]string, count)
+	for i := 0; i < count; i++ {
+		transactions[i] = generateTransaction(op.params, i)
+		transactionIDs[i] = transactions[i].UUID
 	}
 
-	if hasEndTime {
-		if ts, ok := endTimestamp.(time.Time); ok {
-			endDate = ts
-		} else if str, ok := endTimestamp.(string); ok {
-			// Try to parse RFC3339 time
-			if t, err := time.Parse(time.RFC3339, str); err == nil {
-				endDate = t
-			}
-		}
+	// Set options for updates
+	writeOptions := &databases.WriteOptions{
+		Condition: condition,
 	}
 
-	// Set default dates if not provided or parsing failed
-	if startDate.IsZero() {
-		startDate = time.Now().Add(-24 * time.Hour)
-	}
-	if endDate.IsZero() {
-		endDate = time.Now()
+	// Update result with actual count
+	result.ItemsProcessed = count
+	result.Data["transactionIDs"] = transactionIDs
+
+	// Execute the updates
+	for _, tx := range transactions {
+		var updateErr error
+		err := collector.MeasureOperationWithResult(
+			metrics.UpdateOperation,
+			1, // itemCount
+			int64(dataSizeBytes),
+			isColdStart,
+			func() (map[string]interface{}, error) {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
+				reqCtx, trace := databases.WithRequestTrace(reqCtx)
+				updateErr = db.UpdateTransaction(reqCtx, tx, writeOptions)
+				return trace.Metrics(), updateErr
+			},
+		)
+
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to update transaction %s: %w", tx.UUID, err))
+		}
 	}
 
-	limit := getParam(op.params, "limit", int64(100))
-	consistentRead := getParam(op.params, "consistentRead", true)
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
 
-	// Set query options
-	queryOptions := &databases.QueryOptions{
-		Limit:          limit,
-		ConsistentRead: consistentRead,
+	// Return error if all operations failed
+	if len(result.Errors) == count {
+		return result, fmt.Errorf("all update operations failed")
 	}
 
-	// Execute the query
-	var transactions []*databases.Transaction
-	var queryErr error
+	return result, nil
+}
 
-	// Estimate the data size for metrics - will be updated with actual results
-	estimatedItemCount := limit
-	estimatedByteCount := estimatedItemCount * int64(getParam(op.params, "dataSize", 1024))
+// Delete Operation
+type DeleteOperation struct {
+	baseOperation
+}
 
-	err := collector.MeasureOperation(
-		metrics.QueryOperation,
-		estimatedItemCount,
-		estimatedByteCount,
-		isColdStart,
-		func() error {
-			transactions, queryErr = db.QueryTransactionsByTimeRange(
-				ctx,
-				accountID,
-				startDate,
-				endDate,
-				queryOptions,
-			)
-			return queryErr
+// NewDeleteOperation creates a new delete operation (sequential or parallel)
+func NewDeleteOperation(params map[string]interface{}, isParallel bool) *DeleteOperation {
+	return &DeleteOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: isParallel,
 		},
-	)
+	}
+}
 
-	if err != nil {
-		result.Errors = append(result.Errors, fmt.Errorf("failed to execute query: %w", err))
-		return result, err
+// Execute runs the delete operation
+func (op *DeleteOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
 	}
 
-	// Update result with retrieved count
-	result.ItemsProcessed = len(transactions)
-	transactionIDs := make([]string, len(transactions))
-	for i, tx := range transactions {
-		transactionIDs[i] = tx.UUID
+	// Get parameters
+	count := getParam(op.params, "itemCount", 100)
+	accountID := getParam(op.params, "accountId", "test-account")
+	useRandomIDs := getParam(op.params, "useRandomIDs", false)
+	concurrency := getParam(op.params, "concurrency", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+	condition := getParam(op.params, "condition", "")
+	returnOldItem := getParam(op.params, "returnOldItem", false)
+	specificIDs, hasSpecificIDs := op.params["transactionIDs"].([]string)
+
+	// Load IDs to delete, with the account each one was written under
+	var transactionIDs, accountIDs []string
+	if hasSpecificIDs {
+		transactionIDs = specificIDs
+		count = len(transactionIDs)
+		accountIDs = make([]string, count)
+		for i := range accountIDs {
+			accountIDs[i] = accountID
+		}
+	} else if useRandomIDs {
+		// Random IDs are unknown until the transactions have been written
+		return result, fmt.Errorf("deleting random IDs requires supplying transactionIDs")
+	} else {
+		// Generate deterministic IDs
+		transactionIDs = make([]string, count)
+		accountIDs = make([]string, count)
+		for i := 0; i < count; i++ {
+			accountIDs[i] = accountForIndex(op.params, i)
+			transactionIDs[i] = fmt.Sprintf("%s-tx-%d", accountIDs[i], i)
+		}
 	}
+
+	// Update result with actual count
+	result.ItemsProcessed = count
 	result.Data["transactionIDs"] = transactionIDs
 
-	// Calculate total duration
+	var conditionFailed, oldItemsReturned atomic.Int64
+	deleteOne := func(index int) error {
+		// Options are per delete because the adapter sets the returned old item on them
+		deleteOptions := &databases.DeleteOptions{
+			Condition:     condition,
+			ReturnOldItem: returnOldItem,
+		}
+		err := collector.MeasureOperationWithResult(
+			metrics.DeleteOperation,
+			1, // itemCount
+			int64(dataSizeBytes),
+			isColdStart,
+			func() (map[string]interface{}, error) {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
+				reqCtx, trace := databases.WithRequestTrace(reqCtx)
+				err := db.DeleteTransaction(reqCtx, accountIDs[index], transactionIDs[index], deleteOptions)
+				return trace.Metrics(), err
+			},
+		)
+		if databases.IsConditionFailed(err) {
+			conditionFailed.Add(1)
+		}
+		if deleteOptions.OldItem != nil {
+			oldItemsReturned.Add(1)
+		}
+		return err
+	}
+
+	// Delete the first ID on its own so databases without delete support are
+	// recorded as skipped instead of failing every operation
+	if count > 0 {
+		if err := deleteOne(0); databases.IsUnsupportedOperation(err) {
+			result.ItemsProcessed = 0
+			result.Data["skipped"] = true
+			result.Data["skipReason"] = err.Error()
+			result.TotalDuration = time.Since(startTime)
+			return result, nil
+		} else if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to delete transaction %s: %w", transactionIDs[0], err))
+		}
+	}
+	// Execute the remaining deletes
+	if op.isParallel {
+		// Parallel deletes with worker pool
+		var wg sync.WaitGroup
+		errorChan := make(chan error, count)
+		semaphore := make(chan struct{}, concurrency)
+
+		for i := 1; i < count; i++ {
+			wg.Add(1)
+			semaphore <- struct{}{}
+
+			go func(index int) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+
+				if err := deleteOne(index); err != nil {
+					errorChan <- fmt.Errorf("failed to delete transaction %s: %w", transactionIDs[index], err)
+				}
+			}(i)
+		}
+
+		// Wait for all deletes to complete
+		wg.Wait()
+		close(errorChan)
+
+		// Collect errors
+		for err := range errorChan {
+			result.Errors = append(result.Errors, err)
+		}
+	} else {
+		// Sequential deletes
+		for i := 1; i < count; i++ {
+			if err := deleteOne(i); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("failed to delete transaction %s: %w", transactionIDs[i], err))
+			}
+		}
+	}
+
+	if condition != "" {
+		result.Data["conditionFailed"] = int(conditionFailed.Load())
+	}
+	if returnOldItem {
+		result.Data["oldItemsReturned"] = int(oldItemsReturned.Load())
+	}
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if all operations failed
+	if count > 0 && len(result.Errors) == count {
+		return result, fmt.Errorf("all delete operations failed")
+	}
+
+	return result, nil
+}
+
+// Mixed Operation
+type MixedOperation struct {
+	baseOperation
+}
+
+// NewMixedOperation creates a new mixed read/write operation
+func NewMixedOperation(params map[string]interface{}) *MixedOperation {
+	return &MixedOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: true,
+		},
+	}
+}
+
+// Execute interleaves reads and writes according to readRatio using a worker pool
+func (op *MixedOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	// Get parameters
+	count := getParam(op.params, "itemCount", 100)
+	readRatio := getParam(op.params, "readRatio", 0.5)
+	seedCount := getParam(op.params, "seedCount", 100)
+	concurrency := getParam(op.params, "concurrency", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+	consistentRead := getParam(op.params, "consistentRead", true)
+
+	if readRatio < 0 || readRatio > 1 {
+		return result, fmt.Errorf("readRatio must be between 0.0 and 1.0, got %v", readRatio)
+	}
+	if seedCount < 1 {
+		seedCount = 1
+	}
+
+	// Seed the key space so reads hit existing transactions. Seeding is not measured.
+	seed := make([]*databases.Transaction, seedCount)
+	for i := 0; i < seedCount; i++ {
+		seed[i] = generateTransaction(op.params, i)
+	}
+	if err := db.BatchWriteTransactions(ctx, seed, &databases.BatchOptions{MaxBatchSize: 25}); err != nil {
+		return result, fmt.Errorf("failed to seed transactions: %w", err)
+	}
+
+	// Build the operation schedule with the exact read count, then shuffle to interleave
+	readCount := int(math.Round(readRatio * float64(count)))
+	isRead := make([]bool, count)
+	for i := 0; i < readCount; i++ {
+		isRead[i] = true
+	}
+	rand.Shuffle(count, func(i, j int) { isRead[i], isRead[j] = isRead[j], isRead[i] })
+
+	readOptions := &databases.ReadOptions{
+		ConsistentRead: consistentRead,
+	}
+	writeOptions := &databases.WriteOptions{}
+
+	// Execute the workload with a worker pool
+	var wg sync.WaitGroup
+	errorChan := make(chan error, count)
+	semaphore := make(chan struct{}, concurrency)
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(index int, read bool) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if read {
+				target := seed[rand.Intn(len(seed))]
+				err := collector.MeasureOperation(
+					metrics.ReadOperation,
+					1, // itemCount
+					int64(dataSizeBytes),
+					isColdStart,
+					func() error {
+						reqCtx, cancel := withOperationTimeout(ctx, op.params)
+						defer cancel()
+						_, readErr := db.ReadTransaction(reqCtx, target.AccountID, target.UUID, readOptions)
+						return readErr
+					},
+				)
+				if err != nil {
+					errorChan <- fmt.Errorf("failed to read transaction %s: %w", target.UUID, err)
+				}
+				return
+			}
+
+			// Writes use indices past the seed set so they create new keys
+			tx := generateTransaction(op.params, seedCount+index)
+			err := collector.MeasureOperation(
+				metrics.WriteOperation,
+				1, // itemCount
+				int64(dataSizeBytes),
+				isColdStart,
+				func() error {
+					reqCtx, cancel := withOperationTimeout(ctx, op.params)
+					defer cancel()
+					return db.WriteTransaction(reqCtx, tx, writeOptions)
+				},
+			)
+			if err != nil {
+				errorChan <- fmt.Errorf("failed to write transaction %s: %w", tx.UUID, err)
+			}
+		}(i, isRead[i])
+	}
+
+	// Wait for all operations to complete
+	wg.Wait()
+	close(errorChan)
+
+	// Collect errors
+	for err := range errorChan {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.ItemsProcessed = count
+	result.Data["readCount"] = readCount
+	result.Data["writeCount"] = count - readCount
+	result.Data["seedCount"] = seedCount
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if all operations failed
+	if count > 0 && len(result.Errors) == count {
+		return result, fmt.Errorf("all mixed operations failed")
+	}
+
+	return result, nil
+}
+
+// Scan Operation
+type ScanOperation struct {
+	baseOperation
+}
+
+// NewScanOperation creates a new full-table scan operation
+func NewScanOperation(params map[string]interface{}) *ScanOperation {
+	return &ScanOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: getParam(params, "totalSegments", 1) > 1,
+		},
+	}
+}
+
+// Execute runs the scan operation, measuring each page as a query
+func (op *ScanOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	// Get parameters
+	totalSegments := getParam(op.params, "totalSegments", 1)
+	segment := getParam(op.params, "segment", -1) // -1 scans every segment
+	limit := getParam(op.params, "limit", int64(100))
+	consistentRead := getParam(op.params, "consistentRead", false)
+	isColdStart := getParam(op.params, "isColdStart", false)
+	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+
+	if totalSegments < 1 {
+		totalSegments = 1
+	}
+
+	// Determine which segments this invocation scans
+	segments := []int{segment}
+	if segment < 0 {
+		segments = make([]int, totalSegments)
+		for i := range segments {
+			segments[i] = i
+		}
+	}
+
+	var (
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+		itemsScanned int
+		pagesScanned int
+	)
+	errorChan := make(chan error, len(segments))
+
+	for _, seg := range segments {
+		wg.Add(1)
+
+		go func(segmentIndex int) {
+			defer wg.Done()
+
+			scanOptions := &databases.ScanOptions{
+				Segment:        int32(segmentIndex),
+				TotalSegments:  int32(totalSegments),
+				Limit:          limit,
+				ConsistentRead: consistentRead,
+			}
+
+			for {
+				// Record the items the page actually returned; the last page is usually short
+				var page *databases.PagedTransactions
+				err := collector.MeasureCountedOperation(
+					metrics.QueryOperation,
+					int64(dataSizeBytes),
+					isColdStart,
+					func() (int64, error) {
+						reqCtx, cancel := withOperationTimeout(ctx, op.params)
+						defer cancel()
+						var scanErr error
+						page, scanErr = db.ScanTransactionsPaged(reqCtx, scanOptions)
+						if scanErr != nil {
+							return 0, scanErr
+						}
+						return int64(len(page.Transactions)), nil
+					},
+				)
+				if err != nil {
+					errorChan <- fmt.Errorf("failed to scan segment %d: %w", segmentIndex, err)
+					return
+				}
+
+				mu.Lock()
+				itemsScanned += len(page.Transactions)
+				pagesScanned++
+				mu.Unlock()
+
+				if page.NextToken == "" {
+					return
+				}
+				scanOptions.StartToken = page.NextToken
+			}
+		}(seg)
+	}
+
+	// Wait for all segments to complete
+	wg.Wait()
+	close(errorChan)
+
+	// Collect errors
+	for err := range errorChan {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.ItemsProcessed = itemsScanned
+	result.Data["itemsScanned"] = itemsScanned
+	result.Data["pagesScanned"] = pagesScanned
+	result.Data["totalSegments"] = totalSegments
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if every segment failed
+	if len(result.Errors) == len(segments) {
+		return result, fmt.Errorf("all scan segments failed")
+	}
+
+	return result, nil
+}
+
+// Seed Operation
+type SeedOperation struct {
+	baseOperation
+}
+
+// NewSeedOperation creates a new operation that pre-populates deterministic test data
+func NewSeedOperation(params map[string]interface{}) *SeedOperation {
+	return &SeedOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: true,
+		},
+	}
+}
+
+// Execute writes itemCount transactions with IDs {accountID}-tx-{i} using batch writes,
+// matching the IDs read by ReadOperation and updated by UpdateOperation
+func (op *SeedOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	// Get parameters
+	count := getParam(op.params, "itemCount", 100)
+	batchSize := getParam(op.params, "batchSize", 25)
+	concurrency := getParam(op.params, "concurrency", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+	accountID := getParam(op.params, "accountId", "test-account")
+
+	if batchSize < 1 {
+		batchSize = 25
+	}
+
+	// Generate transactions with deterministic IDs regardless of useRandomIDs
+	transactions := make([]*databases.Transaction, count)
+	for i := 0; i < count; i++ {
+		transactions[i] = generateTransaction(op.params, i)
+		transactions[i].UUID = fmt.Sprintf("%s-tx-%d", transactions[i].AccountID, i)
+	}
+
+	batchOptions := &databases.BatchOptions{
+		MaxBatchSize: batchSize,
+	}
+
+	// Write batches with a worker pool
+	numBatches := (count + batchSize - 1) / batchSize
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		written int
+	)
+	errorChan := make(chan error, numBatches)
+	semaphore := make(chan struct{}, concurrency)
+
+	for i := 0; i < numBatches; i++ {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(batchIndex int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			startIdx := batchIndex * batchSize
+			endIdx := startIdx + batchSize
+			if endIdx > count {
+				endIdx = count
+			}
+			batch := transactions[startIdx:endIdx]
+
+			err := collector.MeasureOperation(
+				metrics.BatchOperation,
+				int64(len(batch)),
+				int64(len(batch)*dataSizeBytes),
+				isColdStart,
+				func() error {
+					reqCtx, cancel := withOperationTimeout(ctx, op.params)
+					defer cancel()
+					return db.BatchWriteTransactions(reqCtx, batch, batchOptions)
+				},
+			)
+
+			if err != nil {
+				errorChan <- fmt.Errorf("failed to seed batch %d: %w", batchIndex, err)
+				return
+			}
+
+			mu.Lock()
+			written += len(batch)
+			mu.Unlock()
+		}(i)
+	}
+
+	// Wait for all batches to complete
+	wg.Wait()
+	close(errorChan)
+
+	// Collect errors
+	for err := range errorChan {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.ItemsProcessed = written
+	result.Data["itemsWritten"] = written
+	result.Data["accountId"] = accountID
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if all batches failed
+	if numBatches > 0 && len(result.Errors) == numBatches {
+		return result, fmt.Errorf("all seed batches failed")
+	}
+
+	return result, nil
+}
+
+// TransactRead Operation
+type TransactReadOperation struct {
+	baseOperation
+}
+
+// NewTransactReadOperation creates a new transactional multi-item read operation
+func NewTransactReadOperation(params map[string]interface{}) *TransactReadOperation {
+	return &TransactReadOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: true,
+		},
+	}
+}
+
+// Execute reads itemCount deterministic IDs in groups of batchSize, each group in a
+// single transactional read. A group that returns fewer items than requested is an error.
+func (op *TransactReadOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	// Get parameters
+	count := getParam(op.params, "itemCount", 100)
+	batchSize := getParam(op.params, "batchSize", 25)
+	concurrency := getParam(op.params, "concurrency", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+
+	if batchSize <= 0 || batchSize > 25 {
+		batchSize = 25 // DynamoDB TransactGetItems limit
+	}
+
+	// Group deterministic keys into transactions
+	var groups [][]struct{ AccountID, UUID string }
+	for i := 0; i < count; i += batchSize {
+		end := i + batchSize
+		if end > count {
+			end = count
+		}
+		group := make([]struct{ AccountID, UUID string }, 0, end-i)
+		for j := i; j < end; j++ {
+			accountID := accountForIndex(op.params, j)
+			group = append(group, struct{ AccountID, UUID string }{accountID, fmt.Sprintf("%s-tx-%d", accountID, j)})
+		}
+		groups = append(groups, group)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		itemsRead int
+	)
+	errorChan := make(chan error, len(groups))
+	semaphore := make(chan struct{}, concurrency)
+
+	for _, group := range groups {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(keys []struct{ AccountID, UUID string }) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			var transactions []*databases.Transaction
+			err := collector.MeasureOperation(
+				metrics.TransactionOperation,
+				int64(len(keys)),
+				int64(dataSizeBytes*len(keys)),
+				isColdStart,
+				func() error {
+					reqCtx, cancel := withOperationTimeout(ctx, op.params)
+					defer cancel()
+					var readErr error
+					transactions, readErr = db.ExecuteTransactRead(reqCtx, keys)
+					return readErr
+				},
+			)
+			if err != nil {
+				errorChan <- fmt.Errorf("failed transact read starting at %s: %w", keys[0].UUID, err)
+				return
+			}
+
+			mu.Lock()
+			itemsRead += len(transactions)
+			mu.Unlock()
+
+			if len(transactions) != len(keys) {
+				errorChan <- fmt.Errorf("transact read starting at %s returned %d of %d items", keys[0].UUID, len(transactions), len(keys))
+			}
+		}(group)
+	}
+
+	// Wait for all transactions to complete
+	wg.Wait()
+	close(errorChan)
+
+	// Collect errors
+	for err := range errorChan {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.ItemsProcessed = itemsRead
+	result.Data["transactions"] = len(groups)
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if all operations failed
+	if len(groups) > 0 && len(result.Errors) == len(groups) {
+		return result, fmt.Errorf("all transact read operations failed")
+	}
+
+	return result, nil
+}
+
+// Query Operation
+type QueryOperation struct {
+	baseOperation
+}
+
+// NewQueryOperation creates a new query operation
+func NewQueryOperation(params map[string]interface{}) *QueryOperation {
+	return &QueryOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: false,
+		},
+	}
+}
+
+// Execute runs the query operation
+func (op *QueryOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	// Get parameters
+	accountID := getParam(op.params, "accountId", "test-account")
+	isColdStart := getParam(op.params, "isColdStart", false)
+
+	startDate, endDate := timeRangeParams(op.params)
+
+	limit := getParam(op.params, "limit", int64(100))
+	consistentRead := getParam(op.params, "consistentRead", true)
+
+	// Set query options
+	queryOptions := &databases.QueryOptions{
+		Limit:          limit,
+		ConsistentRead: consistentRead,
+	}
+
+	// Execute the query
+	var transactions []*databases.Transaction
+	var queryErr error
+
+	// Estimate the data size for metrics - will be updated with actual results
+	estimatedItemCount := limit
+	estimatedByteCount := estimatedItemCount * int64(getParam(op.params, "dataSize", 1024))
+
+	err := collector.MeasureOperation(
+		metrics.QueryOperation,
+		estimatedItemCount,
+		estimatedByteCount,
+		isColdStart,
+		func() error {
+			reqCtx, cancel := withOperationTimeout(ctx, op.params)
+			defer cancel()
+			transactions, queryErr = db.QueryTransactionsByTimeRange(
+				reqCtx,
+				accountID,
+				startDate,
+				endDate,
+				queryOptions,
+			)
+			return queryErr
+		},
+	)
+
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("failed to execute query: %w", err))
+		return result, err
+	}
+
+	// Update result with retrieved count
+	result.ItemsProcessed = len(transactions)
+	transactionIDs := make([]string, len(transactions))
+	for i, tx := range transactions {
+		transactionIDs[i] = tx.UUID
+	}
+	result.Data["transactionIDs"] = transactionIDs
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	return result, nil
+}
+
+// timeRangeParams reads the startTime and endTime parameters as time.Time values or
+// RFC3339 strings, defaulting to the last 24 hours
+func timeRangeParams(params map[string]interface{}) (time.Time, time.Time) {
+	var startDate, endDate time.Time
+	startTimestamp, hasStartTime := params["startTime"]
+	endTimestamp, hasEndTime := params["endTime"]
+
+	if hasStartTime {
+		if ts, ok := startTimestamp.(time.Time); ok {
+			startDate = ts
+		} else if str, ok := startTimestamp.(string); ok {
+			// Try to parse RFC3339 time
+			if t, err := time.Parse(time.RFC3339, str); err == nil {
+				startDate = t
+			}
+		}
+	}
+
+	if hasEndTime {
+		if ts, ok := endTimestamp.(time.Time); ok {
+			endDate = ts
+		} else if str, ok := endTimestamp.(string); ok {
+			// Try to parse RFC3339 time
+			if t, err := time.Parse(time.RFC3339, str); err == nil {
+				endDate = t
+			}
+		}
+	}
+
+	// Set default dates if not provided or parsing failed
+	if startDate.IsZero() {
+		startDate = time.Now().Add(-24 * time.Hour)
+	}
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+
+	return startDate, endDate
+}
+
+// QueryGSIOperation compares time-range queries against the base table and a
+// secondary index
+type QueryGSIOperation struct {
+	baseOperation
+}
+
+// NewQueryGSIOperation creates a new base-table versus GSI query operation
+func NewQueryGSIOperation(params map[string]interface{}) *QueryGSIOperation {
+	return &QueryGSIOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: false,
+		},
+	}
+}
+
+// Execute runs the same time-range query against the base table and the index for each
+// iteration, recording per-path latency and how often the index returned a different
+// item count than the strongly consistent base-table query
+func (op *QueryGSIOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	// Get parameters
+	accountID := getParam(op.params, "accountId", "test-account")
+	isColdStart := getParam(op.params, "isColdStart", false)
+	iterations := getParam(op.params, "iterations", 10)
+	limit := getParam(op.params, "limit", int64(100))
+	indexName := getParam(op.params, "indexName", "")
+	startDate, endDate := timeRangeParams(op.params)
+
+	paths := []struct {
+		name    string
+		options *databases.QueryOptions
+	}{
+		{"baseTable", &databases.QueryOptions{Limit: limit, ConsistentRead: true, IndexName: databases.BaseTableIndex}},
+		{"gsi", &databases.QueryOptions{Limit: limit, IndexName: indexName}},
+	}
+
+	durations := make(map[string]time.Duration, len(paths))
+	items := make(map[string]int, len(paths))
+	mismatches := 0
+	completed := 0
+
+	for i := 0; i < iterations; i++ {
+		// Stop once the context deadline has passed
+		if ctx.Err() != nil {
+			result.Data["stoppedEarly"] = fmt.Sprintf("%v after %d of %d iterations", ctx.Err(), completed, iterations)
+			break
+		}
+
+		counts := make(map[string]int, len(paths))
+		failed := false
+		for _, path := range paths {
+			var transactions []*databases.Transaction
+			queryStart := time.Now()
+			err := collector.MeasureOperationWithCustomMetrics(
+				metrics.QueryOperation,
+				limit,
+				0,
+				isColdStart && i == 0,
+				map[string]interface{}{"index": path.name},
+				func() error {
+					reqCtx, cancel := withOperationTimeout(ctx, op.params)
+					defer cancel()
+					var queryErr error
+					transactions, queryErr = db.QueryTransactionsByTimeRange(reqCtx, accountID, startDate, endDate, path.options)
+					return queryErr
+				},
+			)
+			durations[path.name] += time.Since(queryStart)
+
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("%s query %d failed: %w", path.name, i, err))
+				failed = true
+				continue
+			}
+			counts[path.name] = len(transactions)
+			items[path.name] += len(transactions)
+			result.ItemsProcessed += len(transactions)
+		}
+
+		// A GSI is eventually consistent, so it can lag behind the base table
+		if !failed && counts["baseTable"] != counts["gsi"] {
+			mismatches++
+		}
+		completed++
+	}
+
+	for _, path := range paths {
+		result.Data[path.name+"Items"] = items[path.name]
+		if completed > 0 {
+			result.Data[path.name+"AvgLatencyMs"] = float64(durations[path.name].Microseconds()) / 1000 / float64(completed)
+		}
+	}
+	result.Data["iterations"] = completed
+	result.Data["countMismatches"] = mismatches
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if every query failed
+	if len(result.Errors) > 0 && len(result.Errors) == completed*len(paths) {
+		return result, fmt.Errorf("all queries failed")
+	}
+
+	return result, nil
+}
+
+// AggregateOperation benchmarks server-side aggregation of transaction amounts
+type AggregateOperation struct {
+	baseOperation
+}
+
+// NewAggregateOperation creates a new aggregation query operation
+func NewAggregateOperation(params map[string]interface{}) *AggregateOperation {
+	return &AggregateOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: false,
+		},
+	}
+}
+
+// Execute runs the aggregation query repeatedly against databases that implement
+// databases.Aggregator
+func (op *AggregateOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	aggregator, ok := db.(databases.Aggregator)
+	if !ok {
+		return result, fmt.Errorf("aggregate: %w", databases.ErrNotSupported)
+	}
+
+	// Get parameters
+	accountID := getParam(op.params, "accountId", "test-account")
+	aggFunc := getParam(op.params, "aggFunc", "SUM")
+	iterations := getParam(op.params, "iterations", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+	startDate, endDate := timeRangeParams(op.params)
+
+	var value float64
+	completed := 0
+	for i := 0; i < iterations; i++ {
+		// Stop once the context deadline has passed
+		if ctx.Err() != nil {
+			result.Data["stoppedEarly"] = fmt.Sprintf("%v after %d of %d iterations", ctx.Err(), completed, iterations)
+			break
+		}
+
+		err := collector.MeasureOperation(
+			metrics.QueryOperation,
+			1, // One aggregate value
+			0,
+			isColdStart && i == 0,
+			func() error {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
+				var aggErr error
+				value, aggErr = aggregator.AggregateTransactions(reqCtx, accountID, startDate, endDate, aggFunc)
+				return aggErr
+			},
+		)
+		completed++
+
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("aggregate query %d failed: %w", i, err))
+			continue
+		}
+		result.ItemsProcessed++
+	}
+
+	result.Data["aggFunc"] = aggFunc
+	result.Data["value"] = value
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if every query failed
+	if completed > 0 && len(result.Errors) == completed {
+		return result, fmt.Errorf("all aggregate queries failed: %w", result.Errors[0])
+	}
+
+	return result, nil
+}
+
+// QueryAmountOperation benchmarks queries for an account's transactions within an amount range
+type QueryAmountOperation struct {
+	baseOperation
+}
+
+// NewQueryAmountOperation creates a new amount-range query operation
+func NewQueryAmountOperation(params map[string]interface{}) *QueryAmountOperation {
+	return &QueryAmountOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: false,
+		},
+	}
+}
+
+// Execute runs the amount-range query repeatedly against databases that implement
+// databases.AmountRangeQuerier
+func (op *QueryAmountOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	querier, ok := db.(databases.AmountRangeQuerier)
+	if !ok {
+		return result, fmt.Errorf("query-amount: %w", databases.ErrNotSupported)
+	}
+
+	// Get parameters
+	accountID := getParam(op.params, "accountId", "test-account")
+	minAmount := getParam(op.params, "minAmount", 0.0)
+	maxAmount := getParam(op.params, "maxAmount", 1000.0)
+	limit := getParam(op.params, "limit", int64(100))
+	consistentRead := getParam(op.params, "consistentRead", true)
+	iterations := getParam(op.params, "iterations", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+
+	if minAmount > maxAmount {
+		return result, fmt.Errorf("minAmount %v is greater than maxAmount %v", minAmount, maxAmount)
+	}
+
+	queryOptions := &databases.QueryOptions{
+		Limit:            limit,
+		ConsistentRead:   consistentRead,
+		ScanIndexForward: true,
+	}
+
+	itemsReturned := 0
+	completed := 0
+	for i := 0; i < iterations; i++ {
+		// Stop once the context deadline has passed
+		if ctx.Err() != nil {
+			result.Data["stoppedEarly"] = fmt.Sprintf("%v after %d of %d iterations", ctx.Err(), completed, iterations)
+			break
+		}
+
+		var transactions []*databases.Transaction
+		err := collector.MeasureOperation(
+			metrics.QueryOperation,
+			limit,
+			0,
+			isColdStart && i == 0,
+			func() error {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
+				var queryErr error
+				transactions, queryErr = querier.QueryTransactionsByAmountRange(reqCtx, accountID, minAmount, maxAmount, queryOptions)
+				return queryErr
+			},
+		)
+		completed++
+
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("amount query %d failed: %w", i, err))
+			continue
+		}
+		itemsReturned = len(transactions)
+		result.ItemsProcessed += len(transactions)
+	}
+
+	result.Data["itemsReturned"] = itemsReturned
+	result.Data["minAmount"] = minAmount
+	result.Data["maxAmount"] = maxAmount
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if every query failed
+	if completed > 0 && len(result.Errors) == completed {
+		return result, fmt.Errorf("all amount queries failed: %w", result.Errors[0])
+	}
+
+	return result, nil
+}
+
+// ColdStartOperation benchmarks database initialization by closing and initializing
+// the adapter repeatedly
+type ColdStartOperation struct {
+	baseOperation
+}
+
+// NewColdStartOperation creates a new cold-start operation
+func NewColdStartOperation(params map[string]interface{}) *ColdStartOperation {
+	return &ColdStartOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: false,
+		},
+	}
+}
+
+// Execute closes and re-initializes databases that implement databases.Reinitializable
+// and measures each Initialize call, which covers connection setup and the table checks
+func (op *ColdStartOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	if r, ok := db.(databases.Reinitializable); !ok || !r.CanReinitialize() {
+		return result, fmt.Errorf("cold-start: %w", databases.ErrNotSupported)
+	}
+
+	// Get parameters
+	iterations := getParam(op.params, "iterations", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+
+	initLatencies := make([]float64, 0, iterations)
+	completed := 0
+	for i := 0; i < iterations; i++ {
+		// Stop once the context deadline has passed
+		if ctx.Err() != nil {
+			result.Data["stoppedEarly"] = fmt.Sprintf("%v after %d of %d iterations", ctx.Err(), completed, iterations)
+			break
+		}
+
+		// Closing is not part of the measured initialization
+		if err := db.Close(); err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("close %d failed: %w", i, err))
+		}
+
+		initStart := time.Now()
+		err := collector.MeasureOperation(
+			metrics.TransactionOperation,
+			1,
+			0,
+			isColdStart && i == 0,
+			func() error {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
+				return db.Initialize(reqCtx)
+			},
+		)
+		completed++
+
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("initialize %d failed: %w", i, err))
+			continue
+		}
+		initLatencies = append(initLatencies, float64(time.Since(initStart))/float64(time.Millisecond))
+		result.ItemsProcessed++
+	}
+
+	result.Data["initLatenciesMs"] = initLatencies
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if every initialization failed
+	if completed > 0 && result.ItemsProcessed == 0 {
+		return result, fmt.Errorf("all initializations failed: %w", result.Errors[len(result.Errors)-1])
+	}
+
+	return result, nil
+}
+
+// Count Operation
+type CountOperation struct {
+	baseOperation
+}
+
+// NewCountOperation creates a new operation counting an account's transactions
+func NewCountOperation(params map[string]interface{}) *CountOperation {
+	return &CountOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: false,
+		},
+	}
+}
+
+// Execute counts the transactions of the account repeatedly and reports the last count
+func (op *CountOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	// Get parameters
+	accountID := getParam(op.params, "accountId", "test-account")
+	iterations := getParam(op.params, "iterations", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+
+	var count int64
+	completed := 0
+	for i := 0; i < iterations; i++ {
+		// Stop once the context deadline has passed
+		if ctx.Err() != nil {
+			result.Data["stoppedEarly"] = fmt.Sprintf("%v after %d of %d iterations", ctx.Err(), completed, iterations)
+			break
+		}
+
+		err := collector.MeasureOperation(
+			metrics.QueryOperation,
+			1, // One count value
+			0,
+			isColdStart && i == 0,
+			func() error {
+				reqCtx, cancel := withOperationTimeout(ctx, op.params)
+				defer cancel()
+				var countErr error
+				count, countErr = db.CountTransactionsByAccount(reqCtx, accountID)
+				return countErr
+			},
+		)
+		completed++
+
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("count query %d failed: %w", i, err))
+			continue
+		}
+		result.ItemsProcessed++
+	}
+
+	result.Data["accountId"] = accountID
+	result.Data["count"] = count
+
+	// Calculate total duration
+	result.TotalDuration = time.Since(startTime)
+
+	// Return error if every query failed
+	if completed > 0 && len(result.Errors) == completed {
+		return result, fmt.Errorf("all count queries failed: %w", result.Errors[0])
+	}
+
+	return result, nil
+}
+
+// Conditional Write Operation
+type ConditionalWriteOperation struct {
+	baseOperation
+}
+
+// NewConditionalWriteOperation creates a new operation measuring conditional write contention
+func NewConditionalWriteOperation(params map[string]interface{}) *ConditionalWriteOperation {
+	return &ConditionalWriteOperation{
+		baseOperation: baseOperation{
+			params:     params,
+			isParallel: true,
+		},
+	}
+}
+
+// Execute has contenders writers race to create each of itemCount fresh keys with an
+// attribute_not_exists(uuid) condition. With working optimistic concurrency exactly one
+// writer per key succeeds and the others fail their condition.
+func (op *ConditionalWriteOperation) Execute(ctx context.Context, db databases.Database, collector *metrics.Collector) (OperationResult, error) {
+	startTime := time.Now()
+	result := OperationResult{
+		Errors: []error{},
+		Data:   make(map[string]interface{}),
+	}
+
+	// Get parameters
+	count := getParam(op.params, "itemCount", 100)
+	contenders := getParam(op.params, "contenders", 2)
+	concurrency := getParam(op.params, "concurrency", 10)
+	isColdStart := getParam(op.params, "isColdStart", false)
+	dataSizeBytes := getParam(op.params, "dataSize", 1024)
+
+	if contenders < 1 {
+		contenders = 1
+	}
+
+	writeOptions := &databases.WriteOptions{
+		Condition: "attribute_not_exists(uuid)",
+	}
+
+	// Keys are unique to this run so every key starts out absent
+	runID := uuid.New().String()[:8]
+
+	var (
+		wg              sync.WaitGroup
+		mu              sync.Mutex
+		successful      int
+		conditionFailed int
+		multipleWinners int
+		completed       atomic.Int64
+	)
+	semaphore := make(chan struct{}, concurrency)
+
+	for i := 0; i < count; i++ {
+		// Stop launching keys once the deadline has passed
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			tx := generateTransaction(op.params, index)
+			tx.UUID = fmt.Sprintf("%s-cw-%s-%d", tx.AccountID, runID, index)
+
+			// Release all contenders for the key at once
+			start := make(chan struct{})
+			errs := make([]error, contenders)
+			var contenderWg sync.WaitGroup
+			for c := 0; c < contenders; c++ {
+				contenderWg.Add(1)
+				go func(c int) {
+					defer contenderWg.Done()
+					attempt := *tx
+					<-start
+					errs[c] = collector.MeasureOperationWithResult(
+						metrics.WriteOperation,
+						1,
+						int64(dataSizeBytes),
+						isColdStart,
+						func() (map[string]interface{}, error) {
+							reqCtx, cancel := withOperationTimeout(ctx, op.params)
+							defer cancel()
+							reqCtx, trace := databases.WithRequestTrace(reqCtx)
+							err := db.WriteTransaction(reqCtx, &attempt, writeOptions)
+							return trace.Metrics(), err
+						},
+					)
+				}(c)
+			}
+			close(start)
+			contenderWg.Wait()
+			completed.Add(1)
+
+			winners := 0
+			mu.Lock()
+			defer mu.Unlock()
+			for _, err := range errs {
+				switch {
+				case err == nil:
+					winners++
+					successful++
+				case databases.IsConditionFailed(err):
+					conditionFailed++
+				default:
+					result.Errors = append(result.Errors, fmt.Errorf("conditional write of %s failed: %w", tx.UUID, err))
+				}
+			}
+			if winners > 1 {
+				multipleWinners++
+			}
+		}(i)
+	}
+
+	// Wait for all keys to complete
+	wg.Wait()
+
+	// ItemsProcessed always counts winning writes, also when the deadline cut the run
+	// short; the keys contended are reported separately
+	stopEarly(ctx, &result, int(completed.Load()), count)
+	result.ItemsProcessed = successful
+
+	result.Data["keysCompleted"] = int(completed.Load())
+	result.Data["attempts"] = int(completed.Load()) * contenders
+	result.Data["conditionFailed"] = conditionFailed
+	result.Data["keysWithMultipleWinners"] = multipleWinners
+
+	// Calculate total duration
 	result.TotalDuration = time.Since(startTime)
 
+	// Return error if no attempt reached the database successfully
+	if successful == 0 && conditionFailed == 0 && len(result.Errors) > 0 {
+		return result, fmt.Errorf("all conditional writes failed: %w", result.Errors[0])
+	}
+
 	return result, nil
 }
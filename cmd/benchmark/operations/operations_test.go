@@ -0,0 +1,659 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+func TestGetParam(t *testing.T) {
+	// Lambda decodes JSON numbers as float64, so numeric parameters must convert
+	params := map[string]interface{}{
+		"jsonInt":   float64(250),
+		"jsonFloat": 0.75,
+		"int":       25,
+		"int64":     int64(500),
+		"string":    "zipfian",
+		"bool":      true,
+	}
+
+	t.Run("int", func(t *testing.T) {
+		tests := []struct {
+			key  string
+			want int
+		}{
+			{"jsonInt", 250},
+			{"int", 25},
+			{"int64", 500},
+			{"string", 100},
+			{"missing", 100},
+		}
+		for _, tt := range tests {
+			if got := getParam(params, tt.key, 100); got != tt.want {
+				t.Errorf("getParam(%q, 100) = %d, want %d", tt.key, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("int64", func(t *testing.T) {
+		tests := []struct {
+			key  string
+			want int64
+		}{
+			{"jsonInt", 250},
+			{"int", 25},
+			{"int64", 500},
+			{"bool", 100},
+		}
+		for _, tt := range tests {
+			if got := getParam(params, tt.key, int64(100)); got != tt.want {
+				t.Errorf("getParam(%q, int64(100)) = %d, want %d", tt.key, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("float64", func(t *testing.T) {
+		tests := []struct {
+			key  string
+			want float64
+		}{
+			{"jsonFloat", 0.75},
+			{"int", 25},
+			{"missing", 0.5},
+		}
+		for _, tt := range tests {
+			if got := getParam(params, tt.key, 0.5); got != tt.want {
+				t.Errorf("getParam(%q, 0.5) = %v, want %v", tt.key, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("non-numeric", func(t *testing.T) {
+		if got := getParam(params, "string", "uniform"); got != "zipfian" {
+			t.Errorf("getParam(%q) = %q, want %q", "string", got, "zipfian")
+		}
+		if got := getParam(params, "jsonInt", "uniform"); got != "uniform" {
+			t.Errorf("getParam(%q) = %q, want the default", "jsonInt", got)
+		}
+		if got := getParam(params, "bool", false); !got {
+			t.Errorf("getParam(%q) = false, want true", "bool")
+		}
+	})
+}
+
+// newTestCollector returns a collector with a test started, as the handler does
+func newTestCollector(t *testing.T) *metrics.Collector {
+	t.Helper()
+	collector := metrics.NewCollector()
+	collector.StartTest(t.Name(), "", "dbtest", nil, nil)
+	return collector
+}
+
+func TestConditionalWriteItemsProcessed(t *testing.T) {
+	tests := []struct {
+		name       string
+		cancelAt   int64 // Write call after which the context is canceled; 0 never cancels
+		wantKeys   int
+		stopsEarly bool
+	}{
+		{"complete run", 0, 20, false},
+		{"deadline", 15, 6, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Cancel once the contenders of the first five keys have started; later
+			// writes fail as a deadline would
+			var calls atomic.Int64
+			db := dbtest.New()
+			db.Hook = func(ctx context.Context, method, uuid string) error {
+				n := calls.Add(1)
+				if tt.cancelAt > 0 && n == tt.cancelAt {
+					cancel()
+				} else if tt.cancelAt > 0 && n > tt.cancelAt {
+					return ctx.Err()
+				}
+				return nil
+			}
+
+			op := NewConditionalWriteOperation(map[string]interface{}{
+				"itemCount":   20,
+				"contenders":  3,
+				"concurrency": 1,
+			})
+			result, err := op.Execute(ctx, db, newTestCollector(t))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			// ItemsProcessed counts winning writes whether or not the run stopped early
+			if result.ItemsProcessed != db.Len() {
+				t.Errorf("ItemsProcessed = %d, want the %d winning writes", result.ItemsProcessed, db.Len())
+			}
+			if got := result.Data["keysCompleted"]; got != tt.wantKeys {
+				t.Errorf("keysCompleted = %v, want %d", got, tt.wantKeys)
+			}
+			if _, stopped := result.Data["stoppedEarly"]; stopped != tt.stopsEarly {
+				t.Errorf("stoppedEarly present = %v, want %v", stopped, tt.stopsEarly)
+			}
+			if !tt.stopsEarly && result.Data["conditionFailed"] != 40 {
+				t.Errorf("conditionFailed = %v, want 40", result.Data["conditionFailed"])
+			}
+		})
+	}
+}
+
+func TestPerOperationTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		op        Operation
+		method    string // Database call that blocks once
+		wantCalls int
+	}{
+		{
+			"read",
+			NewReadOperation(map[string]interface{}{"itemCount": 5, "perOperationTimeoutMs": 20}, false),
+			"ReadTransaction", 5,
+		},
+		{
+			"mixed",
+			NewMixedOperation(map[string]interface{}{"itemCount": 10, "readRatio": 1.0, "seedCount": 5, "perOperationTimeoutMs": 20}),
+			"ReadTransaction", 10,
+		},
+		{
+			"count",
+			NewCountOperation(map[string]interface{}{"iterations": 3, "perOperationTimeoutMs": 20}),
+			"CountTransactionsByAccount", 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The first call blocks until its context ends, as a hung request would
+			var blocked atomic.Bool
+			db := dbtest.New()
+			db.Put(generateTransaction(nil, 0), generateTransaction(nil, 1), generateTransaction(nil, 2),
+				generateTransaction(nil, 3), generateTransaction(nil, 4))
+			db.Hook = func(ctx context.Context, method, uuid string) error {
+				if method == tt.method && blocked.CompareAndSwap(false, true) {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				return nil
+			}
+
+			collector := newTestCollector(t)
+			done := make(chan struct{})
+			var result OperationResult
+			var err error
+			go func() {
+				defer close(done)
+				result, err = tt.op.Execute(context.Background(), db, collector)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Execute() did not return; the blocked call was not abandoned")
+			}
+
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if len(result.Errors) != 1 || !errors.Is(result.Errors[0], context.DeadlineExceeded) {
+				t.Errorf("Errors = %v, want one deadline exceeded", result.Errors)
+			}
+			// The operation moves on to the remaining items
+			if got := db.Calls(tt.method); got != tt.wantCalls {
+				t.Errorf("%s called %d times, want %d", tt.method, got, tt.wantCalls)
+			}
+
+			timeouts := 0
+			for _, op := range collector.EndTest(t.Name()).Operations {
+				if op.ErrorCategory == metrics.ErrorCategoryTimeout {
+					timeouts++
+				}
+			}
+			if timeouts != 1 {
+				t.Errorf("recorded %d timeouts, want 1", timeouts)
+			}
+		})
+	}
+}
+
+func TestColdStartKeepsAdapterMetrics(t *testing.T) {
+	db := dbtest.New()
+	op := NewColdStartOperation(map[string]interface{}{"iterations": 5})
+
+	result, err := op.Execute(context.Background(), db, newTestCollector(t))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.ItemsProcessed != 5 {
+		t.Errorf("ItemsProcessed = %d, want 5", result.ItemsProcessed)
+	}
+
+	// Re-initializing must not discard what the earlier iterations recorded
+	if db.MetricResets() != 0 {
+		t.Errorf("metrics reset %d times, want 0", db.MetricResets())
+	}
+	if got := db.GetMetrics()["Initialize"]; got != 5 {
+		t.Errorf("Initialize calls in the adapter metrics = %v, want 5", got)
+	}
+}
+
+func TestDeleteOperation(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		t.Run(fmt.Sprintf("parallel=%v", parallel), func(t *testing.T) {
+			// Populate eight of the ten IDs the operation generates
+			db := dbtest.New()
+			for i := 0; i < 8; i++ {
+				db.Put(&databases.Transaction{AccountID: "test-account", UUID: fmt.Sprintf("test-account-tx-%d", i), Timestamp: time.Now()})
+			}
+
+			op := NewDeleteOperation(map[string]interface{}{
+				"itemCount": 10,
+				"condition": "attribute_exists(uuid)",
+			}, parallel)
+			result, err := op.Execute(context.Background(), db, newTestCollector(t))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if result.ItemsProcessed != 10 {
+				t.Errorf("ItemsProcessed = %d, want 10", result.ItemsProcessed)
+			}
+			if db.Len() != 0 {
+				t.Errorf("%d transactions left after the deletes, want 0", db.Len())
+			}
+
+			// The two missing IDs fail the condition without stopping the run
+			if len(result.Errors) != 2 {
+				t.Errorf("Errors = %v, want 2", result.Errors)
+			}
+			for _, err := range result.Errors {
+				if !databases.IsConditionFailed(err) {
+					t.Errorf("error = %v, want ErrConditionFailed", err)
+				}
+			}
+			if result.Data["conditionFailed"] != 2 {
+				t.Errorf("conditionFailed = %v, want 2", result.Data["conditionFailed"])
+			}
+		})
+	}
+
+	t.Run("all missing", func(t *testing.T) {
+		op := NewDeleteOperation(map[string]interface{}{
+			"transactionIDs": []string{"tx-1", "tx-2"},
+			"condition":      "attribute_exists(uuid)",
+		}, false)
+		result, err := op.Execute(context.Background(), dbtest.New(), newTestCollector(t))
+		if err == nil {
+			t.Error("Execute() error = nil, want an error when every delete fails")
+		}
+		if len(result.Errors) != 2 {
+			t.Errorf("Errors = %v, want 2", result.Errors)
+		}
+	})
+}
+
+func TestMixedOperationRatio(t *testing.T) {
+	tests := []struct {
+		name      string
+		readRatio float64
+		itemCount int
+		wantReads int
+	}{
+		{"70/30", 0.7, 100, 70},
+		{"rounds to the nearest count", 0.7, 15, 11},
+		{"reads only", 1.0, 20, 20},
+		{"writes only", 0.0, 20, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.New()
+			op := NewMixedOperation(map[string]interface{}{
+				"itemCount":   tt.itemCount,
+				"readRatio":   tt.readRatio,
+				"seedCount":   10,
+				"concurrency": 4,
+			})
+			collector := newTestCollector(t)
+
+			result, err := op.Execute(context.Background(), db, collector)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if len(result.Errors) != 0 {
+				t.Fatalf("Errors = %v, want none", result.Errors)
+			}
+
+			wantWrites := tt.itemCount - tt.wantReads
+			if result.Data["readCount"] != tt.wantReads || result.Data["writeCount"] != wantWrites {
+				t.Errorf("readCount/writeCount = %v/%v, want %d/%d", result.Data["readCount"], result.Data["writeCount"], tt.wantReads, wantWrites)
+			}
+			// Seeding is a single unmeasured batch write, so every read and write is a workload call
+			if got := db.Calls("ReadTransaction"); got != tt.wantReads {
+				t.Errorf("ReadTransaction called %d times, want %d", got, tt.wantReads)
+			}
+			if got := db.Calls("WriteTransaction"); got != wantWrites {
+				t.Errorf("WriteTransaction called %d times, want %d", got, wantWrites)
+			}
+			if db.Len() != 10+wantWrites {
+				t.Errorf("stored %d transactions, want the 10 seeded plus %d written", db.Len(), wantWrites)
+			}
+
+			byType, _ := collector.EndTest(t.Name()).Summary["byOperationType"].(map[string]interface{})
+			for opType, want := range map[metrics.OperationType]int{metrics.ReadOperation: tt.wantReads, metrics.WriteOperation: wantWrites} {
+				entry, ok := byType[string(opType)].(map[string]interface{})
+				if want == 0 {
+					if ok {
+						t.Errorf("byOperationType has a %s entry, want none", opType)
+					}
+					continue
+				}
+				if !ok || entry["count"] != int64(want) {
+					t.Errorf("byOperationType[%s] = %v, want a count of %d", opType, byType[string(opType)], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSeedThenRead(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"one account", map[string]interface{}{"itemCount": 1000, "dataSize": 64}},
+		{"spread over accounts", map[string]interface{}{"itemCount": 1000, "dataSize": 64, "numAccounts": 4, "accountId": "seeded"}},
+		// Seeding ignores useRandomIDs so the reads find the IDs it wrote
+		{"random IDs requested", map[string]interface{}{"itemCount": 1000, "dataSize": 64, "useRandomIDs": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.New()
+			seeded, err := NewSeedOperation(tt.params).Execute(context.Background(), db, newTestCollector(t))
+			if err != nil {
+				t.Fatalf("seed Execute() error = %v", err)
+			}
+			if seeded.ItemsProcessed != 1000 || seeded.Data["itemsWritten"] != 1000 || db.Len() != 1000 {
+				t.Fatalf("seeded %d items (%v reported), stored %d, want 1000", seeded.ItemsProcessed, seeded.Data["itemsWritten"], db.Len())
+			}
+			// 1000 items in batches of the default 25
+			if got := db.Calls("BatchWriteTransactions"); got != 40 {
+				t.Errorf("BatchWriteTransactions called %d times, want 40", got)
+			}
+
+			readParams := make(map[string]interface{})
+			for k, v := range tt.params {
+				if k != "useRandomIDs" {
+					readParams[k] = v
+				}
+			}
+			for _, parallel := range []bool{false, true} {
+				read, err := NewReadOperation(readParams, parallel).Execute(context.Background(), db, newTestCollector(t))
+				if err != nil {
+					t.Fatalf("read Execute(parallel=%v) error = %v", parallel, err)
+				}
+				if read.ItemsProcessed != 1000 || len(read.Errors) != 0 || read.Data["notFound"] != int64(0) {
+					t.Errorf("read (parallel=%v) processed %d with %d errors and %v not found, want all 1000 found",
+						parallel, read.ItemsProcessed, len(read.Errors), read.Data["notFound"])
+				}
+			}
+		})
+	}
+}
+
+func TestDeadlineStopsEarly(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         Operation
+		method     string
+		itemsPerOp int
+		seed       bool
+	}{
+		{"sequential reads", NewReadOperation(map[string]interface{}{"itemCount": 100}, false), "ReadTransaction", 1, true},
+		{"parallel reads", NewReadOperation(map[string]interface{}{"itemCount": 100, "concurrency": 2}, true), "ReadTransaction", 1, true},
+		{"writes", NewWriteOperation(map[string]interface{}{"itemCount": 100, "dataSize": 16}, false), "WriteTransaction", 1, false},
+		{"batch writes", NewWriteOperation(map[string]interface{}{"itemCount": 100, "dataSize": 16, "batchSize": 5, "concurrency": 1}, true), "BatchWriteTransactions", 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.New()
+			if tt.seed {
+				for i := 0; i < 100; i++ {
+					db.Put(generateTransaction(nil, i))
+				}
+			}
+			// Every call takes 10ms, so the deadline passes after a handful of them
+			db.Hook = func(ctx context.Context, method, uuid string) error {
+				select {
+				case <-time.After(10 * time.Millisecond):
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			result, err := tt.op.Execute(ctx, db, newTestCollector(t))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Execute() took %v, want it to stop at the deadline", elapsed)
+			}
+
+			// Only the calls that ran count as processed
+			completed := db.Calls(tt.method) * tt.itemsPerOp
+			if completed == 0 || completed >= 100 {
+				t.Fatalf("%s called %d times, want the run cut short", tt.method, db.Calls(tt.method))
+			}
+			if result.ItemsProcessed != completed {
+				t.Errorf("ItemsProcessed = %d, want the %d completed", result.ItemsProcessed, completed)
+			}
+			want := fmt.Sprintf("context deadline exceeded after %d of 100 items", completed)
+			if result.Data["stoppedEarly"] != want {
+				t.Errorf("stoppedEarly = %v, want %q", result.Data["stoppedEarly"], want)
+			}
+		})
+	}
+}
+
+func TestWriteVerification(t *testing.T) {
+	tests := []struct {
+		name         string
+		dropEvery    int // Drop every Nth transaction; 0 keeps all
+		fraction     float64
+		wantVerified int
+		wantFailures int
+	}{
+		{"all writes stored", 0, 1.0, 20, 0},
+		{"dropped writes", 4, 1.0, 20, 5},
+		{"fraction rounds up", 0, 0.12, 3, 0},
+		{"fraction above one reads everything once", 2, 3.0, 20, 10},
+	}
+
+	for _, tt := range tests {
+		for _, batch := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/batch=%v", tt.name, batch), func(t *testing.T) {
+				// The writes succeed, but some are silently not persisted
+				db := dbtest.New()
+				db.DropWrite = func(tx *databases.Transaction) bool {
+					var i int
+					fmt.Sscanf(tx.UUID, "test-account-tx-%d", &i)
+					return tt.dropEvery > 0 && i%tt.dropEvery == 0
+				}
+
+				op := NewWriteOperation(map[string]interface{}{
+					"itemCount":      20,
+					"dataSize":       16,
+					"batchSize":      5,
+					"verifyFraction": tt.fraction,
+				}, batch)
+				collector := newTestCollector(t)
+				result, err := op.Execute(context.Background(), db, collector)
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				if len(result.Errors) != 0 {
+					t.Errorf("Errors = %v, want the dropped writes to look successful", result.Errors)
+				}
+
+				if result.Data["verifiedCount"] != tt.wantVerified || result.Data["verificationFailures"] != tt.wantFailures {
+					t.Errorf("verifiedCount/verificationFailures = %v/%v, want %d/%d",
+						result.Data["verifiedCount"], result.Data["verificationFailures"], tt.wantVerified, tt.wantFailures)
+				}
+				if got := db.Calls("ReadTransaction"); got != tt.wantVerified {
+					t.Errorf("ReadTransaction called %d times, want %d", got, tt.wantVerified)
+				}
+
+				// The read-backs are not measured
+				for _, measured := range collector.EndTest(t.Name()).Operations {
+					if measured.Type == metrics.ReadOperation {
+						t.Fatal("verification reads were measured")
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		db := dbtest.New()
+		result, err := NewWriteOperation(map[string]interface{}{"itemCount": 5, "dataSize": 16}, false).Execute(context.Background(), db, newTestCollector(t))
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if _, ok := result.Data["verifiedCount"]; ok || db.Calls("ReadTransaction") != 0 {
+			t.Errorf("verification ran without verifyFraction: %v", result.Data)
+		}
+	})
+}
+
+func TestWriteAdaptive(t *testing.T) {
+	throttled := errors.New("ProvisionedThroughputExceededException: rate exceeded")
+
+	tests := []struct {
+		name       string
+		numBatches int
+		fail       map[int]error // Errors returned by batch index
+		wantSeries []int
+		wantErrs   int
+	}{
+		{"grows to the maximum", 14, nil, []int{1, 2, 3, 4, 4}, 0},
+		// The round after halving has a lower throughput than the throttled one, so the
+		// workers only grow again from the round after it
+		{"throttling halves the workers", 12, map[int]error{3: throttled}, []int{1, 2, 3, 1, 1, 2, 3}, 1},
+		{"other errors hold the workers", 8, map[int]error{1: errors.New("boom")}, []int{1, 2, 2, 3}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			written := make(map[int]int)
+			series, errs := writeAdaptive(context.Background(), tt.numBatches, 4, func(batchIndex int) error {
+				// Later batches are faster, so a round beats the one before it unless it
+				// has fewer workers
+				time.Sleep(time.Duration(5*(14-batchIndex)) * time.Millisecond)
+				mu.Lock()
+				written[batchIndex]++
+				mu.Unlock()
+				return tt.fail[batchIndex]
+			})
+
+			if !reflect.DeepEqual(series, tt.wantSeries) {
+				t.Errorf("concurrency series = %v, want %v", series, tt.wantSeries)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("errors = %v, want %d", errs, tt.wantErrs)
+			}
+			// Every batch is written exactly once
+			for i := 0; i < tt.numBatches; i++ {
+				if written[i] != 1 {
+					t.Errorf("batch %d written %d times, want once", i, written[i])
+				}
+			}
+		})
+	}
+
+	t.Run("stops when the context ends", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls atomic.Int64
+		series, _ := writeAdaptive(ctx, 100, 4, func(batchIndex int) error {
+			if calls.Add(1) == 3 {
+				cancel()
+			}
+			return nil
+		})
+		if calls.Load() != 3 || len(series) != 2 {
+			t.Errorf("wrote %d batches in %d rounds after cancelling on the third, want 3 in 2", calls.Load(), len(series))
+		}
+	})
+}
+
+func TestWriteByteCounts(t *testing.T) {
+	tests := []struct {
+		name          string
+		itemSizeMode  string
+		wantRequested bool
+	}{
+		{"serialized", "", false},
+		{"requested", "requested", true},
+	}
+
+	for _, tt := range tests {
+		for _, batch := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/batch=%v", tt.name, batch), func(t *testing.T) {
+				params := map[string]interface{}{"itemCount": 10, "dataSize": 200, "metadataShape": "json", "batchSize": 5}
+				if tt.itemSizeMode != "" {
+					params["itemSizeMode"] = tt.itemSizeMode
+				}
+				collector := newTestCollector(t)
+				result, err := NewWriteOperation(params, batch).Execute(context.Background(), dbtest.New(), collector)
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+
+				// The JSON encoding adds attribute names and the other fields to the payload
+				avgSerialized, _ := result.Data["avgSerializedItemBytes"].(int)
+				if result.Data["requestedItemBytes"] != 200 || avgSerialized <= 200 {
+					t.Errorf("requestedItemBytes/avgSerializedItemBytes = %v/%v, want 200 and more than 200",
+						result.Data["requestedItemBytes"], result.Data["avgSerializedItemBytes"])
+				}
+
+				var items, bytes int64
+				for _, op := range collector.EndTest(t.Name()).Operations {
+					items += op.ItemCount
+					bytes += op.ByteCount
+				}
+				if items != 10 {
+					t.Fatalf("measured %d items, want 10", items)
+				}
+				if tt.wantRequested {
+					if bytes != 10*200 {
+						t.Errorf("measured %d bytes, want the requested 2000", bytes)
+					}
+				} else if bytes <= 10*200 || bytes/10 != int64(avgSerialized) && bytes/10 != int64(avgSerialized)+1 {
+					t.Errorf("measured %d bytes, want the serialized size of about %d per item", bytes, avgSerialized)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/dynamodb"
+)
+
+// Request represents the input for the benchmark Lambda function
+type Request struct {
+	AccountID        string   `json:"accountId"`
+	TransactionCount int      `json:"transactionCount"`
+	CollectMetrics   bool     `json:"collectMetrics"`
+	TransactionIDs   []string `json:"transactionIds"`
+	IsColdStart      bool     `json:"isColdStart"`
+	DataSizeBytes    int64    `json:"dataSizeBytes"`
+}
+
+// Response represents the output from the benchmark Lambda function
+type Response struct {
+	TransactionsDeleted int                    `json:"transactionsDeleted"`
+	TotalDuration       int64                  `json:"totalDurationNs"`
+	AvgDuration         int64                  `json:"avgDurationNs"`
+	TransactionIDs      []string               `json:"transactionIds,omitempty"`
+	Metrics             map[string]interface{} `json:"metrics,omitempty"`
+	Errors              []string               `json:"errors,omitempty"`
+	StoppedEarly        string                 `json:"stoppedEarly,omitempty"`
+}
+
+var (
+	db               databases.Database
+	metricsCollector = metrics.NewCollector()
+	isColdStart      = true
+)
+
+// openDatabase creates and initializes the package-level database from the
+// environment, exiting on failure. main calls it before starting the Lambda runtime,
+// so it still runs in the init phase while tests can set db to a fake instead.
+func openDatabase() {
+	// Get configuration from environment variables
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	tableName := os.Getenv("DYNAMODB_TABLE")
+	if tableName == "" {
+		tableName = "Transactions"
+	}
+
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+
+	// Create DynamoDB factory
+	factory := dynamodb.NewDynamoDBFactory()
+
+	// Configure DynamoDB
+	config := map[string]interface{}{
+		"region":    region,
+		"tableName": tableName,
+	}
+
+	if endpoint != "" {
+		config["endpoint"] = endpoint
+	}
+
+	var err error
+	db, err = factory.CreateDatabase(config)
+	if err != nil {
+		fmt.Printf("Error creating database: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Initialize the database
+	err = db.Initialize(context.Background())
+	if err != nil {
+		fmt.Printf("Error initializing database: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleRequest(ctx context.Context, request Request) (Response, error) {
+	functionStart := time.Now()
+	response := Response{
+		TransactionsDeleted: 0,
+		Errors:              []string{},
+	}
+
+	// Start metrics collection. The collector only measures operations while a test
+	// is running, so the test is started even when the metrics are not returned
+	testName := fmt.Sprintf("dynamodb-delete-%s", time.Now().Format(time.RFC3339))
+	metricsCollector.StartTest(
+		testName,
+		"Sequential delete operations on DynamoDB",
+		"dynamodb",
+		map[string]interface{}{"region": os.Getenv("AWS_REGION")},
+		map[string]interface{}{"tableName": os.Getenv("DYNAMODB_TABLE")},
+	)
+
+	// Track durations for calculating average
+	var durations []time.Duration
+	var transactionIDs []string
+
+	// If transaction IDs are provided, use them
+	// Otherwise delete the sequential IDs written by the write benchmark
+	if len(request.TransactionIDs) > 0 {
+		transactionIDs = request.TransactionIDs
+	} else {
+		for i := 0; i < request.TransactionCount; i++ {
+			transactionIDs = append(transactionIDs, fmt.Sprintf("txn-%07d", i))
+		}
+	}
+
+	// Perform sequential deletes, stopping once the Lambda deadline has passed
+	for i, transactionID := range transactionIDs {
+		if ctx.Err() != nil {
+			response.StoppedEarly = fmt.Sprintf("%v after %d of %d transactions", ctx.Err(), i, len(transactionIDs))
+			break
+		}
+
+		deleteStart := time.Now()
+
+		// Use the metrics collector to measure the operation
+		err := metricsCollector.MeasureOperation(
+			metrics.DeleteOperation,
+			1,
+			request.DataSizeBytes,
+			isColdStart && request.IsColdStart,
+			func() error {
+				return db.DeleteTransaction(ctx, request.AccountID, transactionID, nil)
+			},
+		)
+
+		deleteDuration := time.Since(deleteStart)
+		durations = append(durations, deleteDuration)
+
+		if err != nil {
+			errMsg := fmt.Sprintf("Error deleting transaction %s: %v", transactionID, err)
+			response.Errors = append(response.Errors, errMsg)
+		} else {
+			response.TransactionsDeleted++
+		}
+	}
+
+	// Calculate total and average durations
+	var totalDuration time.Duration
+	for _, d := range durations {
+		totalDuration += d
+	}
+
+	response.TotalDuration = totalDuration.Nanoseconds()
+	if len(durations) > 0 {
+		response.AvgDuration = totalDuration.Nanoseconds() / int64(len(durations))
+	}
+
+	// Include transaction IDs in response if specified
+	if len(request.TransactionIDs) == 0 {
+		response.TransactionIDs = transactionIDs
+	}
+
+	// Include metrics in response if requested
+	testResult := metricsCollector.EndTest(testName)
+	if request.CollectMetrics && testResult != nil {
+		response.Metrics = testResult.Summary
+	}
+
+	// Reset cold start flag after first invocation
+	isColdStart = false
+
+	// Log total execution time
+	elapsed := time.Since(functionStart)
+	fmt.Printf("Total execution time: %v\n", elapsed)
+
+	return response, nil
+}
+
+// closeDatabase releases the package-level connection when the container shuts down
+func closeDatabase() {
+	if err := db.Close(); err != nil {
+		fmt.Printf("Error closing database: %v\n", err)
+	}
+}
+
+func main() {
+	openDatabase()
+	lambda.StartWithOptions(handleRequest, lambda.WithEnableSIGTERM(closeDatabase))
+}
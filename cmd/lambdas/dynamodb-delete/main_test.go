@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+func TestCloseDatabase(t *testing.T) {
+	fake := dbtest.New()
+	db = fake
+
+	// The Lambda runtime runs closeDatabase when SIGTERM signals the container shutdown
+	closeDatabase()
+
+	if fake.Closed() != 1 {
+		t.Errorf("database closed %d times on shutdown, want 1", fake.Closed())
+	}
+}
+
+func TestHandleRequest(t *testing.T) {
+	// Three of the four generated IDs exist; deleting the missing one fails
+	fake := dbtest.New()
+	for i := 0; i < 3; i++ {
+		fake.Put(&databases.Transaction{AccountID: "account-1", UUID: fmt.Sprintf("txn-%07d", i), Timestamp: time.Now()})
+	}
+	fake.Hook = func(ctx context.Context, method, uuid string) error {
+		if method == "DeleteTransaction" && uuid == "txn-0000003" {
+			return databases.ErrTransactionNotFound
+		}
+		return nil
+	}
+	db = fake
+
+	response, err := handleRequest(context.Background(), Request{
+		AccountID:        "account-1",
+		TransactionCount: 4,
+		CollectMetrics:   true,
+	})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+
+	if response.TransactionsDeleted != 3 || fake.Len() != 0 {
+		t.Errorf("deleted %d with %d left, want 3 deleted and none left", response.TransactionsDeleted, fake.Len())
+	}
+	if len(response.Errors) != 1 || !strings.Contains(response.Errors[0], "txn-0000003") {
+		t.Errorf("Errors = %v, want one for txn-0000003", response.Errors)
+	}
+	if len(response.TransactionIDs) != 4 {
+		t.Errorf("TransactionIDs = %v, want the 4 generated IDs", response.TransactionIDs)
+	}
+	if response.TotalDuration <= 0 || response.Metrics == nil {
+		t.Errorf("TotalDuration = %d, Metrics = %v, want both set", response.TotalDuration, response.Metrics)
+	}
+}
+
+func TestHandleRequestDeadline(t *testing.T) {
+	db = dbtest.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	response, err := handleRequest(ctx, Request{AccountID: "account-1", TransactionIDs: []string{"txn-1", "txn-2"}})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+	if response.TransactionsDeleted != 0 || response.StoppedEarly == "" {
+		t.Errorf("deleted %d, StoppedEarly = %q, want no deletes and the stop reported", response.TransactionsDeleted, response.StoppedEarly)
+	}
+}
+
+func TestHandleRequestWithoutMetrics(t *testing.T) {
+	fake := dbtest.New()
+	fake.Put(&databases.Transaction{AccountID: "account-1", UUID: "txn-1", Timestamp: time.Now()})
+	db = fake
+
+	// Deletes are still measured when the caller does not ask for the metrics back
+	response, err := handleRequest(context.Background(), Request{AccountID: "account-1", TransactionIDs: []string{"txn-1"}})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+	if response.TransactionsDeleted != 1 || len(response.Errors) != 0 {
+		t.Errorf("deleted %d with Errors = %v, want 1 and none", response.TransactionsDeleted, response.Errors)
+	}
+	if response.Metrics != nil {
+		t.Errorf("Metrics = %v, want nil when not requested", response.Metrics)
+	}
+}
@@ -2,15 +2,10 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
-	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/google/uuid"
-	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
-	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
-	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/dynamodb"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/cmd/benchmark/handler"
 )
 
 // Request represents the input for the benchmark Lambda function
@@ -28,166 +23,72 @@ type Request struct {
 // Response represents the output from the benchmark Lambda function
 type Response struct {
 	TransactionsRead int                    `json:"transactionsRead"`
+	NotFound         int                    `json:"notFound"` // reads of transactions that don't exist
 	TotalDuration    int64                  `json:"totalDurationNs"`
 	AvgDuration      int64                  `json:"avgDurationNs"`
 	TransactionIDs   []string               `json:"transactionIds,omitempty"`
 	Metrics          map[string]interface{} `json:"metrics,omitempty"`
 	Errors           []string               `json:"errors,omitempty"`
+	StoppedEarly     string                 `json:"stoppedEarly,omitempty"`
 }
 
-var (
-	db               databases.Database
-	metricsCollector *metrics.Collector
-	isColdStart      = true
-)
-
-func init() {
-	// Initialize metrics collector
-	metricsCollector = metrics.NewCollector()
+// databaseType selects the adapter the benchmark runs against; tests point it at an
+// in-memory database
+var databaseType = "dynamodb"
 
-	// Get configuration from environment variables
-	region := os.Getenv("AWS_REGION")
-	if region == "" {
-		region = "us-east-1"
+// handleRequest translates the request into a read-sequential benchmark on DynamoDB,
+// configured from DYNAMODB_TABLE and DYNAMODB_ENDPOINT, and runs it through the
+// benchmark handler
+func handleRequest(ctx context.Context, request Request) (Response, error) {
+	params := map[string]interface{}{
+		"accountId":      request.AccountID,
+		"itemCount":      request.TransactionCount,
+		"consistentRead": request.ConsistentRead,
+		"useRandomIDs":   request.UseRandomIDs,
+		"dataSize":       int(request.DataSizeBytes),
+		"collectMetrics": request.CollectMetrics,
 	}
-
-	tableName := os.Getenv("DYNAMODB_TABLE")
-	if tableName == "" {
-		tableName = "Transactions"
+	if len(request.TransactionIDs) > 0 {
+		params["transactionIDs"] = request.TransactionIDs
 	}
-
-	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
-
-	// Create DynamoDB factory
-	factory := dynamodb.NewDynamoDBFactory()
-
-	// Configure DynamoDB
-	config := map[string]interface{}{
-		"region":    region,
-		"tableName": tableName,
+	if tableName := os.Getenv("DYNAMODB_TABLE"); tableName != "" {
+		params["db.tableName"] = tableName
 	}
-
-	if endpoint != "" {
-		config["endpoint"] = endpoint
+	if endpoint := os.Getenv("DYNAMODB_ENDPOINT"); endpoint != "" {
+		params["db.endpoint"] = endpoint
 	}
 
-	var err error
-	db, err = factory.CreateDatabase(config)
+	benchmark, result, err := handler.Run(ctx, handler.BenchmarkRequest{
+		DatabaseType:  databaseType,
+		OperationType: "read-sequential",
+		Parameters:    params,
+	})
 	if err != nil {
-		fmt.Printf("Error creating database: %v\n", err)
-		os.Exit(1)
+		return Response{}, err
 	}
 
-	// Initialize the database
-	err = db.Initialize(context.Background())
-	if err != nil {
-		fmt.Printf("Error initializing database: %v\n", err)
-		os.Exit(1)
-	}
-}
-
-func handleRequest(ctx context.Context, request Request) (Response, error) {
-	functionStart := time.Now()
 	response := Response{
-		TransactionsRead: 0,
-		Errors:           []string{},
+		TotalDuration: benchmark.TotalDurationNs,
+		AvgDuration:   benchmark.AvgOperationDurationNs,
+		Metrics:       benchmark.Metrics,
+		Errors:        []string{},
+		StoppedEarly:  benchmark.StoppedEarly,
 	}
-
-	// Start metrics collection if requested
-	if request.CollectMetrics {
-		testName := fmt.Sprintf("dynamodb-read-sequential-%s", time.Now().Format(time.RFC3339))
-		metricsCollector.StartTest(
-			testName,
-			"Sequential read operations on DynamoDB",
-			"dynamodb",
-			map[string]interface{}{"region": os.Getenv("AWS_REGION")},
-			map[string]interface{}{"tableName": os.Getenv("DYNAMODB_TABLE")},
-		)
+	notFound, _ := result.Data["notFound"].(int64)
+	response.NotFound = int(notFound)
+	response.TransactionsRead = max(0, benchmark.ItemsProcessed-response.NotFound-len(result.Errors))
+	for _, err := range result.Errors {
+		response.Errors = append(response.Errors, err.Error())
 	}
-
-	// Read options
-	readOptions := &databases.ReadOptions{
-		ConsistentRead: request.ConsistentRead,
+	if benchmark.ErrorMessage != "" {
+		response.Errors = append(response.Errors, benchmark.ErrorMessage)
 	}
 
-	// Track durations for calculating average
-	var durations []time.Duration
-	var transactionIDs []string
-
-	// If transaction IDs are provided, use them
-	// Otherwise generate random IDs if requested or sequential IDs
-	if len(request.TransactionIDs) > 0 {
-		transactionIDs = request.TransactionIDs
-	} else if request.UseRandomIDs {
-		// Generate random transaction IDs
-		for i := 0; i < request.TransactionCount; i++ {
-			transactionIDs = append(transactionIDs, uuid.New().String())
-		}
-	} else {
-		// Generate sequential transaction IDs
-		for i := 0; i < request.TransactionCount; i++ {
-			transactionIDs = append(transactionIDs, fmt.Sprintf("txn-%07d", i))
-		}
-	}
-
-	// Perform sequential reads
-	for _, transactionID := range transactionIDs {
-		readStart := time.Now()
-
-		// Use the metrics collector to measure the operation
-		err := metricsCollector.MeasureOperation(
-			metrics.ReadOperation,
-			1,
-			request.DataSizeBytes,
-			isColdStart && request.IsColdStart,
-			func() error {
-				_, err := db.ReadTransaction(ctx, request.AccountID, transactionID, readOptions)
-				return err
-			},
-		)
-
-		readDuration := time.Since(readStart)
-		durations = append(durations, readDuration)
-
-		if err != nil {
-			errMsg := fmt.Sprintf("Error reading transaction %s: %v", transactionID, err)
-			response.Errors = append(response.Errors, errMsg)
-		} else {
-			response.TransactionsRead++
-		}
-	}
-
-	// Calculate total and average durations
-	var totalDuration time.Duration
-	for _, d := range durations {
-		totalDuration += d
-	}
-
-	response.TotalDuration = totalDuration.Nanoseconds()
-	if len(durations) > 0 {
-		response.AvgDuration = totalDuration.Nanoseconds() / int64(len(durations))
-	}
-
-	// Include transaction IDs in response if specified
+	// Include transaction IDs in response if they were generated
 	if len(request.TransactionIDs) == 0 {
-		response.TransactionIDs = transactionIDs
+		response.TransactionIDs, _ = result.Data["transactionIDs"].([]string)
 	}
 
-	// Include metrics in response if requested
-	if request.CollectMetrics {
-		testResult := metricsCollector.EndTest(fmt.Sprintf("dynamodb-read-sequential-%s", time.Now().Format(time.RFC3339)))
-		if testResult != nil {
-			response.Metrics = testResult.Summary
-		}
-	}
-
-	// Reset cold start flag after first invocation
-	isColdStart = false
-
-	// Log total execution time
-	elapsed := time.Since(functionStart)
-	fmt.Printf("Total execution time: %v\n", elapsed)
-
 	return response, nil
 }
 
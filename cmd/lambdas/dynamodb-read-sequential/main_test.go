@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+func TestHandleRequest(t *testing.T) {
+	// Four of the five transactions read exist
+	db := dbtest.New()
+	for i := 0; i < 4; i++ {
+		db.Put(&databases.Transaction{AccountID: "account-1", UUID: fmt.Sprintf("account-1-tx-%d", i), Timestamp: time.Now()})
+	}
+
+	// Track reads in flight to tell sequential from parallel execution
+	var inFlight, maxInFlight atomic.Int64
+	db.Hook = func(ctx context.Context, method, uuid string) error {
+		if method == "ReadTransaction" {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			if n > maxInFlight.Load() {
+				maxInFlight.Store(n)
+			}
+			time.Sleep(2 * time.Millisecond)
+		}
+		return nil
+	}
+	dbtest.Register("lambda-test", db)
+	databaseType = "lambda-test"
+
+	response, err := handleRequest(context.Background(), Request{
+		AccountID:        "account-1",
+		TransactionCount: 5,
+		CollectMetrics:   true,
+	})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+
+	if len(response.Errors) != 0 {
+		t.Fatalf("Errors = %v", response.Errors)
+	}
+	if response.TransactionsRead != 4 || response.NotFound != 1 {
+		t.Errorf("read %d and not found %d, want 4 and 1", response.TransactionsRead, response.NotFound)
+	}
+	if len(response.TransactionIDs) != 5 {
+		t.Errorf("TransactionIDs = %v, want the 5 generated IDs", response.TransactionIDs)
+	}
+	if response.TotalDuration <= 0 || response.Metrics == nil {
+		t.Errorf("TotalDuration = %d, Metrics = %v, want both set", response.TotalDuration, response.Metrics)
+	}
+
+	// The handler opens a database per request and closes it before returning, so
+	// nothing is left open when the container shuts down
+	if db.Initialized() != 1 || db.Closed() != 1 {
+		t.Errorf("database initialized %d and closed %d times, want 1 and 1", db.Initialized(), db.Closed())
+	}
+
+	// The request routes to the read-sequential strategy
+	if db.Calls("ReadTransaction") != 5 || maxInFlight.Load() != 1 {
+		t.Errorf("%d reads with up to %d in flight, want 5 sequential reads", db.Calls("ReadTransaction"), maxInFlight.Load())
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/dynamodb"
+)
+
+// Request represents the input for the benchmark Lambda function
+type Request struct {
+	AccountID        string    `json:"accountId"`
+	TransactionCount int       `json:"transactionCount"`
+	TransactionIDs   []string  `json:"transactionIds"`
+	Amounts          []float64 `json:"amounts"` // New amount per transaction, or a single amount for all
+	CollectMetrics   bool      `json:"collectMetrics"`
+	IsColdStart      bool      `json:"isColdStart"`
+	DataSizeBytes    int64     `json:"dataSizeBytes"`
+	Concurrency      int       `json:"concurrency"`
+}
+
+// Response represents the output from the benchmark Lambda function
+type Response struct {
+	TransactionsUpdated int                    `json:"transactionsUpdated"`
+	TotalDuration       int64                  `json:"totalDurationNs"`
+	AvgDuration         int64                  `json:"avgDurationNs"`
+	TransactionIDs      []string               `json:"transactionIds,omitempty"`
+	Metrics             map[string]interface{} `json:"metrics,omitempty"`
+	Errors              []string               `json:"errors,omitempty"`
+	StoppedEarly        string                 `json:"stoppedEarly,omitempty"`
+}
+
+// Result represents the result of a single update operation
+type Result struct {
+	TransactionID string
+	Duration      time.Duration
+	Error         error
+}
+
+// updateJob is one transaction to update with its new amount
+type updateJob struct {
+	TransactionID string
+	Amount        float64
+}
+
+var (
+	db               databases.Database
+	metricsCollector = metrics.NewCollector()
+	isColdStart      = true
+)
+
+// openDatabase creates and initializes the package-level database from the
+// environment, exiting on failure. main calls it before starting the Lambda runtime,
+// so it still runs in the init phase while tests can set db to a fake instead.
+func openDatabase() {
+	// Get configuration from environment variables
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	tableName := os.Getenv("DYNAMODB_TABLE")
+	if tableName == "" {
+		tableName = "Transactions"
+	}
+
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+
+	// Create DynamoDB factory
+	factory := dynamodb.NewDynamoDBFactory()
+
+	// Configure DynamoDB
+	config := map[string]interface{}{
+		"region":    region,
+		"tableName": tableName,
+	}
+
+	if endpoint != "" {
+		config["endpoint"] = endpoint
+	}
+
+	var err error
+	db, err = factory.CreateDatabase(config)
+	if err != nil {
+		fmt.Printf("Error creating database: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Initialize the database
+	err = db.Initialize(context.Background())
+	if err != nil {
+		fmt.Printf("Error initializing database: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generateMetadata creates a metadata payload of roughly the specified size
+func generateMetadata(dataSize int64) map[string]interface{} {
+	metadata := make(map[string]interface{})
+	if dataSize > 0 {
+		payload := make([]byte, dataSize)
+		for i := range payload {
+			payload[i] = byte(i % 256) // Pattern to avoid compression in transit
+		}
+		metadata["payload"] = payload
+	}
+	return metadata
+}
+
+func handleRequest(ctx context.Context, request Request) (Response, error) {
+	functionStart := time.Now()
+	response := Response{
+		TransactionsUpdated: 0,
+		Errors:              []string{},
+	}
+
+	// If transaction IDs are provided, use them
+	// Otherwise update the sequential IDs written by the write benchmark
+	var transactionIDs []string
+	if len(request.TransactionIDs) > 0 {
+		transactionIDs = request.TransactionIDs
+	} else {
+		for i := 0; i < request.TransactionCount; i++ {
+			transactionIDs = append(transactionIDs, fmt.Sprintf("txn-%07d", i))
+		}
+	}
+
+	// Pair each transaction with its new amount
+	if len(request.Amounts) != 1 && len(request.Amounts) != len(transactionIDs) {
+		response.Errors = append(response.Errors, fmt.Sprintf("amounts must hold one amount or one per transaction, got %d for %d transactions", len(request.Amounts), len(transactionIDs)))
+		return response, nil
+	}
+	jobs := make([]updateJob, len(transactionIDs))
+	for i, id := range transactionIDs {
+		amount := request.Amounts[0]
+		if len(request.Amounts) > 1 {
+			amount = request.Amounts[i]
+		}
+		jobs[i] = updateJob{TransactionID: id, Amount: amount}
+	}
+
+	// Start metrics collection. The collector only measures operations while a test
+	// is running, so the test is started even when the metrics are not returned
+	testName := fmt.Sprintf("dynamodb-update-%s", time.Now().Format(time.RFC3339))
+	metricsCollector.StartTest(
+		testName,
+		"Update operations on DynamoDB",
+		"dynamodb",
+		map[string]interface{}{"region": os.Getenv("AWS_REGION")},
+		map[string]interface{}{"tableName": os.Getenv("DYNAMODB_TABLE")},
+	)
+
+	// Set concurrency level
+	concurrency := request.Concurrency
+	if concurrency <= 0 {
+		concurrency = 10 // Default concurrency
+	}
+
+	// Create a channel for results
+	results := make(chan Result, len(jobs))
+
+	// Create a worker pool
+	var wg sync.WaitGroup
+	jobChan := make(chan updateJob, len(jobs))
+
+	// Only update items that exist so a wrong ID is reported instead of creating a new item
+	writeOptions := &databases.WriteOptions{
+		Condition: "attribute_exists(accountId)",
+	}
+
+	// Start workers
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobChan {
+				// Drain remaining updates without running them once the deadline has passed
+				if ctx.Err() != nil {
+					continue
+				}
+
+				tx := &databases.Transaction{
+					AccountID: request.AccountID,
+					UUID:      job.TransactionID,
+					Amount:    job.Amount,
+					Metadata:  generateMetadata(request.DataSizeBytes),
+				}
+
+				updateStart := time.Now()
+
+				// Use the metrics collector to measure the operation
+				err := metricsCollector.MeasureOperation(
+					metrics.UpdateOperation,
+					1,
+					request.DataSizeBytes,
+					isColdStart && request.IsColdStart,
+					func() error {
+						return db.UpdateTransaction(ctx, tx, writeOptions)
+					},
+				)
+
+				results <- Result{
+					TransactionID: job.TransactionID,
+					Duration:      time.Since(updateStart),
+					Error:         err,
+				}
+			}
+		}()
+	}
+
+	// Send updates to workers
+	for _, job := range jobs {
+		jobChan <- job
+	}
+	close(jobChan)
+
+	// Wait for all workers to finish
+	wg.Wait()
+	close(results)
+
+	// Process results
+	var durations []time.Duration
+
+	for result := range results {
+		if result.Error != nil {
+			errMsg := fmt.Sprintf("Error updating transaction %s: %v", result.TransactionID, result.Error)
+			response.Errors = append(response.Errors, errMsg)
+		} else {
+			response.TransactionsUpdated++
+		}
+		durations = append(durations, result.Duration)
+	}
+	if len(durations) < len(jobs) {
+		response.StoppedEarly = fmt.Sprintf("%v after %d of %d transactions", ctx.Err(), len(durations), len(jobs))
+	}
+
+	// Calculate total and average durations
+	var totalDuration time.Duration
+	for _, d := range durations {
+		totalDuration += d
+	}
+
+	response.TotalDuration = totalDuration.Nanoseconds()
+	if len(durations) > 0 {
+		response.AvgDuration = totalDuration.Nanoseconds() / int64(len(durations))
+	}
+
+	// Include transaction IDs in response if they were generated
+	if len(request.TransactionIDs) == 0 {
+		response.TransactionIDs = transactionIDs
+	}
+
+	// Include metrics in response if requested
+	testResult := metricsCollector.EndTest(testName)
+	if request.CollectMetrics && testResult != nil {
+		response.Metrics = testResult.Summary
+	}
+
+	// Reset cold start flag after first invocation
+	isColdStart = false
+
+	// Log total execution time
+	elapsed := time.Since(functionStart)
+	fmt.Printf("Total execution time: %v\n", elapsed)
+
+	return response, nil
+}
+
+// closeDatabase releases the package-level connection when the container shuts down
+func closeDatabase() {
+	if err := db.Close(); err != nil {
+		fmt.Printf("Error closing database: %v\n", err)
+	}
+}
+
+func main() {
+	openDatabase()
+	lambda.StartWithOptions(handleRequest, lambda.WithEnableSIGTERM(closeDatabase))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+func TestCloseDatabase(t *testing.T) {
+	fake := dbtest.New()
+	db = fake
+
+	// The Lambda runtime runs closeDatabase when SIGTERM signals the container shutdown
+	closeDatabase()
+
+	if fake.Closed() != 1 {
+		t.Errorf("database closed %d times on shutdown, want 1", fake.Closed())
+	}
+}
+
+func TestHandleRequest(t *testing.T) {
+	fake := dbtest.New()
+	for i := 0; i < 5; i++ {
+		fake.Put(&databases.Transaction{AccountID: "account-1", UUID: fmt.Sprintf("txn-%07d", i), Amount: 1, Timestamp: time.Now()})
+	}
+	db = fake
+
+	tests := []struct {
+		name    string
+		request Request
+		want    map[string]float64
+	}{
+		{
+			name:    "single amount",
+			request: Request{TransactionCount: 5, Amounts: []float64{50}},
+			want:    map[string]float64{"txn-0000000": 50, "txn-0000004": 50},
+		},
+		{
+			name:    "amount per transaction",
+			request: Request{TransactionIDs: []string{"txn-0000001", "txn-0000002"}, Amounts: []float64{10, 20}, Concurrency: 1},
+			want:    map[string]float64{"txn-0000001": 10, "txn-0000002": 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.AccountID = "account-1"
+			tt.request.CollectMetrics = true
+			response, err := handleRequest(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("handleRequest() error = %v", err)
+			}
+			if len(response.Errors) != 0 {
+				t.Fatalf("Errors = %v", response.Errors)
+			}
+			if response.Metrics == nil {
+				t.Error("Metrics = nil, want the collected summary")
+			}
+
+			// Read back to confirm the new amounts were stored
+			for uuid, amount := range tt.want {
+				got, err := fake.ReadTransaction(context.Background(), "account-1", uuid, nil)
+				if err != nil {
+					t.Fatalf("ReadTransaction(%s) error = %v", uuid, err)
+				}
+				if got.Amount != amount {
+					t.Errorf("%s amount = %v, want %v", uuid, got.Amount, amount)
+				}
+			}
+		})
+	}
+
+	// Updating a missing transaction reports an error instead of creating it
+	response, err := handleRequest(context.Background(), Request{
+		AccountID:      "account-1",
+		TransactionIDs: []string{"txn-0000000", "txn-missing"},
+		Amounts:        []float64{75},
+	})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+	if response.TransactionsUpdated != 1 || len(response.Errors) != 1 || !strings.Contains(response.Errors[0], "txn-missing") {
+		t.Errorf("updated %d with Errors = %v, want 1 update and an error for txn-missing", response.TransactionsUpdated, response.Errors)
+	}
+	if fake.Len() != 5 {
+		t.Errorf("%d transactions stored, want the 5 seeded", fake.Len())
+	}
+}
+
+func TestHandleRequestAmountMismatch(t *testing.T) {
+	fake := dbtest.New()
+	db = fake
+
+	response, err := handleRequest(context.Background(), Request{
+		AccountID:        "account-1",
+		TransactionCount: 3,
+		Amounts:          []float64{1, 2},
+	})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+	if len(response.Errors) != 1 || fake.Calls("UpdateTransaction") != 0 {
+		t.Errorf("Errors = %v after %d updates, want one error and no updates", response.Errors, fake.Calls("UpdateTransaction"))
+	}
+}
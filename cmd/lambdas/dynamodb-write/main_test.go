@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+)
+
+func TestHandleRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		batchSize  int
+		wantMethod string
+		wantCalls  int
+	}{
+		{"single writes", 0, "WriteTransaction", 20},
+		{"batch writes", 10, "BatchWriteTransactions", 2},
+		{"batch size above the DynamoDB limit", 100, "BatchWriteTransactions", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dbtest.New()
+			dbtest.Register("lambda-test", db)
+			databaseType = "lambda-test"
+
+			response, err := handleRequest(context.Background(), Request{
+				AccountID:        "account-1",
+				TransactionCount: 20,
+				CollectMetrics:   true,
+				BatchSize:        tt.batchSize,
+			})
+			if err != nil {
+				t.Fatalf("handleRequest() error = %v", err)
+			}
+
+			if len(response.Errors) != 0 {
+				t.Fatalf("Errors = %v", response.Errors)
+			}
+			if response.TransactionsWritten != 20 || db.Len() != 20 {
+				t.Errorf("TransactionsWritten = %d with %d stored, want 20", response.TransactionsWritten, db.Len())
+			}
+			if len(response.TransactionIDs) != 20 {
+				t.Errorf("TransactionIDs has %d IDs, want 20", len(response.TransactionIDs))
+			}
+			if response.TotalDuration <= 0 || response.Metrics == nil {
+				t.Errorf("TotalDuration = %d, Metrics = %v, want both set", response.TotalDuration, response.Metrics)
+			}
+
+			// The handler opens a database per request and closes it before returning,
+			// so nothing is left open when the container shuts down
+			if db.Initialized() != 1 || db.Closed() != 1 {
+				t.Errorf("database initialized %d and closed %d times, want 1 and 1", db.Initialized(), db.Closed())
+			}
+
+			// The batch size selects the write or write-batch strategy
+			if got := db.Calls(tt.wantMethod); got != tt.wantCalls {
+				t.Errorf("%s called %d times, want %d", tt.wantMethod, got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/immudb"
+)
+
+// TestHandleRequestImmuDB writes through the handler to the ImmuDB server at
+// IMMUDB_ADDRESS (default: 127.0.0.1:3322) and reads the transactions back. The test
+// is skipped when no server is reachable.
+func TestHandleRequestImmuDB(t *testing.T) {
+	address := os.Getenv("IMMUDB_ADDRESS")
+	if address == "" {
+		address = "127.0.0.1"
+	}
+
+	var err error
+	db, err = immudb.NewImmuDBFactory().CreateDatabase(map[string]interface{}{"address": address})
+	if err != nil {
+		t.Fatalf("CreateDatabase() error = %v", err)
+	}
+	ctx := context.Background()
+	if err := db.Initialize(ctx); err != nil {
+		t.Skipf("ImmuDB is not reachable at %s: %v", address, err)
+	}
+	t.Cleanup(closeDatabase)
+
+	accountID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	for _, batchSize := range []int{1, 5} {
+		response, err := handleRequest(ctx, Request{
+			AccountID:        accountID,
+			TransactionCount: 10,
+			BatchSize:        batchSize,
+			UseRandomIDs:     true,
+			CollectMetrics:   true,
+		})
+		if err != nil {
+			t.Fatalf("handleRequest() error = %v", err)
+		}
+		if len(response.Errors) != 0 || response.TransactionsWritten != 10 {
+			t.Fatalf("batch size %d: wrote %d with Errors = %v, want 10", batchSize, response.TransactionsWritten, response.Errors)
+		}
+		if response.Metrics == nil {
+			t.Errorf("batch size %d: Metrics = nil, want the collected summary", batchSize)
+		}
+
+		for _, id := range response.TransactionIDs {
+			if _, err := db.ReadTransaction(ctx, accountID, id, nil); err != nil {
+				t.Errorf("ReadTransaction(%s) error = %v", id, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+)
+
+func TestCloseDatabase(t *testing.T) {
+	fake := dbtest.New()
+	db = fake
+
+	// The Lambda runtime runs closeDatabase when SIGTERM signals the container shutdown
+	closeDatabase()
+
+	if fake.Closed() != 1 {
+		t.Errorf("database closed %d times on shutdown, want 1", fake.Closed())
+	}
+}
+
+func TestGenerateTransactionData(t *testing.T) {
+	// The payload tops the transaction up to the requested size after an estimated
+	// base of the two IDs plus 50 bytes; "account-1" and "txn-0000001" estimate to 70
+	tests := []struct {
+		dataSize    int64
+		wantPayload int // -1 when no payload is added
+	}{
+		{0, -1},
+		{50, -1},
+		{70, -1},
+		{100, 30},
+		{1024, 954},
+	}
+
+	for _, tt := range tests {
+		tx := generateTransactionData("account-1", "txn-0000001", tt.dataSize)
+		if tx.AccountID != "account-1" || tx.UUID != "txn-0000001" {
+			t.Fatalf("generateTransactionData() keys = %s/%s", tx.AccountID, tx.UUID)
+		}
+
+		payload, ok := tx.Metadata.(map[string]interface{})["payload"].([]byte)
+		switch {
+		case tt.wantPayload < 0 && ok:
+			t.Errorf("dataSize %d: payload of %d bytes, want none", tt.dataSize, len(payload))
+		case tt.wantPayload >= 0 && len(payload) != tt.wantPayload:
+			t.Errorf("dataSize %d: payload of %d bytes, want %d", tt.dataSize, len(payload), tt.wantPayload)
+		}
+	}
+}
+
+func TestHandleRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		batchSize      int
+		collectMetrics bool
+		wantWrites     int
+		wantBatches    int
+	}{
+		{"single writes", 0, true, 25, 0},
+		{"batches", 10, true, 0, 3},
+		{"without metrics", 0, false, 25, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := dbtest.New()
+			db = fake
+
+			response, err := handleRequest(context.Background(), Request{
+				AccountID:        "account-1",
+				TransactionCount: 25,
+				BatchSize:        tt.batchSize,
+				DataSizeBytes:    256,
+				CollectMetrics:   tt.collectMetrics,
+			})
+			if err != nil {
+				t.Fatalf("handleRequest() error = %v", err)
+			}
+
+			if len(response.Errors) != 0 {
+				t.Fatalf("Errors = %v", response.Errors)
+			}
+			if response.TransactionsWritten != 25 || fake.Len() != 25 {
+				t.Errorf("wrote %d with %d stored, want 25", response.TransactionsWritten, fake.Len())
+			}
+			if len(response.TransactionIDs) != 25 {
+				t.Errorf("TransactionIDs = %v, want 25", response.TransactionIDs)
+			}
+			if got := fake.Calls("WriteTransaction"); got != tt.wantWrites {
+				t.Errorf("WriteTransaction calls = %d, want %d", got, tt.wantWrites)
+			}
+			if got := fake.Calls("BatchWriteTransactions"); got != tt.wantBatches {
+				t.Errorf("BatchWriteTransactions calls = %d, want %d", got, tt.wantBatches)
+			}
+			if (response.Metrics != nil) != tt.collectMetrics {
+				t.Errorf("Metrics = %v, want them only when requested", response.Metrics)
+			}
+		})
+	}
+}
+
+func TestHandleRequestDeadline(t *testing.T) {
+	fake := dbtest.New()
+	db = fake
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	response, err := handleRequest(ctx, Request{AccountID: "account-1", TransactionCount: 10})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+	if fake.Len() != 0 || response.StoppedEarly == "" {
+		t.Errorf("%d stored, StoppedEarly = %q, want no writes and the stop reported", fake.Len(), response.StoppedEarly)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/adapters"
+)
+
+// Request represents the input for the query benchmark Lambda function
+type Request struct {
+	Database         string                 `json:"database"`  // dynamodb, immudb, timestream, redis
+	QueryType        string                 `json:"queryType"` // account, time-range
+	AccountID        string                 `json:"accountId"`
+	StartTime        time.Time              `json:"startTime"`
+	EndTime          time.Time              `json:"endTime"`
+	Limit            int64                  `json:"limit"`
+	ScanIndexForward bool                   `json:"scanIndexForward"`
+	ConsistentRead   bool                   `json:"consistentRead"`
+	Iterations       int                    `json:"iterations"`
+	CollectMetrics   bool                   `json:"collectMetrics"`
+	IsColdStart      bool                   `json:"isColdStart"`
+	Parameters       map[string]interface{} `json:"parameters"` // "db."-prefixed adapter overrides
+}
+
+// Response represents the output from the query benchmark Lambda function
+type Response struct {
+	Database      string                 `json:"database"`
+	QueryType     string                 `json:"queryType"`
+	ItemsReturned int                    `json:"itemsReturned"`
+	TotalDuration int64                  `json:"totalDurationNs"`
+	AvgDuration   int64                  `json:"avgDurationNs"`
+	Throughput    float64                `json:"throughput"` // items per second
+	Metrics       map[string]interface{} `json:"metrics,omitempty"`
+	Errors        []string               `json:"errors,omitempty"`
+	StoppedEarly  string                 `json:"stoppedEarly,omitempty"`
+}
+
+var (
+	metricsCollector *metrics.Collector
+	isColdStart      = true
+)
+
+func init() {
+	// Initialize metrics collector
+	metricsCollector = metrics.NewCollector()
+}
+
+// runQuery executes a single query of the requested type
+func runQuery(ctx context.Context, db databases.Database, request Request, options *databases.QueryOptions) ([]*databases.Transaction, error) {
+	switch strings.ToLower(request.QueryType) {
+	case "", "account":
+		return db.QueryTransactionsByAccount(ctx, request.AccountID, options)
+	case "time-range":
+		return db.QueryTransactionsByTimeRange(ctx, request.AccountID, request.StartTime, request.EndTime, options)
+	default:
+		return nil, fmt.Errorf("unsupported query type: %s", request.QueryType)
+	}
+}
+
+func handleRequest(ctx context.Context, request Request) (Response, error) {
+	startTime := time.Now()
+	response := Response{
+		Database:  request.Database,
+		QueryType: request.QueryType,
+		Errors:    []string{},
+	}
+
+	if request.AccountID == "" {
+		response.Errors = append(response.Errors, "accountId is required")
+		return response, nil
+	}
+
+	// Default to the last 24 hours for time-range queries
+	if request.EndTime.IsZero() {
+		request.EndTime = time.Now()
+	}
+	if request.StartTime.IsZero() {
+		request.StartTime = request.EndTime.Add(-24 * time.Hour)
+	}
+
+	iterations := request.Iterations
+	if iterations <= 0 {
+		iterations = 1
+	}
+
+	db, err := adapters.Create(ctx, request.Database, request.Parameters)
+	if err != nil {
+		response.Errors = append(response.Errors, fmt.Sprintf("Failed to create database adapter: %v", err))
+		return response, nil
+	}
+	defer db.Close()
+
+	// Start metrics collection. The collector only measures operations while a test
+	// is running, so the test is started even when the metrics are not returned
+	testName := fmt.Sprintf("%s-query-%s", request.Database, time.Now().Format(time.RFC3339))
+	metricsCollector.StartTest(
+		testName,
+		fmt.Sprintf("%s queries on %s", request.QueryType, request.Database),
+		request.Database,
+		map[string]interface{}{"region": os.Getenv("AWS_REGION")},
+		map[string]interface{}{
+			"queryType":  request.QueryType,
+			"limit":      request.Limit,
+			"iterations": iterations,
+		},
+	)
+
+	options := &databases.QueryOptions{
+		ScanIndexForward: request.ScanIndexForward,
+		Limit:            request.Limit,
+		ConsistentRead:   request.ConsistentRead,
+	}
+
+	var totalDuration time.Duration
+	for i := 0; i < iterations; i++ {
+		// Stop once the Lambda deadline has passed
+		if ctx.Err() != nil {
+			response.StoppedEarly = fmt.Sprintf("%v after %d of %d queries", ctx.Err(), i, iterations)
+			iterations = i
+			break
+		}
+
+		var txs []*databases.Transaction
+		queryStart := time.Now()
+		err := metricsCollector.MeasureOperation(
+			metrics.QueryOperation,
+			1,
+			0,
+			isColdStart && request.IsColdStart && i == 0,
+			func() error {
+				var queryErr error
+				txs, queryErr = runQuery(ctx, db, request, options)
+				return queryErr
+			},
+		)
+		totalDuration += time.Since(queryStart)
+
+		if err != nil {
+			response.Errors = append(response.Errors, fmt.Sprintf("Query %d failed: %v", i, err))
+			continue
+		}
+		response.ItemsReturned += len(txs)
+	}
+
+	response.TotalDuration = totalDuration.Nanoseconds()
+	if iterations > 0 {
+		response.AvgDuration = totalDuration.Nanoseconds() / int64(iterations)
+	}
+	if totalDuration > 0 {
+		response.Throughput = float64(response.ItemsReturned) / totalDuration.Seconds()
+	}
+
+	// Include metrics in response if requested
+	testResult := metricsCollector.EndTest(testName)
+	if request.CollectMetrics && testResult != nil {
+		response.Metrics = testResult.Summary
+		response.Metrics["database"] = db.GetMetrics()
+	}
+
+	// Reset cold start flag after first invocation
+	isColdStart = false
+
+	// Calculate elapsed time
+	elapsed := time.Since(startTime)
+	fmt.Printf("Total execution time: %v\n", elapsed)
+
+	return response, nil
+}
+
+func main() {
+	lambda.Start(handleRequest)
+}
@@ -0,0 +1,112 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/adapters"
+)
+
+// envOr returns the environment variable or fallback when it is unset
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// TestHandleRequestLocal runs both query types through the handler against DynamoDB
+// Local at DYNAMODB_ENDPOINT (default: http://localhost:8000) and the LocalStack
+// Timestream endpoint at TIMESTREAM_ENDPOINT (default: http://localhost:4566). Each
+// database is skipped when its endpoint is not reachable.
+func TestHandleRequestLocal(t *testing.T) {
+	// The local endpoints accept any credentials, but the SDK still needs some to sign with
+	if os.Getenv("AWS_ACCESS_KEY_ID") == "" {
+		t.Setenv("AWS_ACCESS_KEY_ID", "local")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "local")
+	}
+	suffix := time.Now().UnixNano()
+
+	tests := []struct {
+		database   string
+		parameters map[string]interface{}
+	}{
+		{"dynamodb", map[string]interface{}{
+			"db.region":      "us-east-1",
+			"db.endpoint":    envOr("DYNAMODB_ENDPOINT", "http://localhost:8000"),
+			"db.tableName":   fmt.Sprintf("query-test-%d", suffix),
+			"db.createTable": true,
+			"db.billingMode": "payPerRequest",
+		}},
+		{"timestream", map[string]interface{}{
+			"db.region":       "us-east-1",
+			"db.endpoint":     envOr("TIMESTREAM_ENDPOINT", "http://localhost:4566"),
+			"db.databaseName": "QueryTestDB",
+			"db.tableName":    fmt.Sprintf("query-test-%d", suffix),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.database, func(t *testing.T) {
+			ctx := context.Background()
+			db, err := adapters.Create(ctx, tt.database, tt.parameters)
+			if err != nil {
+				t.Skipf("%s is not reachable: %v", tt.database, err)
+			}
+			defer db.Close()
+
+			// Five transactions in the last hour, and one outside the queried range
+			now := time.Now()
+			for i := 0; i < 6; i++ {
+				timestamp := now.Add(-time.Duration(i+1) * time.Minute)
+				if i == 5 {
+					timestamp = now.Add(-2 * time.Hour)
+				}
+				transaction := &databases.Transaction{
+					AccountID:       "account-1",
+					UUID:            fmt.Sprintf("tx-%d", i),
+					Timestamp:       timestamp,
+					Amount:          float64(i),
+					TransactionType: databases.Deposit,
+				}
+				if err := db.WriteTransaction(ctx, transaction, nil); err != nil {
+					t.Fatalf("WriteTransaction() error = %v", err)
+				}
+			}
+
+			queries := []struct {
+				request   Request
+				wantItems int
+			}{
+				{Request{QueryType: "account"}, 6},
+				{Request{QueryType: "time-range", StartTime: now.Add(-time.Hour), EndTime: now}, 5},
+			}
+			for _, query := range queries {
+				query.request.Database = tt.database
+				query.request.AccountID = "account-1"
+				query.request.Parameters = tt.parameters
+				query.request.CollectMetrics = true
+
+				response, err := handleRequest(ctx, query.request)
+				if err != nil {
+					t.Fatalf("handleRequest() error = %v", err)
+				}
+				if len(response.Errors) != 0 {
+					t.Fatalf("%s query Errors = %v", query.request.QueryType, response.Errors)
+				}
+				if response.ItemsReturned != query.wantItems {
+					t.Errorf("%s query returned %d items, want %d", query.request.QueryType, response.ItemsReturned, query.wantItems)
+				}
+				if response.Metrics["operationCount"] != int64(1) {
+					t.Errorf("%s query Metrics = %v, want one measured query", query.request.QueryType, response.Metrics)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// newTestDB registers an in-memory database holding ten transactions for account-1
+// written 0, 5, ... 45 hours ago, and one for another account
+func newTestDB(t *testing.T) *dbtest.DB {
+	t.Helper()
+	db := dbtest.New()
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		db.Put(&databases.Transaction{
+			AccountID: "account-1",
+			UUID:      fmt.Sprintf("tx-%d", i),
+			Timestamp: now.Add(-time.Duration(i) * 5 * time.Hour),
+		})
+	}
+	db.Put(&databases.Transaction{AccountID: "account-2", UUID: "tx-0", Timestamp: now})
+	dbtest.Register("query-test", db)
+	return db
+}
+
+func TestHandleRequest(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name          string
+		request       Request
+		wantItems     int
+		wantQueries   int
+		wantTimeRange bool
+	}{
+		{"account", Request{QueryType: "account"}, 10, 1, false},
+		{"default query type", Request{}, 10, 1, false},
+		{"account with limit", Request{QueryType: "account", Limit: 4}, 4, 1, false},
+		{"iterations", Request{QueryType: "account", Iterations: 3}, 30, 3, false},
+		// Time-range queries default to the last 24 hours
+		{"time-range default window", Request{QueryType: "time-range"}, 5, 1, true},
+		{"time-range", Request{QueryType: "time-range", StartTime: now.Add(-12 * time.Hour), EndTime: now.Add(time.Hour)}, 3, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			tt.request.Database = "query-test"
+			tt.request.AccountID = "account-1"
+			tt.request.CollectMetrics = true
+
+			response, err := handleRequest(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("handleRequest() error = %v", err)
+			}
+			if len(response.Errors) != 0 {
+				t.Fatalf("Errors = %v", response.Errors)
+			}
+
+			if response.ItemsReturned != tt.wantItems {
+				t.Errorf("ItemsReturned = %d, want %d", response.ItemsReturned, tt.wantItems)
+			}
+			method := "QueryTransactionsByAccount"
+			if tt.wantTimeRange {
+				method = "QueryTransactionsByTimeRange"
+			}
+			if got := db.Calls(method); got != tt.wantQueries {
+				t.Errorf("%s calls = %d, want %d", method, got, tt.wantQueries)
+			}
+			if response.TotalDuration <= 0 || response.Throughput <= 0 {
+				t.Errorf("TotalDuration = %d, Throughput = %v, want both positive", response.TotalDuration, response.Throughput)
+			}
+			if response.Metrics["operationCount"] != int64(tt.wantQueries) || response.Metrics["database"] == nil {
+				t.Errorf("Metrics = %v, want %d queries and the database metrics", response.Metrics, tt.wantQueries)
+			}
+			if db.Initialized() != 1 || db.Closed() != 1 {
+				t.Errorf("database initialized %d and closed %d times, want 1 and 1", db.Initialized(), db.Closed())
+			}
+		})
+	}
+}
+
+func TestHandleRequestErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   Request
+		wantError string
+	}{
+		{"missing account", Request{Database: "query-test"}, "accountId is required"},
+		{"unknown database", Request{Database: "unknown", AccountID: "account-1"}, "Failed to create database adapter"},
+		{"unsupported query type", Request{Database: "query-test", AccountID: "account-1", QueryType: "scan"}, "unsupported query type: scan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			response, err := handleRequest(context.Background(), tt.request)
+			if err != nil {
+				t.Fatalf("handleRequest() error = %v", err)
+			}
+			if len(response.Errors) != 1 || !strings.Contains(response.Errors[0], tt.wantError) {
+				t.Errorf("Errors = %v, want one containing %q", response.Errors, tt.wantError)
+			}
+			if response.ItemsReturned != 0 {
+				t.Errorf("ItemsReturned = %d, want 0", response.ItemsReturned)
+			}
+		})
+	}
+}
+
+func TestHandleRequestWithoutMetrics(t *testing.T) {
+	newTestDB(t)
+
+	// Queries are still measured when the caller does not ask for the metrics back
+	response, err := handleRequest(context.Background(), Request{Database: "query-test", AccountID: "account-1"})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+	if len(response.Errors) != 0 || response.ItemsReturned != 10 {
+		t.Errorf("returned %d with Errors = %v, want 10 and none", response.ItemsReturned, response.Errors)
+	}
+	if response.Metrics != nil {
+		t.Errorf("Metrics = %v, want nil when not requested", response.Metrics)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/timestream"
+)
+
+// TestHandleRequestTimestream writes through the handler to the LocalStack Timestream
+// endpoint at TIMESTREAM_ENDPOINT (default: http://localhost:4566) and counts the
+// records back. The test is skipped when no endpoint is reachable.
+func TestHandleRequestTimestream(t *testing.T) {
+	endpoint := os.Getenv("TIMESTREAM_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://localhost:4566"
+	}
+	// LocalStack accepts any credentials, but the SDK still needs some to sign with
+	if os.Getenv("AWS_ACCESS_KEY_ID") == "" {
+		t.Setenv("AWS_ACCESS_KEY_ID", "test")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	}
+
+	var err error
+	db, err = timestream.NewTimestreamFactory().CreateDatabase(map[string]interface{}{
+		"endpoint":     endpoint,
+		"databaseName": "LambdaTestDB",
+		"tableName":    fmt.Sprintf("test-%d", time.Now().UnixNano()),
+	})
+	if err != nil {
+		t.Skipf("Timestream is not reachable at %s: %v", endpoint, err)
+	}
+	ctx := context.Background()
+	if err := db.Initialize(ctx); err != nil {
+		t.Skipf("Timestream is not reachable at %s: %v", endpoint, err)
+	}
+	t.Cleanup(closeDatabase)
+
+	response, err := handleRequest(ctx, Request{
+		AccountID:        "account-1",
+		TransactionCount: 150,
+		DataSizeBytes:    256,
+		CollectMetrics:   true,
+	})
+	if err != nil {
+		t.Fatalf("handleRequest() error = %v", err)
+	}
+	if len(response.Errors) != 0 || response.TransactionsWritten != 150 {
+		t.Fatalf("wrote %d with Errors = %v, want 150", response.TransactionsWritten, response.Errors)
+	}
+	if response.Metrics["operationCount"] != int64(2) || response.Metrics["totalItems"] != int64(150) {
+		t.Errorf("Metrics = %v, want 2 batch operations covering 150 items", response.Metrics)
+	}
+
+	count, err := db.CountTransactionsByAccount(ctx, "account-1")
+	if err != nil {
+		t.Fatalf("CountTransactionsByAccount() error = %v", err)
+	}
+	if count != 150 {
+		t.Errorf("CountTransactionsByAccount() = %d, want 150", count)
+	}
+	if got := db.GetMetrics()["totalDataPoints"]; got != 150 {
+		t.Errorf("totalDataPoints = %v, want 150", got)
+	}
+}
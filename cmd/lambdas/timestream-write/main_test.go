@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+)
+
+func TestCloseDatabase(t *testing.T) {
+	fake := dbtest.New()
+	db = fake
+
+	// The Lambda runtime runs closeDatabase when SIGTERM signals the container shutdown
+	closeDatabase()
+
+	if fake.Closed() != 1 {
+		t.Errorf("database closed %d times on shutdown, want 1", fake.Closed())
+	}
+}
+
+func TestHandleRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		batchSize      int
+		collectMetrics bool
+		wantWrites     int
+		wantBatches    int
+	}{
+		// Batches default to and are capped at the 100-record WriteRecords limit
+		{"default batch size", 0, true, 0, 3},
+		{"batch size over the limit", 500, true, 0, 3},
+		{"smaller batches", 50, true, 0, 5},
+		{"single writes", 1, true, 250, 0},
+		{"without metrics", 0, false, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := dbtest.New()
+			db = fake
+
+			response, err := handleRequest(context.Background(), Request{
+				AccountID:        "account-1",
+				TransactionCount: 250,
+				BatchSize:        tt.batchSize,
+				CollectMetrics:   tt.collectMetrics,
+			})
+			if err != nil {
+				t.Fatalf("handleRequest() error = %v", err)
+			}
+
+			if len(response.Errors) != 0 {
+				t.Fatalf("Errors = %v", response.Errors)
+			}
+			if response.TransactionsWritten != 250 || fake.Len() != 250 {
+				t.Errorf("wrote %d with %d stored, want 250", response.TransactionsWritten, fake.Len())
+			}
+			if got := fake.Calls("WriteTransaction"); got != tt.wantWrites {
+				t.Errorf("WriteTransaction calls = %d, want %d", got, tt.wantWrites)
+			}
+			if got := fake.Calls("BatchWriteTransactions"); got != tt.wantBatches {
+				t.Errorf("BatchWriteTransactions calls = %d, want %d", got, tt.wantBatches)
+			}
+			if (response.Metrics != nil) != tt.collectMetrics {
+				t.Errorf("Metrics = %v, want them only when requested", response.Metrics)
+			}
+			if tt.collectMetrics && response.Metrics["totalItems"] != int64(250) {
+				t.Errorf("totalItems = %v, want 250", response.Metrics["totalItems"])
+			}
+		})
+	}
+}
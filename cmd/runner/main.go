@@ -2,17 +2,33 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	benchops "github.com/pedro-hbl/lambda-gopher-benchmark/cmd/benchmark/operations"
+	"gopkg.in/yaml.v3"
 )
 
 // BenchmarkConfig holds the configuration for a benchmark run
@@ -34,62 +50,205 @@ type BenchmarkResult struct {
 	Throughput             float64                `json:"throughput"`
 	Metrics                map[string]interface{} `json:"metrics,omitempty"`
 	Timestamp              time.Time              `json:"timestamp"`
+	Iterations             *IterationStats        `json:"iterations,omitempty"`
+}
+
+// IterationStats summarizes a benchmark invoked several times
+type IterationStats struct {
+	Count                  int         `json:"count"`
+	Successful             int         `json:"successful"`
+	Throughput             StatSummary `json:"throughput"`
+	AvgOperationDurationNs StatSummary `json:"avgOperationDurationNs"`
+}
+
+// StatSummary holds descriptive statistics for a metric across iterations
+type StatSummary struct {
+	Mean   float64 `json:"mean"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stdDev"` // Population standard deviation
+}
+
+// runSummary is the rollup written to run_summary.json at the end of a run
+type runSummary struct {
+	StartTime  time.Time         `json:"startTime"`
+	WallTimeNs int64             `json:"wallTimeNs"`
+	Total      int               `json:"total"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	Results    []runSummaryEntry `json:"results"`
+}
+
+// runManifest describes a run; it is written to manifest.json in the run directory
+// before any benchmark is invoked
+type runManifest struct {
+	RunID       string            `json:"runId"`
+	StartTime   time.Time         `json:"startTime"`
+	ConfigFile  string            `json:"configFile,omitempty"`
+	BenchmarkID string            `json:"benchmarkId,omitempty"`
+	Flags       map[string]string `json:"flags"`
+}
+
+// runSummaryEntry describes one saved result in the run summary
+type runSummaryEntry struct {
+	DatabaseType           string  `json:"databaseType"`
+	OperationType          string  `json:"operationType"`
+	File                   string  `json:"file,omitempty"`
+	Success                bool    `json:"success"`
+	ErrorMessage           string  `json:"errorMessage,omitempty"`
+	ItemsProcessed         int     `json:"itemsProcessed"`
+	Throughput             float64 `json:"throughput"`
+	AvgOperationDurationNs int64   `json:"avgOperationDurationNs"`
+	Iterations             int     `json:"iterations,omitempty"` // Set for aggregated results
 }
 
 // BenchmarkDefinition represents a benchmark configuration file
 type BenchmarkDefinition struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Tests       []struct {
-		ID          string `json:"id"`
-		Name        string `json:"name"`
-		Description string `json:"description"`
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	// Endpoints maps database types to their function URLs
+	Endpoints map[string]string `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	Tests     []struct {
+		ID          string `json:"id" yaml:"id"`
+		Name        string `json:"name" yaml:"name"`
+		Description string `json:"description" yaml:"description"`
 		Database    struct {
-			Type   string                 `json:"type"`
-			Config map[string]interface{} `json:"config"`
-		} `json:"database"`
+			Type   string                 `json:"type" yaml:"type"`
+			Config map[string]interface{} `json:"config" yaml:"config"`
+		} `json:"database" yaml:"database"`
 		Operation struct {
-			Type        string                 `json:"type"`
-			Count       int                    `json:"count"`
-			Data        map[string]interface{} `json:"data"`
-			BatchSize   int                    `json:"batchSize,omitempty"`
-			Concurrency int                    `json:"concurrency,omitempty"`
-		} `json:"operation"`
-	} `json:"tests"`
+			Type        string                 `json:"type" yaml:"type"`
+			Count       int                    `json:"count" yaml:"count"`
+			Data        map[string]interface{} `json:"data" yaml:"data"`
+			BatchSize   int                    `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+			Concurrency int                    `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+		} `json:"operation" yaml:"operation"`
+	} `json:"tests" yaml:"tests"`
 }
 
 // Command line flags
 var (
-	lambdaEndpoint = flag.String("lambda-endpoint", "", "Lambda function endpoint URL")
-	databases      = flag.String("database", "dynamodb", "Comma-separated list of databases to benchmark")
-	operations     = flag.String("operations", "read-sequential,read-parallel,write,write-batch,query", "Comma-separated list of operations to benchmark")
-	concurrency    = flag.Int("concurrency", 10, "Concurrency level for parallel operations")
-	itemCount      = flag.Int("items", 100, "Number of items to process")
-	dataSize       = flag.Int("data-size", 1024, "Size of data in bytes")
-	outputDir      = flag.String("output", "", "Directory to store result files")
-	runAll         = flag.Bool("all", false, "Run all databases and operations")
-	verbose        = flag.Bool("verbose", false, "Enable verbose output")
-	configFile     = flag.String("config", "", "Path to benchmark configuration file")
+	lambdaEndpoint    = flag.String("lambda-endpoint", "", "Lambda function endpoint URL")
+	databases         = flag.String("database", "dynamodb", "Comma-separated list of databases to benchmark")
+	operations        = flag.String("operations", "read-sequential,read-parallel,write,write-batch,query", "Comma-separated list of operations to benchmark (use seed first to pre-populate data)")
+	concurrency       = flag.Int("concurrency", 10, "Concurrency level for parallel operations")
+	itemCount         = flag.Int("items", 100, "Number of items to process")
+	dataSize          = flag.Int("data-size", 1024, "Size of data in bytes")
+	outputDir         = flag.String("output", "", "Directory to store result files")
+	runAll            = flag.Bool("all", false, "Run all databases and operations")
+	verbose           = flag.Bool("verbose", false, "Enable verbose output")
+	configFile        = flag.String("config", "", "Path to benchmark configuration file")
+	invokeMode        = flag.String("invoke-mode", "http", "How to invoke the benchmark function: http (Runtime Interface Emulator) or sdk (AWS Lambda Invoke API)")
+	functionName      = flag.String("function-name", "", "Lambda function name or ARN to invoke in sdk mode")
+	parallel          = flag.Int("parallel", 1, "Maximum number of benchmark invocations to run concurrently")
+	maxRetries        = flag.Int("max-retries", 3, "Retries for HTTP invocations that fail with a 5xx or network error")
+	retryBackoff      = flag.Duration("retry-backoff", 500*time.Millisecond, "Base delay for jittered exponential backoff between retries")
+	warmup            = flag.Int("warmup", 0, "Number of discarded warmup invocations per benchmark before the measured runs")
+	iterations        = flag.Int("iterations", 1, "Number of times to invoke each benchmark; results are aggregated when greater than 1")
+	requestTimeout    = flag.Duration("request-timeout", 5*time.Minute, "Maximum time to wait for a single benchmark invocation")
+	dryRun            = flag.Bool("dry-run", false, "Print the planned benchmark invocations as JSON and exit without invoking anything")
+	dataSizes         = flag.String("data-sizes", "", "Comma-separated data sizes in bytes; each benchmark runs once per size")
+	concurrencyLevels = flag.String("concurrency-levels", "", "Comma-separated concurrency levels; each concurrent operation runs once per level")
+	stream            = flag.String("stream", "", "Also append each result as one JSON line to this file, or - for stdout")
+	filterMetrics     = flag.String("filter-metrics", "", "Comma-separated adapter metrics to keep in each result's dbMetrics, e.g. throttledOperations,writeCapacityUnits")
+	runID             = flag.String("run-id", "", "Subdirectory of the output directory for this run's results; defaults to the start time")
+	compress          = flag.Bool("compress", false, "Gzip the request body and send it in a JSON envelope the benchmark function decompresses")
+)
+
+// httpClient is used for all HTTP invocations; its timeout is set from --request-timeout
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// benchmarkJob describes a single benchmark invocation
+type benchmarkJob struct {
+	dbType   string
+	opType   string
+	endpoint string
+	params   map[string]interface{}
+}
+
+// plannedInvocation is a benchmark invocation as printed by --dry-run
+type plannedInvocation struct {
+	Endpoint     string          `json:"endpoint,omitempty"`
+	FunctionName string          `json:"functionName,omitempty"`
+	Config       BenchmarkConfig `json:"config"`
+}
+
+// lambdaInvoker is the subset of the Lambda client used by the runner
+type lambdaInvoker interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+// lambdaClient is used for sdk invocations; it is created lazily on first use
+var (
+	lambdaClient     lambdaInvoker
+	lambdaClientErr  error
+	lambdaClientOnce sync.Once
+)
+
+// resultSeq keeps result filenames unique when invocations finish in the same second
+var resultSeq atomic.Int64
+
+// summaryEntries collects every saved result for run_summary.json
+var (
+	summaryEntries   []runSummaryEntry
+	summaryEntriesMu sync.Mutex
+)
+
+// resultStream receives one JSON line per result when --stream is set. Writes are
+// unbuffered, so nothing is lost when the runner exits early.
+var (
+	resultStream   io.Writer
+	resultStreamMu sync.Mutex
 )
 
 var availableDatabases = []string{
 	"dynamodb",
 	"immudb",
 	"timestream",
+	"redis",
+}
+
+// knownOperations lists the operation types accepted in benchmark configuration files,
+// which are the types the benchmark function's operation factory registers
+var knownOperations = benchops.NewOperationFactory().Types()
+
+// concurrentOperations lists the operation types whose throughput depends on the concurrency parameter
+var concurrentOperations = []string{
+	"read-parallel", "write-batch", "delete-parallel", "mixed", "seed", "transact-read",
 }
 
+// Limits used to reject implausible configuration values
+const (
+	maxBatchSize         = 1000
+	maxDynamoDBBatchSize = 25 // BatchWriteItem limit
+	maxConcurrency       = 1000
+)
+
 // Map of database types to their specific function URLs
 var functionURLs = make(map[string]string)
 
+// functionURLEnvVars maps database types to the environment variables holding their function URLs
+var functionURLEnvVars = map[string]string{
+	"dynamodb":   "DYNAMODB_FUNCTION_URL",
+	"immudb":     "IMMUDB_FUNCTION_URL",
+	"timestream": "TIMESTREAM_FUNCTION_URL",
+}
+
 func main() {
 	// Parse command line flags
 	flag.Parse()
+	httpClient.Timeout = *requestTimeout
 
 	// Set up logging
 	log.SetOutput(os.Stdout)
 	log.SetFlags(log.Ldate | log.Ltime)
 
+	// Keep stdout for the plan or result stream so it can be piped into other tools
+	if *dryRun || *stream == "-" {
+		log.SetOutput(os.Stderr)
+	}
+
 	// If config file is specified, use that
 	if *configFile != "" {
 		runBenchmarkFromConfigFile(*configFile)
@@ -101,59 +260,31 @@ func main() {
 		log.Fatal("Either --lambda-endpoint, --database flag, or --config file must be provided")
 	}
 
-	// Get Lambda endpoint from flag or environment variable
-	if *lambdaEndpoint == "" {
-		*lambdaEndpoint = os.Getenv("LAMBDA_ENDPOINT")
-		if *lambdaEndpoint == "" {
-			log.Fatalf("Lambda endpoint not specified. Use --lambda-endpoint flag or LAMBDA_ENDPOINT environment variable")
-		}
-	}
-
-	// Get output directory from flag or environment variable
-	if *outputDir == "" {
-		*outputDir = os.Getenv("RESULTS_DIR")
-		if *outputDir == "" {
-			*outputDir = "./results"
-		}
-	}
-
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(*outputDir, 0755); err != nil {
-		log.Fatalf("Failed to create output directory: %v", err)
-	}
-
-	// Initialize function URLs map for different database types
-	functionURLs = make(map[string]string)
-
-	// For DynamoDB benchmarks
-	dynamoDBFunctionURL := os.Getenv("DYNAMODB_FUNCTION_URL")
-	if dynamoDBFunctionURL != "" {
-		functionURLs["dynamodb"] = dynamoDBFunctionURL
-	}
-
-	// For ImmuDB benchmarks
-	immuDBFunctionURL := os.Getenv("IMMUDB_FUNCTION_URL")
-	if immuDBFunctionURL != "" {
-		functionURLs["immudb"] = immuDBFunctionURL
-	}
-
-	// For Timestream benchmarks
-	timestreamFunctionURL := os.Getenv("TIMESTREAM_FUNCTION_URL")
-	if timestreamFunctionURL != "" {
-		functionURLs["timestream"] = timestreamFunctionURL
-	}
+	// Resolve database-specific endpoints from the environment
+	loadFunctionURLs(nil)
 
 	// Parse database and operation lists
 	var dbList, opList []string
 	if *runAll {
 		dbList = []string{"dynamodb", "immudb", "timestream"}
-		opList = []string{"read", "read-parallel", "write", "batch-write", "query"}
+		// Seed first so read and query benchmarks find existing data
+		opList = []string{"seed", "read", "read-parallel", "write", "write-batch", "query"}
 	} else {
 		dbList = strings.Split(*databases, ",")
 		opList = strings.Split(*operations, ",")
 	}
 
-	// Run benchmarks
+	validateInvokeFlags(dbList)
+
+	// Get output directory from flag or environment variable
+	prepareRunDir("")
+
+	if !*dryRun {
+		openResultStream(*stream)
+	}
+
+	// Build the benchmark matrix
+	var jobs []benchmarkJob
 	for _, db := range dbList {
 		for _, op := range opList {
 			// Use database-specific endpoint if available
@@ -161,19 +292,216 @@ func main() {
 			if specificURL, ok := functionURLs[db]; ok && specificURL != "" {
 				endpoint = specificURL
 			}
-			runBenchmarkWithEndpoint(db, op, endpoint, nil)
+			jobs = append(jobs, benchmarkJob{dbType: db, opType: op, endpoint: endpoint})
 		}
 	}
 
+	jobs, err := applySweeps(jobs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dryRun {
+		if err := printPlan(os.Stdout, jobs); err != nil {
+			log.Fatalf("Dry run failed: %v", err)
+		}
+		return
+	}
+
+	// Run benchmarks
+	start := time.Now()
+	errs := runJobs(jobs, *parallel)
+	writeRunSummary(len(jobs), errs, start)
+	if len(errs) > 0 {
+		reportFailures(errs)
+		os.Exit(1)
+	}
+
 	log.Println("All benchmarks completed!")
 }
 
-// runBenchmarkWithEndpoint runs a single benchmark with a specific endpoint
-func runBenchmarkWithEndpoint(dbType, opType, endpoint string, customParams map[string]interface{}) {
-	log.Printf("Running benchmark: %s - %s using endpoint %s", dbType, opType, endpoint)
+// runJobs runs the benchmark jobs with up to parallelism concurrent invocations.
+// A failing job does not stop the others; all failures are returned.
+func runJobs(jobs []benchmarkJob, parallelism int) []error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	semaphore := make(chan struct{}, parallelism)
+
+	for _, job := range jobs {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(job benchmarkJob) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := runBenchmarkWithEndpoint(job.dbType, job.opType, job.endpoint, job.params); err != nil {
+				log.Printf("Benchmark %s - %s failed: %v", job.dbType, job.opType, err)
+
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s - %s: %w", job.dbType, job.opType, err))
+				mu.Unlock()
+			}
+		}(job)
+	}
+
+	wg.Wait()
+	return errs
+}
+
+// reportFailures logs a summary of failed benchmark invocations
+func reportFailures(errs []error) {
+	log.Printf("%d benchmark(s) failed:", len(errs))
+	for _, err := range errs {
+		log.Printf("  - %v", err)
+	}
+}
+
+// runBenchmarkWithEndpoint runs a benchmark with a specific endpoint for the configured
+// number of iterations. Each iteration is saved, and multiple iterations are also
+// saved as an aggregated result.
+func runBenchmarkWithEndpoint(dbType, opType, endpoint string, customParams map[string]interface{}) error {
+	count := *iterations
+	if count < 1 {
+		count = 1
+	}
+
+	// Warm up the function and its connections; these results are discarded
+	for i := 0; i < *warmup; i++ {
+		log.Printf("Warmup %d/%d: %s - %s", i+1, *warmup, dbType, opType)
+		if _, err := invokeBenchmark(dbType, opType, endpoint, customParams, false); err != nil {
+			log.Printf("Warmup %d/%d failed: %v", i+1, *warmup, err)
+		}
+	}
+
+	var (
+		results []*BenchmarkResult
+		failed  int
+		lastErr error
+	)
+	for i := 0; i < count; i++ {
+		if count > 1 {
+			log.Printf("Iteration %d/%d: %s - %s", i+1, count, dbType, opType)
+		}
+
+		result, err := invokeBenchmark(dbType, opType, endpoint, customParams, true)
+		if err != nil {
+			failed++
+			lastErr = err
+			continue
+		}
+		results = append(results, result)
+	}
+
+	if count > 1 {
+		aggregate := aggregateResults(dbType, opType, results, count)
+		tagSweep(aggregate, opType, customParams)
+		saveResult(dbType, resultName(opType, customParams)+"-aggregate", aggregate)
+		printSummary(aggregate)
+	}
+
+	if lastErr != nil {
+		if count > 1 {
+			return fmt.Errorf("%d of %d iterations failed, last error: %w", failed, count, lastErr)
+		}
+		return lastErr
+	}
+
+	return nil
+}
+
+// invokeBenchmark runs a single benchmark invocation and returns its result.
+// When record is false (warmup) the result is neither saved nor summarized.
+func invokeBenchmark(dbType, opType, endpoint string, customParams map[string]interface{}, record bool) (*BenchmarkResult, error) {
+	if *invokeMode == "sdk" {
+		log.Printf("Running benchmark: %s - %s using function %s", dbType, opType, *functionName)
+	} else {
+		log.Printf("Running benchmark: %s - %s using endpoint %s", dbType, opType, endpoint)
+	}
+
+	config := buildBenchmarkConfig(dbType, opType, customParams)
+
+	// Convert config to JSON
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config to JSON: %w", err)
+	}
 
+	if *verbose {
+		log.Printf("Request payload: %s", string(jsonData))
+	}
+
+	if *compress {
+		if jsonData, err = compressPayload(jsonData); err != nil {
+			return nil, fmt.Errorf("failed to compress request: %w", err)
+		}
+	}
+
+	// Invoke Lambda function
+	var body []byte
+	if *invokeMode == "sdk" {
+		ctx, cancel := context.WithTimeout(context.Background(), *requestTimeout)
+		body, err = invokeSDK(ctx, *functionName, jsonData)
+		cancel()
+	} else {
+		body, err = invokeHTTPWithRetry(endpoint, jsonData, *maxRetries, *retryBackoff)
+	}
+	if err != nil {
+		// Record the final failure so the run still has a result for this benchmark
+		failed := BenchmarkResult{
+			OperationType: opType,
+			DatabaseType:  dbType,
+			Success:       false,
+			ErrorMessage:  err.Error(),
+			Timestamp:     time.Now(),
+		}
+		tagSweep(&failed, opType, customParams)
+		if record {
+			saveResult(dbType, resultName(opType, customParams), &failed)
+			printSummary(&failed)
+		}
+		return nil, fmt.Errorf("failed to invoke Lambda function: %w", err)
+	}
+
+	if *verbose {
+		log.Printf("Response: %s", string(body))
+	}
+
+	// Parse result
+	var result BenchmarkResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+
+	// Add timestamp
+	result.Timestamp = time.Now()
+	tagSweep(&result, opType, customParams)
+
+	if !record {
+		return &result, nil
+	}
+
+	// Save result to file
+	saveResult(dbType, resultName(opType, customParams), &result)
+
+	// Print summary
+	printSummary(&result)
+
+	return &result, nil
+}
+
+// buildBenchmarkConfig creates the request for a benchmark from the command line
+// defaults, the custom parameters and the per-operation defaults
+func buildBenchmarkConfig(dbType, opType string, customParams map[string]interface{}) BenchmarkConfig {
 	// Configure the benchmark
-	config := BenchmarkConfig{
+	cfg := BenchmarkConfig{
 		DatabaseType:  dbType,
 		OperationType: opType,
 		Parameters: map[string]interface{}{
@@ -186,72 +514,446 @@ func runBenchmarkWithEndpoint(dbType, opType, endpoint string, customParams map[
 		},
 	}
 
+	if *filterMetrics != "" {
+		cfg.Parameters["metricKeys"] = strings.Split(*filterMetrics, ",")
+	}
+
 	// Override with custom parameters if provided
 	if customParams != nil {
 		for k, v := range customParams {
-			config.Parameters[k] = v
+			cfg.Parameters[k] = v
 		}
 	}
 
 	// Additional parameters based on operation type if not already set
 	switch opType {
-	case "batch-write":
-		if _, ok := config.Parameters["batchSize"]; !ok {
-			config.Parameters["batchSize"] = 25
+	case "seed", "write-batch":
+		if _, ok := cfg.Parameters["batchSize"]; !ok {
+			cfg.Parameters["batchSize"] = 25
 		}
-	case "query":
-		if _, ok := config.Parameters["limit"]; !ok {
-			config.Parameters["limit"] = int64(100)
+	case "query", "query-gsi", "aggregate":
+		if _, ok := cfg.Parameters["limit"]; !ok {
+			cfg.Parameters["limit"] = int64(100)
 		}
-		if _, ok := config.Parameters["startTime"]; !ok {
-			config.Parameters["startTime"] = time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+		if _, ok := cfg.Parameters["startTime"]; !ok {
+			cfg.Parameters["startTime"] = time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
 		}
-		if _, ok := config.Parameters["endTime"]; !ok {
-			config.Parameters["endTime"] = time.Now().Format(time.RFC3339)
+		if _, ok := cfg.Parameters["endTime"]; !ok {
+			cfg.Parameters["endTime"] = time.Now().Format(time.RFC3339)
 		}
 	}
 
-	// Convert config to JSON
-	jsonData, err := json.Marshal(config)
+	return cfg
+}
+
+// planJobs resolves the request and target of every job without invoking anything.
+// It fails if a job has no endpoint (http mode) or function name (sdk mode).
+func planJobs(jobs []benchmarkJob) ([]plannedInvocation, error) {
+	plan := make([]plannedInvocation, 0, len(jobs))
+	for _, job := range jobs {
+		planned := plannedInvocation{Config: buildBenchmarkConfig(job.dbType, job.opType, job.params)}
+
+		if *invokeMode == "sdk" {
+			if *functionName == "" {
+				return nil, fmt.Errorf("%s - %s: no function name", job.dbType, job.opType)
+			}
+			planned.FunctionName = *functionName
+		} else {
+			u, err := url.Parse(job.endpoint)
+			if err != nil || u.Scheme == "" || u.Host == "" {
+				return nil, fmt.Errorf("%s - %s: no valid endpoint resolved for %s: %q", job.dbType, job.opType, job.dbType, job.endpoint)
+			}
+			planned.Endpoint = job.endpoint
+		}
+
+		plan = append(plan, planned)
+	}
+	return plan, nil
+}
+
+// printPlan writes the planned invocations to w as indented JSON
+func printPlan(w io.Writer, jobs []benchmarkJob) error {
+	plan, err := planJobs(jobs)
 	if err != nil {
-		log.Fatalf("Failed to marshal config to JSON: %v", err)
+		return err
 	}
 
-	if *verbose {
-		log.Printf("Request payload: %s", string(jsonData))
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
 	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
 
-	// Invoke Lambda function
-	resp, err := http.Post(endpoint+"/2015-03-31/functions/function/invocations", "application/json", bytes.NewBuffer(jsonData))
+// parseIntList parses a comma-separated list of positive integers; an empty list yields nil
+func parseIntList(list string) ([]int, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+
+	var values []int
+	for _, field := range strings.Split(list, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("expected a positive integer, got %q", field)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// isConcurrentOperation reports whether opType's throughput depends on the concurrency parameter
+func isConcurrentOperation(opType string) bool {
+	return contains(concurrentOperations, opType)
+}
+
+// applySweeps expands jobs for --data-sizes and --concurrency-levels. Every job runs
+// once per data size; concurrent operations also run once per concurrency level.
+func applySweeps(jobs []benchmarkJob) ([]benchmarkJob, error) {
+	sizes, err := parseIntList(*dataSizes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --data-sizes: %w", err)
+	}
+	levels, err := parseIntList(*concurrencyLevels)
 	if err != nil {
-		log.Fatalf("Failed to invoke Lambda function: %v", err)
+		return nil, fmt.Errorf("invalid --concurrency-levels: %w", err)
+	}
+
+	jobs = expandSweep(jobs, "dataSize", sizes, func(string) bool { return true })
+	return expandSweep(jobs, "concurrency", levels, isConcurrentOperation), nil
+}
+
+// expandSweep replaces each job that applies with one copy per value of param
+func expandSweep(jobs []benchmarkJob, param string, values []int, applies func(opType string) bool) []benchmarkJob {
+	if len(values) == 0 {
+		return jobs
+	}
+
+	expanded := make([]benchmarkJob, 0, len(jobs)*len(values))
+	for _, job := range jobs {
+		if !applies(job.opType) {
+			expanded = append(expanded, job)
+			continue
+		}
+		for _, value := range values {
+			swept := job
+			swept.params = make(map[string]interface{}, len(job.params)+1)
+			for k, v := range job.params {
+				swept.params[k] = v
+			}
+			swept.params[param] = value
+			expanded = append(expanded, swept)
+		}
+	}
+	return expanded
+}
+
+// sweepValue returns the value of param set by a sweep flag for this invocation
+func sweepValue(opType, param string, customParams map[string]interface{}) (int, bool) {
+	switch param {
+	case "dataSize":
+		if *dataSizes == "" {
+			return 0, false
+		}
+	case "concurrency":
+		if *concurrencyLevels == "" || !isConcurrentOperation(opType) {
+			return 0, false
+		}
+	}
+	value, ok := customParams[param].(int)
+	return value, ok
+}
+
+// resultName is the operation part of a result filename, tagged with the swept values
+func resultName(opType string, customParams map[string]interface{}) string {
+	name := opType
+	if size, ok := sweepValue(opType, "dataSize", customParams); ok {
+		name += fmt.Sprintf("-%db", size)
+	}
+	if level, ok := sweepValue(opType, "concurrency", customParams); ok {
+		name += fmt.Sprintf("-c%d", level)
+	}
+	return name
+}
+
+// tagSweep records the swept values of an invocation in the result metrics
+func tagSweep(result *BenchmarkResult, opType string, customParams map[string]interface{}) {
+	for _, param := range []string{"dataSize", "concurrency"} {
+		value, ok := sweepValue(opType, param, customParams)
+		if !ok {
+			continue
+		}
+		if result.Metrics == nil {
+			result.Metrics = make(map[string]interface{})
+		}
+		result.Metrics[param] = value
+	}
+}
+
+// aggregateResults combines the successful iterations of a benchmark into one result.
+// Throughput and average latency are the means across iterations; the spread is
+// reported in the iterations block.
+func aggregateResults(dbType, opType string, results []*BenchmarkResult, count int) *BenchmarkResult {
+	aggregate := &BenchmarkResult{
+		OperationType: opType,
+		DatabaseType:  dbType,
+		Timestamp:     time.Now(),
+	}
+
+	var throughputs, latencies []float64
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		throughputs = append(throughputs, result.Throughput)
+		latencies = append(latencies, float64(result.AvgOperationDurationNs))
+		aggregate.ItemsProcessed += result.ItemsProcessed
+		aggregate.TotalDurationNs += result.TotalDurationNs
+	}
+
+	aggregate.Iterations = &IterationStats{
+		Count:                  count,
+		Successful:             len(throughputs),
+		Throughput:             summarize(throughputs),
+		AvgOperationDurationNs: summarize(latencies),
+	}
+
+	if len(throughputs) == 0 {
+		aggregate.ErrorMessage = fmt.Sprintf("all %d iterations failed", count)
+		return aggregate
+	}
+
+	aggregate.Success = true
+	aggregate.Throughput = aggregate.Iterations.Throughput.Mean
+	aggregate.AvgOperationDurationNs = int64(aggregate.Iterations.AvgOperationDurationNs.Mean)
+
+	return aggregate
+}
+
+// summarize computes the mean, min, max and population standard deviation of values
+func summarize(values []float64) StatSummary {
+	if len(values) == 0 {
+		return StatSummary{}
+	}
+
+	summary := StatSummary{Min: values[0], Max: values[0]}
+	var sum float64
+	for _, v := range values {
+		sum += v
+		if v < summary.Min {
+			summary.Min = v
+		}
+		if v > summary.Max {
+			summary.Max = v
+		}
+	}
+	summary.Mean = sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		diff := v - summary.Mean
+		variance += diff * diff
+	}
+	summary.StdDev = math.Sqrt(variance / float64(len(values)))
+
+	return summary
+}
+
+// loadFunctionURLs sets the database-specific function URLs from configured,
+// letting the per-database environment variables win when they are set
+func loadFunctionURLs(configured map[string]string) {
+	functionURLs = make(map[string]string)
+	for db, endpoint := range configured {
+		if endpoint != "" {
+			functionURLs[db] = endpoint
+		}
+	}
+	for db, envVar := range functionURLEnvVars {
+		if endpoint := os.Getenv(envVar); endpoint != "" {
+			functionURLs[db] = endpoint
+		}
+	}
+}
+
+// hasFunctionURLs reports whether every database in dbTypes has its own function URL
+func hasFunctionURLs(dbTypes []string) bool {
+	if len(dbTypes) == 0 {
+		return false
+	}
+	for _, db := range dbTypes {
+		if functionURLs[db] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// validateInvokeFlags checks that the flags required by the selected invoke mode are set.
+// In http mode the default endpoint is optional when every database in dbTypes has its own.
+func validateInvokeFlags(dbTypes []string) {
+	switch *invokeMode {
+	case "http":
+		// Get Lambda endpoint from flag or environment variable
+		if *lambdaEndpoint == "" {
+			*lambdaEndpoint = os.Getenv("LAMBDA_ENDPOINT")
+			if *lambdaEndpoint == "" && !hasFunctionURLs(dbTypes) {
+				log.Fatalf("Lambda endpoint not specified. Use --lambda-endpoint flag or LAMBDA_ENDPOINT environment variable")
+			}
+		}
+	case "sdk":
+		if *functionName == "" {
+			log.Fatalf("Function name not specified. Use --function-name flag with --invoke-mode=sdk")
+		}
+	default:
+		log.Fatalf("Unsupported invoke mode: %s (expected http or sdk)", *invokeMode)
+	}
+}
+
+// compressedRequest is the envelope of a gzipped request. Neither the Invoke API nor
+// the Runtime Interface Emulator passes HTTP headers such as Content-Encoding to the
+// function, so the encoding travels in the JSON body and the payload is base64-encoded.
+type compressedRequest struct {
+	ContentEncoding string `json:"contentEncoding"`
+	Payload         []byte `json:"payload"`
+}
+
+// compressPayload gzips a JSON request and wraps it in a compressedRequest
+func compressPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(compressedRequest{ContentEncoding: "gzip", Payload: buf.Bytes()})
+}
+
+// invokeHTTP posts the payload to the Lambda Runtime Interface Emulator endpoint
+func invokeHTTP(endpoint string, payload []byte) ([]byte, error) {
+	resp, err := httpClient.Post(endpoint+"/2015-03-31/functions/function/invocations", "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		if isTimeout(err) {
+			return nil, fmt.Errorf("request timed out after %v: %w", httpClient.Timeout, err)
+		}
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatalf("Failed to read response: %v", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if *verbose {
-		log.Printf("Response: %s", string(body))
+	if resp.StatusCode >= 400 {
+		return nil, &httpStatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	// Parse result
-	var result BenchmarkResult
-	if err := json.Unmarshal(body, &result); err != nil {
-		log.Fatalf("Failed to parse result: %v", err)
+	return body, nil
+}
+
+// maxRetryDelay caps the backoff between invocation retries
+const maxRetryDelay = 30 * time.Second
+
+// httpStatusError is returned when an invocation responds with an error status code
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// isTimeout reports whether err is a client-side timeout
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
 	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
 
-	// Add timestamp
-	result.Timestamp = time.Now()
+// isRetryableInvokeError reports whether an invocation error is transient.
+// Network errors and 5xx responses are retried; 4xx responses and timeouts are not,
+// since a hung function would otherwise block the run for several timeout periods.
+func isRetryableInvokeError(err error) bool {
+	if isTimeout(err) {
+		return false
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	return true
+}
 
-	// Save result to file
-	saveResult(dbType, opType, &result)
+// invokeHTTPWithRetry calls invokeHTTP, retrying transient failures with jittered exponential backoff
+func invokeHTTPWithRetry(endpoint string, payload []byte, retries int, backoff time.Duration) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := invokeHTTP(endpoint, payload)
+		if err == nil {
+			return body, nil
+		}
 
-	// Print summary
-	printSummary(&result)
+		if attempt >= retries || !isRetryableInvokeError(err) {
+			if attempt > 0 {
+				return nil, fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+			}
+			return nil, err
+		}
+
+		// Full jitter: sleep a random duration up to backoff * 2^attempt, capped
+		delay := maxRetryDelay
+		if attempt < 30 && backoff<<attempt < maxRetryDelay {
+			delay = backoff << attempt
+		}
+		if delay > 0 {
+			delay = time.Duration(rand.Int63n(int64(delay)) + 1)
+		}
+		log.Printf("Invocation failed (attempt %d/%d), retrying in %v: %v", attempt+1, retries+1, delay, err)
+		time.Sleep(delay)
+	}
+}
+
+// invokeSDK invokes a deployed function by name through the AWS Lambda Invoke API.
+// The region is taken from the environment (AWS_REGION or the shared config).
+func invokeSDK(ctx context.Context, name string, payload []byte) ([]byte, error) {
+	lambdaClientOnce.Do(func() {
+		if lambdaClient != nil {
+			return
+		}
+		cfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			lambdaClientErr = fmt.Errorf("failed to load AWS config: %w", err)
+			return
+		}
+		lambdaClient = lambda.NewFromConfig(cfg)
+	})
+	if lambdaClientErr != nil {
+		return nil, lambdaClientErr
+	}
+
+	output, err := lambdaClient.Invoke(ctx, &lambda.InvokeInput{
+		FunctionName: aws.String(name),
+		Payload:      payload,
+	})
+	if err != nil {
+		if isTimeout(err) {
+			return nil, fmt.Errorf("request timed out: %w", err)
+		}
+		return nil, err
+	}
+
+	// Function errors are reported in the response rather than as an API error
+	if output.FunctionError != nil {
+		return nil, fmt.Errorf("function error (%s): %s", aws.ToString(output.FunctionError), string(output.Payload))
+	}
+
+	return output.Payload, nil
 }
 
 // runBenchmarkFromConfigFile runs benchmarks defined in a configuration file
@@ -279,37 +981,43 @@ func runBenchmarkFromConfigFile(filePath string) {
 	})
 
 	// Parse the configuration
-	var benchmarkDef BenchmarkDefinition
-	if err := json.Unmarshal([]byte(configStr), &benchmarkDef); err != nil {
+	benchmarkDef, err := parseBenchmarkDefinition(filePath, []byte(configStr))
+	if err != nil {
 		log.Fatalf("Failed to parse configuration file: %v", err)
 	}
 
+	// Validate before invoking anything so typos do not produce empty runs
+	if errs := validateBenchmarkDefinition(benchmarkDef); len(errs) > 0 {
+		log.Printf("Configuration file %s is invalid:", filePath)
+		for _, err := range errs {
+			log.Printf("  - %v", err)
+		}
+		log.Fatalf("Found %d configuration error(s), aborting", len(errs))
+	}
+
 	log.Printf("Running benchmark: %s - %s", benchmarkDef.ID, benchmarkDef.Name)
 	log.Printf("Description: %s", benchmarkDef.Description)
 	log.Printf("Found %d tests to run", len(benchmarkDef.Tests))
 
 	// Get output directory
-	if *outputDir == "" {
-		*outputDir = os.Getenv("RESULTS_DIR")
-		if *outputDir == "" {
-			*outputDir = "./results"
-		}
-	}
+	prepareRunDir(benchmarkDef.ID)
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(*outputDir, 0755); err != nil {
-		log.Fatalf("Failed to create output directory: %v", err)
+	if !*dryRun {
+		openResultStream(*stream)
 	}
 
-	// Get Lambda endpoint
-	if *lambdaEndpoint == "" {
-		*lambdaEndpoint = os.Getenv("LAMBDA_ENDPOINT")
-		if *lambdaEndpoint == "" {
-			log.Fatalf("Lambda endpoint not specified. Use --lambda-endpoint flag or LAMBDA_ENDPOINT environment variable")
-		}
+	// Resolve database-specific endpoints; environment variables override the file
+	loadFunctionURLs(benchmarkDef.Endpoints)
+
+	// Get Lambda endpoint or function name
+	dbTypes := make([]string, 0, len(benchmarkDef.Tests))
+	for _, test := range benchmarkDef.Tests {
+		dbTypes = append(dbTypes, test.Database.Type)
 	}
+	validateInvokeFlags(dbTypes)
 
-	// Run each test
+	// Build a job for each test
+	var jobs []benchmarkJob
 	for _, test := range benchmarkDef.Tests {
 		log.Printf("Running test: %s - %s", test.ID, test.Name)
 
@@ -341,27 +1049,141 @@ func runBenchmarkFromConfigFile(filePath string) {
 			endpoint = specificURL
 		}
 
-		// Run the benchmark with the configured parameters and specific endpoint
-		runBenchmarkWithEndpoint(test.Database.Type, test.Operation.Type, endpoint, params)
+		// Queue the benchmark with the configured parameters and specific endpoint
+		jobs = append(jobs, benchmarkJob{
+			dbType:   test.Database.Type,
+			opType:   test.Operation.Type,
+			endpoint: endpoint,
+			params:   params,
+		})
+	}
+
+	jobs, err = applySweeps(jobs)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dryRun {
+		if err := printPlan(os.Stdout, jobs); err != nil {
+			log.Fatalf("Dry run failed: %v", err)
+		}
+		return
+	}
+
+	// Run the tests
+	start := time.Now()
+	errs := runJobs(jobs, *parallel)
+	writeRunSummary(len(jobs), errs, start)
+	if len(errs) > 0 {
+		reportFailures(errs)
+		os.Exit(1)
 	}
 
 	log.Printf("Completed all tests for benchmark: %s", benchmarkDef.ID)
 }
 
+// parseBenchmarkDefinition decodes a configuration as YAML or JSON based on the file extension
+func parseBenchmarkDefinition(filePath string, data []byte) (BenchmarkDefinition, error) {
+	var benchmarkDef BenchmarkDefinition
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yml", ".yaml":
+		if err := yaml.Unmarshal(data, &benchmarkDef); err != nil {
+			return benchmarkDef, fmt.Errorf("invalid YAML: %w", err)
+		}
+	default:
+		if err := json.Unmarshal(data, &benchmarkDef); err != nil {
+			return benchmarkDef, fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+
+	return benchmarkDef, nil
+}
+
+// validateBenchmarkDefinition checks every test in a configuration and returns all problems found
+func validateBenchmarkDefinition(def BenchmarkDefinition) []error {
+	var errs []error
+
+	if len(def.Tests) == 0 {
+		errs = append(errs, fmt.Errorf("configuration defines no tests"))
+	}
+
+	for db := range def.Endpoints {
+		if !contains(availableDatabases, db) {
+			errs = append(errs, fmt.Errorf("endpoints: unknown database type %q (expected one of %s)", db, strings.Join(availableDatabases, ", ")))
+		}
+	}
+
+	for i, test := range def.Tests {
+		label := fmt.Sprintf("test %d", i+1)
+		if test.ID != "" {
+			label = fmt.Sprintf("test %d (%s)", i+1, test.ID)
+		} else if test.Name != "" {
+			label = fmt.Sprintf("test %d (%s)", i+1, test.Name)
+		}
+
+		dbType := test.Database.Type
+		switch {
+		case dbType == "":
+			errs = append(errs, fmt.Errorf("%s: database.type is required", label))
+		case !contains(availableDatabases, dbType):
+			errs = append(errs, fmt.Errorf("%s: unknown database.type %q (expected one of %s)", label, dbType, strings.Join(availableDatabases, ", ")))
+		}
+
+		opType := test.Operation.Type
+		switch {
+		case opType == "":
+			errs = append(errs, fmt.Errorf("%s: operation.type is required", label))
+		case !contains(knownOperations, opType):
+			errs = append(errs, fmt.Errorf("%s: unknown operation.type %q", label, opType))
+		}
+
+		if test.Operation.Count <= 0 {
+			errs = append(errs, fmt.Errorf("%s: operation.count must be positive, got %d", label, test.Operation.Count))
+		}
+
+		batchLimit := maxBatchSize
+		if dbType == "dynamodb" {
+			batchLimit = maxDynamoDBBatchSize
+		}
+		if test.Operation.BatchSize < 0 || test.Operation.BatchSize > batchLimit {
+			errs = append(errs, fmt.Errorf("%s: operation.batchSize must be between 1 and %d, got %d", label, batchLimit, test.Operation.BatchSize))
+		}
+
+		if test.Operation.Concurrency < 0 || test.Operation.Concurrency > maxConcurrency {
+			errs = append(errs, fmt.Errorf("%s: operation.concurrency must be between 1 and %d, got %d", label, maxConcurrency, test.Operation.Concurrency))
+		}
+	}
+
+	return errs
+}
+
+// contains reports whether values includes v
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
 // TODO: This function is not currently used directly but kept for future implementation of standalone benchmark runs
-func runBenchmark(dbType, opType string, customParams map[string]interface{}) {
+func runBenchmark(dbType, opType string, customParams map[string]interface{}) error {
 	// Get database-specific endpoint if available
 	endpoint := *lambdaEndpoint
 	if specificURL, ok := functionURLs[dbType]; ok && specificURL != "" {
 		endpoint = specificURL
 	}
-	runBenchmarkWithEndpoint(dbType, opType, endpoint, customParams)
+	return runBenchmarkWithEndpoint(dbType, opType, endpoint, customParams)
 }
 
 func saveResult(dbType, opType string, result *BenchmarkResult) {
+	streamResult(result)
+
 	// Create filename
 	timestamp := time.Now().Format("20060102-150405")
-	filename := fmt.Sprintf("%s-%s-%s.json", dbType, opType, timestamp)
+	filename := fmt.Sprintf("%s-%s-%s-%d.json", dbType, opType, timestamp, resultSeq.Add(1))
 	filepath := filepath.Join(*outputDir, filename)
 
 	// Marshal result to JSON with indentation for readability
@@ -374,10 +1196,146 @@ func saveResult(dbType, opType string, result *BenchmarkResult) {
 	// Write to file
 	if err := os.WriteFile(filepath, jsonData, 0644); err != nil {
 		log.Printf("Failed to write result to file: %v", err)
+		recordSummaryEntry("", result)
 		return
 	}
 
 	log.Printf("Result saved to %s", filepath)
+	recordSummaryEntry(filename, result)
+}
+
+// recordSummaryEntry adds a saved result to the run summary
+func recordSummaryEntry(file string, result *BenchmarkResult) {
+	entry := runSummaryEntry{
+		DatabaseType:           result.DatabaseType,
+		OperationType:          result.OperationType,
+		File:                   file,
+		Success:                result.Success,
+		ErrorMessage:           result.ErrorMessage,
+		ItemsProcessed:         result.ItemsProcessed,
+		Throughput:             result.Throughput,
+		AvgOperationDurationNs: result.AvgOperationDurationNs,
+	}
+	if result.Iterations != nil {
+		entry.Iterations = result.Iterations.Count
+	}
+
+	summaryEntriesMu.Lock()
+	summaryEntries = append(summaryEntries, entry)
+	summaryEntriesMu.Unlock()
+}
+
+// prepareRunDir resolves the output directory from --output or RESULTS_DIR and points
+// it at the run's subdirectory, named by --run-id or the start time. Unless this is a
+// dry run, it creates the directory and writes the run manifest. benchmarkID is empty
+// for runs configured by flags.
+func prepareRunDir(benchmarkID string) {
+	if *outputDir == "" {
+		*outputDir = os.Getenv("RESULTS_DIR")
+		if *outputDir == "" {
+			*outputDir = "./results"
+		}
+	}
+
+	start := time.Now()
+	if *runID == "" {
+		*runID = start.Format("20060102-150405")
+	}
+	*outputDir = filepath.Join(*outputDir, *runID)
+
+	if *dryRun {
+		return
+	}
+
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatalf("Failed to create output directory: %v", err)
+	}
+
+	manifest := runManifest{
+		RunID:       *runID,
+		StartTime:   start,
+		ConfigFile:  *configFile,
+		BenchmarkID: benchmarkID,
+		Flags:       make(map[string]string),
+	}
+	flag.VisitAll(func(f *flag.Flag) {
+		manifest.Flags[f.Name] = f.Value.String()
+	})
+
+	jsonData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal run manifest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(*outputDir, "manifest.json"), jsonData, 0644); err != nil {
+		log.Fatalf("Failed to write run manifest: %v", err)
+	}
+	log.Printf("Writing results for run %s to %s", *runID, *outputDir)
+}
+
+// writeRunSummary writes run_summary.json to the output directory. Benchmarks are
+// counted once each, however many iterations they ran.
+func writeRunSummary(total int, errs []error, start time.Time) {
+	summaryEntriesMu.Lock()
+	summary := runSummary{
+		StartTime:  start,
+		WallTimeNs: time.Since(start).Nanoseconds(),
+		Total:      total,
+		Succeeded:  total - len(errs),
+		Failed:     len(errs),
+		Results:    append([]runSummaryEntry{}, summaryEntries...),
+	}
+	summaryEntriesMu.Unlock()
+
+	jsonData, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		log.Printf("Failed to marshal run summary: %v", err)
+		return
+	}
+
+	path := filepath.Join(*outputDir, "run_summary.json")
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		log.Printf("Failed to write run summary: %v", err)
+		return
+	}
+
+	log.Printf("Run summary saved to %s (%d succeeded, %d failed)", path, summary.Succeeded, summary.Failed)
+}
+
+// openResultStream sets up --stream output, appending to path or writing to stdout for -
+func openResultStream(path string) {
+	switch path {
+	case "":
+		return
+	case "-":
+		resultStream = os.Stdout
+	default:
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatalf("Failed to open result stream: %v", err)
+		}
+		resultStream = f
+	}
+}
+
+// streamResult writes result as a single NDJSON line; concurrent callers never interleave
+func streamResult(result *BenchmarkResult) {
+	if resultStream == nil {
+		return
+	}
+
+	line, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Failed to marshal result for stream: %v", err)
+		return
+	}
+	line = append(line, '\n')
+
+	resultStreamMu.Lock()
+	defer resultStreamMu.Unlock()
+	if _, err := resultStream.Write(line); err != nil {
+		log.Printf("Failed to write result to stream: %v", err)
+	}
 }
 
 func printSummary(result *BenchmarkResult) {
@@ -393,5 +1351,10 @@ func printSummary(result *BenchmarkResult) {
 	log.Printf("Total Time:  %.2f ms", float64(result.TotalDurationNs)/1e6)
 	log.Printf("Avg Time:    %.2f ms", float64(result.AvgOperationDurationNs)/1e6)
 	log.Printf("Throughput:  %.2f ops/sec", result.Throughput)
+	if result.Iterations != nil {
+		log.Printf("Iterations:  %d/%d successful", result.Iterations.Successful, result.Iterations.Count)
+		log.Printf("Throughput:  min %.2f / max %.2f / stddev %.2f ops/sec",
+			result.Iterations.Throughput.Min, result.Iterations.Throughput.Max, result.Iterations.Throughput.StdDev)
+	}
 	log.Printf("==========================")
 }
@@ -0,0 +1,1134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/cmd/benchmark/handler"
+	benchops "github.com/pedro-hbl/lambda-gopher-benchmark/cmd/benchmark/operations"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/dbtest"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/metrics"
+	benchdb "github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// setSweepFlags sets the sweep flags for the duration of a test
+func setSweepFlags(t *testing.T, sizes, levels string) {
+	t.Helper()
+	previousSizes, previousLevels := *dataSizes, *concurrencyLevels
+	*dataSizes, *concurrencyLevels = sizes, levels
+	t.Cleanup(func() {
+		*dataSizes, *concurrencyLevels = previousSizes, previousLevels
+	})
+}
+
+func TestApplySweepsDataSizes(t *testing.T) {
+	jobs := []benchmarkJob{
+		{dbType: "dynamodb", opType: "read", params: map[string]interface{}{"itemCount": 10}},
+		{dbType: "dynamodb", opType: "write", params: map[string]interface{}{}},
+	}
+
+	tests := []struct {
+		name      string
+		sizes     string
+		wantSizes []int
+		wantErr   bool
+	}{
+		{"no sweep", "", nil, false},
+		{"single size", "512", []int{512}, false},
+		{"three sizes", "256, 1024,4096", []int{256, 1024, 4096}, false},
+		{"zero size", "0,1024", nil, true},
+		{"not a number", "1kb", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setSweepFlags(t, tt.sizes, "")
+
+			got, err := applySweeps(jobs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("applySweeps() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applySweeps() error = %v", err)
+			}
+
+			if tt.wantSizes == nil {
+				if len(got) != len(jobs) {
+					t.Fatalf("applySweeps() returned %d jobs, want the %d unchanged", len(got), len(jobs))
+				}
+				return
+			}
+
+			if want := len(jobs) * len(tt.wantSizes); len(got) != want {
+				t.Fatalf("applySweeps() returned %d jobs, want %d", len(got), want)
+			}
+			for i, job := range got {
+				wantJob := jobs[i/len(tt.wantSizes)]
+				wantSize := tt.wantSizes[i%len(tt.wantSizes)]
+				if job.opType != wantJob.opType {
+					t.Errorf("job %d opType = %q, want %q", i, job.opType, wantJob.opType)
+				}
+				if job.params["dataSize"] != wantSize {
+					t.Errorf("job %d dataSize = %v, want %d", i, job.params["dataSize"], wantSize)
+				}
+			}
+		})
+	}
+
+	// The sweep copies the parameters instead of sharing them between invocations
+	if _, ok := jobs[0].params["dataSize"]; ok {
+		t.Error("applySweeps() modified the parameters of the input job")
+	}
+}
+
+func TestApplySweepsInvocationCounts(t *testing.T) {
+	jobs := []benchmarkJob{
+		{dbType: "dynamodb", opType: "read-parallel", params: map[string]interface{}{}},
+		{dbType: "dynamodb", opType: "read", params: map[string]interface{}{}},
+		{dbType: "redis", opType: "mixed", params: map[string]interface{}{}},
+	}
+
+	tests := []struct {
+		name    string
+		sizes   string
+		levels  string
+		wantOps map[string]int
+	}{
+		{"no sweeps", "", "", map[string]int{"read-parallel": 1, "read": 1, "mixed": 1}},
+		{"concurrency only sweeps concurrent operations", "", "1,10,50", map[string]int{"read-parallel": 3, "read": 1, "mixed": 3}},
+		{"data sizes apply to every operation", "256,1024", "", map[string]int{"read-parallel": 2, "read": 2, "mixed": 2}},
+		{"both sweeps multiply", "256,1024", "1,10,50", map[string]int{"read-parallel": 6, "read": 2, "mixed": 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setSweepFlags(t, tt.sizes, tt.levels)
+
+			got, err := applySweeps(jobs)
+			if err != nil {
+				t.Fatalf("applySweeps() error = %v", err)
+			}
+
+			ops := make(map[string]int)
+			for _, job := range got {
+				ops[job.opType]++
+				_, hasLevel := job.params["concurrency"]
+				if wantLevel := tt.levels != "" && isConcurrentOperation(job.opType); hasLevel != wantLevel {
+					t.Errorf("%s job has concurrency set = %v, want %v", job.opType, hasLevel, wantLevel)
+				}
+			}
+			for opType, want := range tt.wantOps {
+				if ops[opType] != want {
+					t.Errorf("%s ran %d times, want %d", opType, ops[opType], want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplySweepsRejectsInvalidConcurrency(t *testing.T) {
+	setSweepFlags(t, "", "10,-1")
+
+	jobs := []benchmarkJob{{dbType: "dynamodb", opType: "read-parallel", params: map[string]interface{}{}}}
+	if _, err := applySweeps(jobs); err == nil {
+		t.Error("applySweeps() error = nil, want an error for a negative level")
+	}
+}
+
+func TestResultName(t *testing.T) {
+	tests := []struct {
+		name   string
+		sizes  string
+		levels string
+		opType string
+		params map[string]interface{}
+		want   string
+	}{
+		{"no sweeps", "", "", "read", map[string]interface{}{"dataSize": 256}, "read"},
+		{"data size", "256,1024", "", "read", map[string]interface{}{"dataSize": 256}, "read-256b"},
+		{"concurrency", "", "1,10", "read-parallel", map[string]interface{}{"concurrency": 10}, "read-parallel-c10"},
+		{"concurrency ignored for sequential operations", "", "1,10", "read", map[string]interface{}{"concurrency": 10}, "read"},
+		{"both", "1024", "1,10", "mixed", map[string]interface{}{"dataSize": 1024, "concurrency": 1}, "mixed-1024b-c1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setSweepFlags(t, tt.sizes, tt.levels)
+
+			if got := resultName(tt.opType, tt.params); got != tt.want {
+				t.Errorf("resultName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKnownOperationsMatchFactory(t *testing.T) {
+	factory := benchops.NewOperationFactory()
+	for _, opType := range knownOperations {
+		if _, err := factory.CreateOperation(opType, nil); err != nil {
+			t.Errorf("known operation %q is not registered: %v", opType, err)
+		}
+	}
+	for _, opType := range []string{"immudb_write", "immudb_verified_write", "immudb_read", "immudb_query"} {
+		if !contains(knownOperations, opType) {
+			t.Errorf("registered operation %q is not known", opType)
+		}
+	}
+}
+
+func TestValidateBenchmarkDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		test     string
+		wantErrs []string
+	}{
+		{
+			"valid",
+			`{"database": {"type": "dynamodb"}, "operation": {"type": "read-parallel", "count": 10, "batchSize": 25, "concurrency": 50}}`,
+			nil,
+		},
+		{
+			"ImmuDB operation",
+			`{"database": {"type": "immudb"}, "operation": {"type": "immudb_query", "count": 10}}`,
+			nil,
+		},
+		{
+			"unregistered operation",
+			`{"id": "t", "database": {"type": "dynamodb"}, "operation": {"type": "batch-write", "count": 10}}`,
+			[]string{`test 1 (t): unknown operation.type "batch-write"`},
+		},
+		{
+			"missing types",
+			`{"operation": {"count": 10}}`,
+			[]string{"database.type is required", "operation.type is required"},
+		},
+		{
+			"unknown database",
+			`{"database": {"type": "postgres"}, "operation": {"type": "read", "count": 10}}`,
+			[]string{`unknown database.type "postgres"`},
+		},
+		{
+			"invalid counts",
+			`{"database": {"type": "dynamodb"}, "operation": {"type": "write-batch", "count": 0, "batchSize": 100, "concurrency": 5000}}`,
+			[]string{
+				"operation.count must be positive, got 0",
+				"operation.batchSize must be between 1 and 25, got 100",
+				"operation.concurrency must be between 1 and 1000, got 5000",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := parseBenchmarkDefinition("test.json", []byte(`{"tests": [`+tt.test+`]}`))
+			if err != nil {
+				t.Fatalf("parseBenchmarkDefinition() error = %v", err)
+			}
+
+			errs := validateBenchmarkDefinition(def)
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("validateBenchmarkDefinition() = %v, want %d errors", errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("error %d = %q, want it to contain %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestShippedConfigsValidate(t *testing.T) {
+	files, err := filepath.Glob("../../configs/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no configuration files found: %v", err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			def, err := parseBenchmarkDefinition(file, data)
+			if err != nil {
+				t.Fatalf("parseBenchmarkDefinition() error = %v", err)
+			}
+			for _, err := range validateBenchmarkDefinition(def) {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// setFlag sets a flag value for the duration of a test
+func setFlag[T any](t *testing.T, p *T, value T) {
+	t.Helper()
+	previous := *p
+	*p = value
+	t.Cleanup(func() { *p = previous })
+}
+
+// useOutputDir points the runner's results at a temporary directory and clears the
+// run summary entries for the duration of a test
+func useOutputDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	setFlag(t, outputDir, dir)
+
+	summaryEntriesMu.Lock()
+	previous := summaryEntries
+	summaryEntries = nil
+	summaryEntriesMu.Unlock()
+	t.Cleanup(func() {
+		summaryEntriesMu.Lock()
+		summaryEntries = previous
+		summaryEntriesMu.Unlock()
+	})
+	return dir
+}
+
+// readResultFiles decodes the result files saved in dir, skipping the run manifest and summary
+func readResultFiles(t *testing.T, dir string) []BenchmarkResult {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results []BenchmarkResult
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == "manifest.json" || entry.Name() == "run_summary.json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result BenchmarkResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			t.Fatalf("%s is not a result: %v", entry.Name(), err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// cannedResult returns the response of a successful benchmark invocation for config
+func cannedResult(t *testing.T, config BenchmarkConfig, throughput float64) []byte {
+	t.Helper()
+	data, err := json.Marshal(BenchmarkResult{
+		OperationType:          config.OperationType,
+		DatabaseType:           config.DatabaseType,
+		Success:                true,
+		ItemsProcessed:         10,
+		TotalDurationNs:        int64(10 * time.Millisecond),
+		AvgOperationDurationNs: int64(time.Millisecond),
+		Throughput:             throughput,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// mockLambdaClient answers Invoke calls with a canned result for the requested config
+type mockLambdaClient struct {
+	t             *testing.T
+	functionError string
+	inputs        []*lambda.InvokeInput
+}
+
+func (m *mockLambdaClient) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	m.inputs = append(m.inputs, params)
+	if m.functionError != "" {
+		return &lambda.InvokeOutput{FunctionError: aws.String(m.functionError), Payload: []byte(`{"errorMessage":"boom"}`)}, nil
+	}
+
+	var config BenchmarkConfig
+	if err := json.Unmarshal(params.Payload, &config); err != nil {
+		m.t.Errorf("Invoke() payload is not a BenchmarkConfig: %v", err)
+	}
+	return &lambda.InvokeOutput{StatusCode: 200, Payload: cannedResult(m.t, config, 250)}, nil
+}
+
+// useLambdaClient makes sdk invocations use client for the duration of a test
+func useLambdaClient(t *testing.T, client lambdaInvoker) {
+	t.Helper()
+	previous := lambdaClient
+	lambdaClient, lambdaClientErr, lambdaClientOnce = client, nil, sync.Once{}
+	t.Cleanup(func() {
+		lambdaClient, lambdaClientErr, lambdaClientOnce = previous, nil, sync.Once{}
+	})
+}
+
+func TestInvokeBenchmarkSDK(t *testing.T) {
+	dir := useOutputDir(t)
+	setFlag(t, invokeMode, "sdk")
+	setFlag(t, functionName, "benchmark-function")
+	client := &mockLambdaClient{t: t}
+	useLambdaClient(t, client)
+
+	result, err := invokeBenchmark("dynamodb", "read", "", nil, true)
+	if err != nil {
+		t.Fatalf("invokeBenchmark() error = %v", err)
+	}
+	if !result.Success || result.DatabaseType != "dynamodb" || result.OperationType != "read" || result.Throughput != 250 {
+		t.Errorf("invokeBenchmark() = %+v, want the canned result", result)
+	}
+
+	if len(client.inputs) != 1 || aws.ToString(client.inputs[0].FunctionName) != "benchmark-function" {
+		t.Fatalf("Invoke() calls = %+v, want one call to benchmark-function", client.inputs)
+	}
+
+	saved := readResultFiles(t, dir)
+	if len(saved) != 1 || saved[0].Throughput != 250 || saved[0].Timestamp.IsZero() {
+		t.Errorf("saved results = %+v, want the canned result with a timestamp", saved)
+	}
+}
+
+func TestInvokeBenchmarkSDKFunctionError(t *testing.T) {
+	dir := useOutputDir(t)
+	setFlag(t, invokeMode, "sdk")
+	setFlag(t, functionName, "benchmark-function")
+	useLambdaClient(t, &mockLambdaClient{t: t, functionError: "Unhandled"})
+
+	if _, err := invokeBenchmark("dynamodb", "read", "", nil, true); err == nil || !strings.Contains(err.Error(), "function error (Unhandled)") {
+		t.Fatalf("invokeBenchmark() error = %v, want the function error", err)
+	}
+
+	// The failure is still recorded
+	saved := readResultFiles(t, dir)
+	if len(saved) != 1 || saved[0].Success || !strings.Contains(saved[0].ErrorMessage, "boom") {
+		t.Errorf("saved results = %+v, want one failed result", saved)
+	}
+}
+
+// benchmarkServer starts a stub Runtime Interface Emulator that answers each request
+// with respond and counts the requests it receives
+func benchmarkServer(t *testing.T, respond func(config BenchmarkConfig) (int, []byte)) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	calls := &atomic.Int64{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/2015-03-31/functions/function/invocations" {
+			t.Errorf("request path = %s, want the invocation path", r.URL.Path)
+		}
+		var config BenchmarkConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			t.Errorf("request body is not a BenchmarkConfig: %v", err)
+		}
+		status, body := respond(config)
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+func TestRunJobs(t *testing.T) {
+	dir := useOutputDir(t)
+	setFlag(t, maxRetries, 0)
+	server, calls := benchmarkServer(t, func(config BenchmarkConfig) (int, []byte) {
+		if config.DatabaseType == "redis" && config.OperationType == "query" {
+			return http.StatusBadRequest, []byte(`{"error":"unsupported"}`)
+		}
+		return http.StatusOK, cannedResult(t, config, 100)
+	})
+
+	var jobs []benchmarkJob
+	for _, db := range []string{"dynamodb", "redis"} {
+		for _, op := range []string{"read", "write", "query"} {
+			jobs = append(jobs, benchmarkJob{dbType: db, opType: op, endpoint: server.URL})
+		}
+	}
+
+	// The failing job does not stop the others
+	errs := runJobs(jobs, 3)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "redis - query") {
+		t.Errorf("runJobs() errors = %v, want only redis - query", errs)
+	}
+	if calls.Load() != int64(len(jobs)) {
+		t.Errorf("server received %d requests, want %d", calls.Load(), len(jobs))
+	}
+
+	saved := make(map[string]bool)
+	for _, result := range readResultFiles(t, dir) {
+		key := result.DatabaseType + "/" + result.OperationType
+		if saved[key] {
+			t.Errorf("%s saved twice", key)
+		}
+		saved[key] = result.Success
+	}
+	for _, job := range jobs {
+		key := job.dbType + "/" + job.opType
+		success, ok := saved[key]
+		if !ok {
+			t.Errorf("no result file for %s", key)
+		} else if success != (key != "redis/query") {
+			t.Errorf("%s success = %v", key, success)
+		}
+	}
+}
+
+func TestInvokeHTTPWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		status    int
+		retries   int
+		wantCalls int64
+		wantErr   bool
+	}{
+		{"fails twice then succeeds", 2, http.StatusServiceUnavailable, 3, 3, false},
+		{"retries exhausted", 5, http.StatusBadGateway, 2, 3, true},
+		{"client errors are not retried", 5, http.StatusBadRequest, 3, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failures atomic.Int64
+			server, calls := benchmarkServer(t, func(config BenchmarkConfig) (int, []byte) {
+				if failures.Add(1) <= int64(tt.failures) {
+					return tt.status, []byte("unavailable")
+				}
+				return http.StatusOK, cannedResult(t, config, 100)
+			})
+
+			body, err := invokeHTTPWithRetry(server.URL, []byte(`{"databaseType":"dynamodb","operationType":"read"}`), tt.retries, time.Millisecond)
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("server received %d requests, want %d", calls.Load(), tt.wantCalls)
+			}
+			if tt.wantErr {
+				var statusErr *httpStatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+					t.Errorf("invokeHTTPWithRetry() error = %v, want status %d", err, tt.status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("invokeHTTPWithRetry() error = %v", err)
+			}
+			var result BenchmarkResult
+			if err := json.Unmarshal(body, &result); err != nil || !result.Success {
+				t.Errorf("invokeHTTPWithRetry() = %s, want the successful result", body)
+			}
+		})
+	}
+}
+
+func TestInvokeBenchmarkRecordsRetriedSuccess(t *testing.T) {
+	dir := useOutputDir(t)
+	setFlag(t, maxRetries, 3)
+	setFlag(t, retryBackoff, time.Millisecond)
+	var failures atomic.Int64
+	server, _ := benchmarkServer(t, func(config BenchmarkConfig) (int, []byte) {
+		if failures.Add(1) <= 2 {
+			return http.StatusInternalServerError, nil
+		}
+		return http.StatusOK, cannedResult(t, config, 100)
+	})
+
+	if _, err := invokeBenchmark("dynamodb", "read", server.URL, nil, true); err != nil {
+		t.Fatalf("invokeBenchmark() error = %v", err)
+	}
+
+	// Only the final outcome is recorded
+	saved := readResultFiles(t, dir)
+	if len(saved) != 1 || !saved[0].Success {
+		t.Errorf("saved results = %+v, want one successful result", saved)
+	}
+}
+
+func TestInvokeTimeout(t *testing.T) {
+	dir := useOutputDir(t)
+	setFlag(t, maxRetries, 3)
+	setFlag(t, &httpClient.Timeout, 50*time.Millisecond)
+
+	// Hang on writes until the runner gives up on the request
+	server, calls := benchmarkServer(t, func(config BenchmarkConfig) (int, []byte) {
+		if config.OperationType == "write" {
+			time.Sleep(500 * time.Millisecond)
+		}
+		return http.StatusOK, cannedResult(t, config, 100)
+	})
+
+	jobs := []benchmarkJob{
+		{dbType: "dynamodb", opType: "write", endpoint: server.URL},
+		{dbType: "dynamodb", opType: "read", endpoint: server.URL},
+	}
+	errs := runJobs(jobs, 1)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "request timed out after 50ms") {
+		t.Fatalf("runJobs() errors = %v, want a timeout for the write", errs)
+	}
+	// Timeouts are not retried
+	if calls.Load() != 2 {
+		t.Errorf("server received %d requests, want 2", calls.Load())
+	}
+
+	saved := make(map[string]BenchmarkResult)
+	for _, result := range readResultFiles(t, dir) {
+		saved[result.OperationType] = result
+	}
+	if write := saved["write"]; write.Success || !strings.Contains(write.ErrorMessage, "timed out") {
+		t.Errorf("write result = %+v, want a recorded timeout", write)
+	}
+	if read := saved["read"]; !read.Success {
+		t.Errorf("read result = %+v, want the run to move on to the read", read)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	got := summarize([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	want := StatSummary{Mean: 5, Min: 2, Max: 9, StdDev: 2}
+	if got != want {
+		t.Errorf("summarize() = %+v, want %+v", got, want)
+	}
+	if got := summarize(nil); got != (StatSummary{}) {
+		t.Errorf("summarize(nil) = %+v, want zero", got)
+	}
+}
+
+func TestIterationsAggregate(t *testing.T) {
+	dir := useOutputDir(t)
+	setFlag(t, iterations, 4)
+	setFlag(t, maxRetries, 0)
+
+	// Each iteration reports a different throughput and latency
+	var call atomic.Int64
+	server, _ := benchmarkServer(t, func(config BenchmarkConfig) (int, []byte) {
+		n := call.Add(1)
+		data, _ := json.Marshal(BenchmarkResult{
+			OperationType:          config.OperationType,
+			DatabaseType:           config.DatabaseType,
+			Success:                true,
+			ItemsProcessed:         10,
+			AvgOperationDurationNs: n * int64(time.Millisecond),
+			Throughput:             float64(n * 100),
+		})
+		return http.StatusOK, data
+	})
+
+	if err := runBenchmarkWithEndpoint("dynamodb", "read", server.URL, nil); err != nil {
+		t.Fatalf("runBenchmarkWithEndpoint() error = %v", err)
+	}
+
+	var aggregates []BenchmarkResult
+	saved := readResultFiles(t, dir)
+	for _, result := range saved {
+		if result.Iterations != nil {
+			aggregates = append(aggregates, result)
+		}
+	}
+	if len(saved) != 5 || len(aggregates) != 1 {
+		t.Fatalf("saved %d results with %d aggregates, want 4 iterations and 1 aggregate", len(saved), len(aggregates))
+	}
+
+	aggregate := aggregates[0]
+	// Throughputs 100..400 have a mean of 250 and a population stddev of sqrt(12500)
+	wantThroughput := StatSummary{Mean: 250, Min: 100, Max: 400, StdDev: math.Sqrt(12500)}
+	if got := aggregate.Iterations.Throughput; math.Abs(got.StdDev-wantThroughput.StdDev) > 1e-9 || got.Mean != wantThroughput.Mean ||
+		got.Min != wantThroughput.Min || got.Max != wantThroughput.Max {
+		t.Errorf("throughput = %+v, want %+v", got, wantThroughput)
+	}
+	if got := aggregate.Iterations.AvgOperationDurationNs; got.Mean != 2.5e6 || math.Abs(got.StdDev-math.Sqrt(1.25e12)) > 1e-3 {
+		t.Errorf("latency = %+v, want a mean of 2.5ms and a stddev of sqrt(1.25e12)ns", got)
+	}
+	if !aggregate.Success || aggregate.Throughput != 250 || aggregate.AvgOperationDurationNs != 2500000 ||
+		aggregate.ItemsProcessed != 40 || aggregate.Iterations.Count != 4 || aggregate.Iterations.Successful != 4 {
+		t.Errorf("aggregate = %+v, iterations = %+v", aggregate, aggregate.Iterations)
+	}
+}
+
+func TestAggregateResultsAllFailed(t *testing.T) {
+	aggregate := aggregateResults("dynamodb", "read", []*BenchmarkResult{{Success: false}}, 3)
+	if aggregate.Success || aggregate.ErrorMessage != "all 3 iterations failed" || aggregate.Iterations.Successful != 0 {
+		t.Errorf("aggregateResults() = %+v, want a failed aggregate", aggregate)
+	}
+}
+
+func TestParseBenchmarkDefinitionYAMLMatchesJSON(t *testing.T) {
+	jsonConfig := `{
+  "id": "mixed",
+  "name": "Mixed benchmark",
+  "description": "Reads and writes",
+  "endpoints": {"dynamodb": "http://localhost:9000"},
+  "tests": [
+    {
+      "id": "write",
+      "name": "Write",
+      "database": {"type": "dynamodb", "config": {"tableName": "Transactions", "consistentRead": true}},
+      "operation": {"type": "write-batch", "count": 200, "batchSize": 25, "concurrency": 4, "data": {"size": 1024, "ratio": 0.7}}
+    },
+    {
+      "id": "read",
+      "name": "Read",
+      "database": {"type": "redis"},
+      "operation": {"type": "read", "count": 50}
+    }
+  ]
+}`
+	yamlConfig := `
+# The same benchmark as YAML
+id: mixed
+name: Mixed benchmark
+description: Reads and writes
+endpoints:
+  dynamodb: http://localhost:9000
+tests:
+  - id: write
+    name: Write
+    database:
+      type: dynamodb
+      config:
+        tableName: Transactions
+        consistentRead: true
+    operation:
+      type: write-batch
+      count: 200
+      batchSize: 25
+      concurrency: 4
+      data:
+        size: 1024
+        ratio: 0.7
+  - id: read
+    name: Read
+    database:
+      type: redis
+    operation:
+      type: read
+      count: 50
+`
+
+	fromJSON, err := parseBenchmarkDefinition("benchmark.json", []byte(jsonConfig))
+	if err != nil {
+		t.Fatalf("parseBenchmarkDefinition(json) error = %v", err)
+	}
+	for _, name := range []string{"benchmark.yaml", "benchmark.YML"} {
+		fromYAML, err := parseBenchmarkDefinition(name, []byte(yamlConfig))
+		if err != nil {
+			t.Fatalf("parseBenchmarkDefinition(%s) error = %v", name, err)
+		}
+
+		// YAML decodes whole numbers in free-form maps as int and JSON as float64, so
+		// the definitions are compared as they are sent to the benchmark function
+		gotJSON, _ := json.Marshal(fromYAML)
+		wantJSON, _ := json.Marshal(fromJSON)
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("%s parsed to %s, want %s", name, gotJSON, wantJSON)
+		}
+		if len(fromYAML.Tests) != 2 || fromYAML.Tests[0].Operation.BatchSize != 25 || fromYAML.Endpoints["dynamodb"] != "http://localhost:9000" {
+			t.Errorf("%s parsed to %+v", name, fromYAML)
+		}
+	}
+
+	if _, err := parseBenchmarkDefinition("benchmark.yaml", []byte("tests: [")); err == nil || !strings.Contains(err.Error(), "invalid YAML") {
+		t.Errorf("parseBenchmarkDefinition() of invalid YAML error = %v", err)
+	}
+}
+
+func TestWarmupInvocations(t *testing.T) {
+	tests := []struct {
+		name       string
+		warmup     int
+		iterations int
+		wantFiles  int
+	}{
+		{"no warmup", 0, 1, 1},
+		{"warmup before one run", 2, 1, 1},
+		// Several iterations also save their aggregate
+		{"warmup before iterations", 3, 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useOutputDir(t)
+			setFlag(t, warmup, tt.warmup)
+			setFlag(t, iterations, tt.iterations)
+			server, calls := benchmarkServer(t, func(config BenchmarkConfig) (int, []byte) {
+				return http.StatusOK, cannedResult(t, config, 100)
+			})
+
+			if err := runBenchmarkWithEndpoint("dynamodb", "read", server.URL, nil); err != nil {
+				t.Fatalf("runBenchmarkWithEndpoint() error = %v", err)
+			}
+
+			if want := int64(tt.warmup + tt.iterations); calls.Load() != want {
+				t.Errorf("server received %d requests, want %d", calls.Load(), want)
+			}
+			if saved := readResultFiles(t, dir); len(saved) != tt.wantFiles {
+				t.Errorf("saved %d results, want %d", len(saved), tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	setFlag(t, invokeMode, "http")
+	setFlag(t, concurrency, 8)
+	setFlag(t, itemCount, 50)
+	setFlag(t, dataSize, 256)
+	setFlag(t, filterMetrics, "")
+
+	jobs := []benchmarkJob{
+		{dbType: "dynamodb", opType: "read", endpoint: "http://dynamodb-fn:8080"},
+		{dbType: "redis", opType: "write-batch", endpoint: "http://redis-fn:8080", params: map[string]interface{}{"concurrency": 2}},
+	}
+
+	var out bytes.Buffer
+	if err := printPlan(&out, jobs); err != nil {
+		t.Fatalf("printPlan() error = %v", err)
+	}
+
+	var got []plannedInvocation
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("printPlan() output is not a JSON plan: %v\n%s", err, out.String())
+	}
+	want := []plannedInvocation{
+		{
+			Endpoint: "http://dynamodb-fn:8080",
+			Config: BenchmarkConfig{DatabaseType: "dynamodb", OperationType: "read", Parameters: map[string]interface{}{
+				"concurrency": 8.0, "itemCount": 50.0, "dataSize": 256.0, "accountId": "benchmark-account",
+				"consistentRead": true, "collectMetrics": true,
+			}},
+		},
+		{
+			// Custom parameters override the flags, and batch operations get a default batch size
+			Endpoint: "http://redis-fn:8080",
+			Config: BenchmarkConfig{DatabaseType: "redis", OperationType: "write-batch", Parameters: map[string]interface{}{
+				"concurrency": 2.0, "itemCount": 50.0, "dataSize": 256.0, "accountId": "benchmark-account",
+				"consistentRead": true, "collectMetrics": true, "batchSize": 25.0,
+			}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("printPlan() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPrintPlanErrors(t *testing.T) {
+	jobs := []benchmarkJob{{dbType: "immudb", opType: "read", endpoint: ""}}
+
+	setFlag(t, invokeMode, "http")
+	var out bytes.Buffer
+	if err := printPlan(&out, jobs); err == nil || !strings.Contains(err.Error(), "no valid endpoint resolved for immudb") {
+		t.Errorf("printPlan() error = %v, want an unresolved endpoint", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("printPlan() printed %q for an invalid plan", out.String())
+	}
+
+	setFlag(t, invokeMode, "sdk")
+	setFlag(t, functionName, "benchmark-function")
+	out.Reset()
+	if err := printPlan(&out, jobs); err != nil || !strings.Contains(out.String(), `"functionName": "benchmark-function"`) {
+		t.Errorf("printPlan() in sdk mode = %q, %v, want the function name", out.String(), err)
+	}
+}
+
+// captureStdout returns what fn writes to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	return <-output
+}
+
+func TestConfigFileEndpoints(t *testing.T) {
+	setFlag(t, dryRun, true)
+	setFlag(t, invokeMode, "http")
+	setFlag(t, lambdaEndpoint, "http://default-fn:8080")
+	setFlag(t, outputDir, t.TempDir())
+	setFlag(t, runID, "")
+	setFlag(t, &functionURLs, functionURLs)
+	t.Setenv("DYNAMODB_FUNCTION_URL", "http://dynamodb-env:8080")
+	t.Setenv("IMMUDB_FUNCTION_URL", "")
+	t.Setenv("TIMESTREAM_FUNCTION_URL", "")
+
+	config := `{
+  "id": "endpoints",
+  "endpoints": {"dynamodb": "http://dynamodb-file:8080", "immudb": "http://immudb-file:8080"},
+  "tests": [
+    {"database": {"type": "dynamodb"}, "operation": {"type": "read", "count": 1}},
+    {"database": {"type": "immudb"}, "operation": {"type": "read", "count": 1}},
+    {"database": {"type": "redis"}, "operation": {"type": "read", "count": 1}}
+  ]
+}`
+	path := filepath.Join(t.TempDir(), "benchmark.json")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setFlag(t, configFile, path)
+
+	output := captureStdout(t, func() { runBenchmarkFromConfigFile(path) })
+
+	var plan []plannedInvocation
+	if err := json.Unmarshal([]byte(output), &plan); err != nil {
+		t.Fatalf("dry run output is not a JSON plan: %v\n%s", err, output)
+	}
+	// The environment wins over the file, which wins over --lambda-endpoint
+	want := map[string]string{
+		"dynamodb": "http://dynamodb-env:8080",
+		"immudb":   "http://immudb-file:8080",
+		"redis":    "http://default-fn:8080",
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("plan has %d invocations, want %d", len(plan), len(want))
+	}
+	for _, planned := range plan {
+		if planned.Endpoint != want[planned.Config.DatabaseType] {
+			t.Errorf("%s endpoint = %s, want %s", planned.Config.DatabaseType, planned.Endpoint, want[planned.Config.DatabaseType])
+		}
+	}
+}
+
+func TestResultStream(t *testing.T) {
+	useOutputDir(t)
+	path := filepath.Join(t.TempDir(), "results.ndjson")
+	openResultStream(path)
+	t.Cleanup(func() {
+		if closer, ok := resultStream.(io.Closer); ok {
+			closer.Close()
+		}
+		resultStream = nil
+	})
+
+	server, _ := benchmarkServer(t, func(config BenchmarkConfig) (int, []byte) {
+		return http.StatusOK, cannedResult(t, config, 100)
+	})
+	var jobs []benchmarkJob
+	for _, op := range []string{"read", "write", "query", "read-parallel", "write-batch", "mixed"} {
+		jobs = append(jobs, benchmarkJob{dbType: "dynamodb", opType: op, endpoint: server.URL})
+	}
+	if errs := runJobs(jobs, 4); len(errs) > 0 {
+		t.Fatalf("runJobs() errors = %v", errs)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != len(jobs) {
+		t.Fatalf("stream has %d lines, want %d:\n%s", len(lines), len(jobs), data)
+	}
+	streamed := make(map[string]bool)
+	for i, line := range lines {
+		var result BenchmarkResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Errorf("line %d is not a JSON result: %v", i+1, err)
+			continue
+		}
+		streamed[result.OperationType] = result.Success
+	}
+	for _, job := range jobs {
+		if !streamed[job.opType] {
+			t.Errorf("no successful %s result streamed", job.opType)
+		}
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	dir := useOutputDir(t)
+	setFlag(t, maxRetries, 0)
+	server, _ := benchmarkServer(t, func(config BenchmarkConfig) (int, []byte) {
+		if config.OperationType == "query" {
+			return http.StatusBadRequest, []byte("unsupported")
+		}
+		return http.StatusOK, cannedResult(t, config, 100)
+	})
+
+	jobs := []benchmarkJob{
+		{dbType: "dynamodb", opType: "read", endpoint: server.URL},
+		{dbType: "dynamodb", opType: "write", endpoint: server.URL},
+		{dbType: "dynamodb", opType: "query", endpoint: server.URL},
+	}
+	start := time.Now()
+	errs := runJobs(jobs, 2)
+	writeRunSummary(len(jobs), errs, start)
+
+	data, err := os.ReadFile(filepath.Join(dir, "run_summary.json"))
+	if err != nil {
+		t.Fatalf("run summary not written: %v", err)
+	}
+	var summary runSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("run summary is not valid JSON: %v", err)
+	}
+
+	if summary.Total != 3 || summary.Succeeded != 2 || summary.Failed != 1 || summary.WallTimeNs <= 0 {
+		t.Errorf("run summary = %+v, want 2 of 3 succeeded", summary)
+	}
+	if len(summary.Results) != len(jobs) {
+		t.Fatalf("run summary has %d results, want %d", len(summary.Results), len(jobs))
+	}
+	for _, entry := range summary.Results {
+		if entry.Success != (entry.OperationType != "query") {
+			t.Errorf("%s success = %v", entry.OperationType, entry.Success)
+		}
+		if entry.Success && (entry.Throughput != 100 || entry.AvgOperationDurationNs != int64(time.Millisecond)) {
+			t.Errorf("%s entry = %+v, want the saved result's throughput and latency", entry.OperationType, entry)
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.File)); entry.File == "" || err != nil {
+			t.Errorf("%s entry file %q does not exist", entry.OperationType, entry.File)
+		}
+	}
+}
+
+func TestPrepareRunDir(t *testing.T) {
+	base := t.TempDir()
+	setFlag(t, dryRun, false)
+	setFlag(t, configFile, "configs/dynamodb_benchmark.json")
+	setFlag(t, iterations, 3)
+
+	tests := []struct {
+		name  string
+		runID string
+	}{
+		{"explicit run id", "nightly"},
+		{"default run id", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, outputDir, base)
+			setFlag(t, runID, tt.runID)
+
+			prepareRunDir("dynamodb_benchmark")
+
+			if tt.runID == "" {
+				if _, err := time.Parse("20060102-150405", *runID); err != nil {
+					t.Errorf("default run id = %q, want a start timestamp", *runID)
+				}
+			} else if *runID != tt.runID {
+				t.Errorf("run id = %q, want %q", *runID, tt.runID)
+			}
+			runDir := filepath.Join(base, *runID)
+			if *outputDir != runDir {
+				t.Fatalf("output directory = %s, want %s", *outputDir, runDir)
+			}
+
+			data, err := os.ReadFile(filepath.Join(runDir, "manifest.json"))
+			if err != nil {
+				t.Fatalf("manifest not written: %v", err)
+			}
+			var manifest runManifest
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				t.Fatalf("manifest is not valid JSON: %v", err)
+			}
+			if manifest.RunID != *runID || manifest.BenchmarkID != "dynamodb_benchmark" ||
+				manifest.ConfigFile != "configs/dynamodb_benchmark.json" || manifest.Flags["iterations"] != "3" {
+				t.Errorf("manifest = %+v", manifest)
+			}
+
+			// Results land in the run directory
+			useOutputDir(t)
+			setFlag(t, outputDir, runDir)
+			saveResult("dynamodb", "read", &BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true})
+			matches, _ := filepath.Glob(filepath.Join(runDir, "dynamodb-read-*.json"))
+			if len(matches) != 1 {
+				t.Errorf("result files in the run directory = %v, want one", matches)
+			}
+		})
+	}
+}
+
+func TestPrepareRunDirDryRun(t *testing.T) {
+	base := t.TempDir()
+	setFlag(t, dryRun, true)
+	setFlag(t, outputDir, base)
+	setFlag(t, runID, "planned")
+
+	prepareRunDir("")
+
+	if _, err := os.Stat(filepath.Join(base, "planned")); !os.IsNotExist(err) {
+		t.Errorf("dry run created the run directory: %v", err)
+	}
+}
+
+// paramsOperation returns the parameters the benchmark function built it with
+type paramsOperation struct {
+	params map[string]interface{}
+}
+
+func (op *paramsOperation) Execute(ctx context.Context, db benchdb.Database, collector *metrics.Collector) (benchops.OperationResult, error) {
+	return benchops.OperationResult{Data: op.params}, nil
+}
+
+func TestCompressedRequestRoundTrip(t *testing.T) {
+	dbtest.Register("runner-test", dbtest.New())
+	handler.RegisterOperation("runner-test-params", func(params map[string]interface{}) benchops.Operation {
+		return &paramsOperation{params: params}
+	})
+
+	config := buildBenchmarkConfig("runner-test", "runner-test-params", map[string]interface{}{
+		"data":   strings.Repeat("payload ", 4096),
+		"nested": map[string]interface{}{"ids": []interface{}{"tx-1", "tx-2"}},
+	})
+	raw, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := compressPayload(raw)
+	if err != nil {
+		t.Fatalf("compressPayload() error = %v", err)
+	}
+	if len(compressed) >= len(raw) {
+		t.Errorf("compressed request is %d bytes, want less than the %d uncompressed", len(compressed), len(raw))
+	}
+
+	// The Lambda runtime decodes the envelope into the handler's request type
+	var request handler.BenchmarkRequest
+	if err := json.Unmarshal(compressed, &request); err != nil {
+		t.Fatalf("compressed request is not a handler request: %v", err)
+	}
+	response, result, err := handler.Run(context.Background(), request)
+	if err != nil || !response.Success {
+		t.Fatalf("Run() = %+v, %v", response, err)
+	}
+
+	// The handler adds the cold-start flag to the parameters it received
+	received := BenchmarkConfig{DatabaseType: response.DatabaseType, OperationType: response.OperationType, Parameters: result.Data}
+	delete(received.Parameters, "isColdStart")
+
+	var want BenchmarkConfig
+	if err := json.Unmarshal(raw, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(received, want) {
+		t.Errorf("handler received %+v, want %+v", received, want)
+	}
+}
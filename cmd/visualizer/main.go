@@ -1,18 +1,26 @@
 package main
 
 import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/HdrHistogram/hdrhistogram-go"
 	"github.com/olekukonko/tablewriter"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/internal/cost"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	chart "github.com/wcharczuk/go-chart/v2"
 	"github.com/wcharczuk/go-chart/v2/drawing"
 )
@@ -48,23 +56,41 @@ type FilterOptions struct {
 
 // OutputOptions for visualization
 type OutputOptions struct {
-	Format     string // text, csv, chart
+	Format     string // text, csv, chart, markdown
 	OutputDir  string
 	GroupBy    string // database, operation
-	MetricType string // throughput, latency
+	MetricType string // throughput, latency, errorrate, cost
+	Pricing    string // on-demand, provisioned
+	Aggregate  string // mean, median, min, max
+	ChartType  string // bar, trend, concurrency
+}
+
+// GroupedValue is a metric aggregated across all results for a group, with the
+// number of results it was computed from
+type GroupedValue struct {
+	Value   float64
+	Samples int
 }
 
 // Command line flags
 var (
 	inputPath  = flag.String("input", "", "Path to benchmark results directory or specific result file")
 	outputPath = flag.String("output", "visualizations", "Directory to store visualization outputs")
-	format     = flag.String("format", "all", "Output format: text, csv, chart, all")
+	format     = flag.String("format", "all", "Output format: text, csv, chart, markdown, all")
 	groupBy    = flag.String("group-by", "database", "Group results by: database, operation")
-	metricType = flag.String("metric", "throughput", "Metric to visualize: throughput, latency")
+	metricType = flag.String("metric", "throughput", "Metric to visualize: throughput, latency, errorrate, cost")
+	pricing    = flag.String("pricing", "on-demand", "DynamoDB pricing mode for the cost metric: on-demand, provisioned")
 	databases  = flag.String("databases", "", "Comma-separated list of databases to include")
 	operations = flag.String("operations", "", "Comma-separated list of operations to include")
 	startDate  = flag.String("start-date", "", "Start date filter (YYYY-MM-DD)")
 	endDate    = flag.String("end-date", "", "End date filter (YYYY-MM-DD)")
+	chartType  = flag.String("chart-type", "bar", "Chart type: bar, trend (metric over time per database/operation; narrow with --databases and --operations), concurrency (throughput per concurrency level of a --concurrency-levels sweep)")
+	baseline   = flag.String("baseline", "", "Baseline results directory to compare against for regression detection")
+	threshold  = flag.Float64("threshold", 10, "Percent change beyond which a throughput drop or latency increase counts as a regression")
+	aggregate  = flag.String("aggregate", "mean", "How to combine repeated results for the same database/operation: mean, median, min, max")
+	strict     = flag.Bool("strict", false, "Validate each result file against the result schema and fail on the first invalid file instead of skipping it")
+	rawCSV     = flag.Bool("raw-csv", false, "Write raw_latencies.csv with the per-operation samples of results collected with includeRawMetrics")
+	mergeHist  = flag.Bool("merge-histograms", false, "Merge the latency histograms of all results for each database/operation and report the combined percentiles")
 )
 
 func main() {
@@ -74,6 +100,16 @@ func main() {
 		log.Fatal("Input path is required. Use --input flag to specify the directory or file.")
 	}
 
+	switch *aggregate {
+	case "mean", "median", "min", "max":
+	default:
+		log.Fatalf("Invalid aggregate %q. Use mean, median, min or max.", *aggregate)
+	}
+
+	if *pricing != "on-demand" && *pricing != "provisioned" {
+		log.Fatalf("Invalid pricing %q. Use on-demand or provisioned.", *pricing)
+	}
+
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(*outputPath, 0755); err != nil {
 		log.Fatalf("Failed to create output directory: %v", err)
@@ -82,8 +118,18 @@ func main() {
 	// Parse filter options
 	filterOpts := parseFilterOptions()
 
+	// Compile the result schema when strict validation is requested
+	var schema *jsonschema.Schema
+	if *strict {
+		var err error
+		schema, err = compileResultSchema()
+		if err != nil {
+			log.Fatalf("Failed to compile result schema: %v", err)
+		}
+	}
+
 	// Load benchmark results
-	resultsCollection, err := loadBenchmarkResults(*inputPath, filterOpts)
+	resultsCollection, err := loadBenchmarkResults(*inputPath, filterOpts, schema)
 	if err != nil {
 		log.Fatalf("Failed to load benchmark results: %v", err)
 	}
@@ -102,6 +148,9 @@ func main() {
 		OutputDir:  *outputPath,
 		GroupBy:    *groupBy,
 		MetricType: *metricType,
+		Pricing:    *pricing,
+		Aggregate:  *aggregate,
+		ChartType:  *chartType,
 	}
 
 	// Generate visualizations
@@ -113,9 +162,34 @@ func main() {
 		generateCSVReport(resultsCollection, outputOpts)
 	}
 
+	if *rawCSV {
+		generateRawLatencyCSV(resultsCollection, outputOpts)
+	}
+
+	if *mergeHist {
+		generateMergedLatencySummary(resultsCollection, outputOpts)
+	}
+
 	if *format == "chart" || *format == "all" {
 		generateCharts(resultsCollection, outputOpts)
 	}
+
+	if *format == "markdown" || *format == "all" {
+		generateMarkdownSummary(resultsCollection, outputOpts, filterOpts)
+	}
+
+	// Compare against a baseline and fail when performance regressed
+	if *baseline != "" {
+		baselineCollection, err := loadBenchmarkResults(*baseline, filterOpts, schema)
+		if err != nil {
+			log.Fatalf("Failed to load baseline results: %v", err)
+		}
+
+		comparisons := compareResults(baselineCollection, resultsCollection, *aggregate, *threshold)
+		if generateRegressionReport(comparisons, outputOpts, *threshold) {
+			os.Exit(1)
+		}
+	}
 }
 
 // parseFilterOptions parses command line flags into filter options
@@ -153,8 +227,9 @@ func parseFilterOptions() FilterOptions {
 	return filterOpts
 }
 
-// loadBenchmarkResults loads benchmark results from a file or directory
-func loadBenchmarkResults(path string, filterOpts FilterOptions) (ResultsCollection, error) {
+// loadBenchmarkResults loads benchmark results from a file or directory. When schema
+// is non-nil every file is validated and the first invalid file is an error.
+func loadBenchmarkResults(path string, filterOpts FilterOptions, schema *jsonschema.Schema) (ResultsCollection, error) {
 	collection := ResultsCollection{
 		Results:        []BenchmarkResult{},
 		DatabaseTypes:  []string{},
@@ -178,8 +253,11 @@ func loadBenchmarkResults(path string, filterOpts FilterOptions) (ResultsCollect
 				return err
 			}
 			if !info.IsDir() && strings.HasSuffix(info.Name(), ".json") {
-				result, err := loadResultFromFile(filePath)
+				result, err := loadResultFromFile(filePath, schema)
 				if err != nil {
+					if schema != nil {
+						return fmt.Errorf("invalid result file %s: %w", filePath, err)
+					}
 					fmt.Printf("Warning: Skipping file %s: %v\n", filePath, err)
 					return nil
 				}
@@ -198,9 +276,9 @@ func loadBenchmarkResults(path string, filterOpts FilterOptions) (ResultsCollect
 		}
 	} else {
 		// Process single file
-		result, err := loadResultFromFile(path)
+		result, err := loadResultFromFile(path, schema)
 		if err != nil {
-			return collection, fmt.Errorf("failed to load result file: %v", err)
+			return collection, fmt.Errorf("failed to load result file %s: %v", path, err)
 		}
 
 		// Apply filters
@@ -226,7 +304,7 @@ func loadBenchmarkResults(path string, filterOpts FilterOptions) (ResultsCollect
 }
 
 // loadResultFromFile loads a benchmark result from a file
-func loadResultFromFile(filePath string) (BenchmarkResult, error) {
+func loadResultFromFile(filePath string, schema *jsonschema.Schema) (BenchmarkResult, error) {
 	var result BenchmarkResult
 
 	file, err := os.Open(filePath)
@@ -240,6 +318,19 @@ func loadResultFromFile(filePath string) (BenchmarkResult, error) {
 		return result, fmt.Errorf("failed to read file: %v", err)
 	}
 
+	if schema != nil {
+		// Decode numbers as json.Number so integer fields are checked precisely
+		var doc interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return result, fmt.Errorf("failed to parse JSON: %v", err)
+		}
+		if err := schema.Validate(doc); err != nil {
+			return result, fmt.Errorf("schema validation failed: %v", err)
+		}
+	}
+
 	if err := json.Unmarshal(data, &result); err != nil {
 		return result, fmt.Errorf("failed to parse JSON: %v", err)
 	}
@@ -247,6 +338,20 @@ func loadResultFromFile(filePath string) (BenchmarkResult, error) {
 	return result, nil
 }
 
+//go:embed result.schema.json
+var resultSchemaJSON string
+
+// compileResultSchema compiles the embedded JSON Schema for result files
+func compileResultSchema() (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+	compiler.AssertFormat = true
+	if err := compiler.AddResource("result.schema.json", strings.NewReader(resultSchemaJSON)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile("result.schema.json")
+}
+
 // shouldIncludeResult checks if a result should be included based on filters
 func shouldIncludeResult(result BenchmarkResult, filterOpts FilterOptions) bool {
 	// Filter by database
@@ -292,7 +397,7 @@ func shouldIncludeResult(result BenchmarkResult, filterOpts FilterOptions) bool
 // generateTextSummary generates a text summary of the benchmark results
 func generateTextSummary(collection ResultsCollection, opts OutputOptions) {
 	// Group results by database or operation
-	groupedResults := groupResults(collection, opts.GroupBy)
+	groupedResults := groupResults(collection, opts)
 
 	table := tablewriter.NewWriter(os.Stdout)
 
@@ -304,27 +409,27 @@ func generateTextSummary(collection ResultsCollection, opts OutputOptions) {
 	if opts.GroupBy == "database" {
 		headers = []string{"Database"}
 		for _, op := range collection.OperationTypes {
-			if opts.MetricType == "throughput" {
-				headers = append(headers, fmt.Sprintf("%s (ops/sec)", op))
-			} else {
-				headers = append(headers, fmt.Sprintf("%s (ms)", op))
-			}
+			headers = append(headers, fmt.Sprintf("%s (%s)", op, metricUnit(opts.MetricType)))
+			headers = append(headers, percentileHeaders(op, opts, fmt.Sprintf(" (%s)", metricUnit(opts.MetricType)))...)
 		}
-		table.SetHeader(headers)
 	} else {
 		headers = []string{"Operation"}
 		for _, db := range collection.DatabaseTypes {
-			if opts.MetricType == "throughput" {
-				headers = append(headers, fmt.Sprintf("%s (ops/sec)", db))
-			} else {
-				headers = append(headers, fmt.Sprintf("%s (ms)", db))
-			}
+			headers = append(headers, fmt.Sprintf("%s (%s)", db, metricUnit(opts.MetricType)))
+			headers = append(headers, percentileHeaders(db, opts, fmt.Sprintf(" (%s)", metricUnit(opts.MetricType)))...)
 		}
-		table.SetHeader(headers)
 	}
+	headers = append(headers, "Errors")
+	table.SetHeader(headers)
+
+	percentiles := groupPercentiles(collection, opts)
+
+	// Failed results are counted per group so they remain visible
+	failures := countFailures(collection, opts.GroupBy)
 
 	// Add rows
-	for groupName, results := range groupedResults {
+	for _, groupName := range groupNames(collection, opts.GroupBy) {
+		results := groupedResults[groupName]
 		row := []string{groupName}
 
 		var sortedKeys []string
@@ -336,17 +441,17 @@ func generateTextSummary(collection ResultsCollection, opts OutputOptions) {
 
 		for _, key := range sortedKeys {
 			if val, ok := results[key]; ok {
-				if opts.MetricType == "throughput" {
-					row = append(row, fmt.Sprintf("%.2f", val))
-				} else {
-					// Convert nanoseconds to milliseconds
-					latencyMs := val / 1000000
-					row = append(row, fmt.Sprintf("%.2f", latencyMs))
+				cell := fmt.Sprintf("%.*f", metricPrecision(opts.MetricType), chartValue(val.Value, opts.MetricType))
+				if val.Samples > 1 {
+					cell += fmt.Sprintf(" (n=%d)", val.Samples)
 				}
+				row = append(row, cell)
 			} else {
 				row = append(row, "N/A")
 			}
+			row = append(row, percentileCells(percentiles[groupName][key], opts)...)
 		}
+		row = append(row, fmt.Sprintf("%d", failures[groupName]))
 
 		table.Append(row)
 		rows = append(rows, row) // Store rows for later use
@@ -377,12 +482,109 @@ func generateTextSummary(collection ResultsCollection, opts OutputOptions) {
 
 	file.WriteString("# Benchmark Results Summary\n\n")
 	file.WriteString(fmt.Sprintf("Grouped by: %s\n", opts.GroupBy))
-	file.WriteString(fmt.Sprintf("Metric: %s\n\n", opts.MetricType))
+	file.WriteString(fmt.Sprintf("Metric: %s\n", opts.MetricType))
+	file.WriteString(fmt.Sprintf("Aggregate: %s\n\n", opts.Aggregate))
 	file.WriteString(tableString.String())
 
 	fmt.Printf("Text summary saved to: %s\n", outputFile)
 }
 
+// generateMarkdownSummary writes summary.md with a front-matter block describing the run
+// and a Markdown table per metric
+func generateMarkdownSummary(collection ResultsCollection, opts OutputOptions, filterOpts FilterOptions) {
+	var sb strings.Builder
+
+	// Front matter with run metadata and applied filters
+	sb.WriteString("---\n")
+	sb.WriteString("title: Benchmark Results Summary\n")
+	sb.WriteString(fmt.Sprintf("generated: %s\n", time.Now().UTC().Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("input: %q\n", *inputPath))
+	sb.WriteString(fmt.Sprintf("results: %d\n", len(collection.Results)))
+	sb.WriteString(fmt.Sprintf("groupBy: %s\n", opts.GroupBy))
+	sb.WriteString(fmt.Sprintf("aggregate: %s\n", opts.Aggregate))
+	sb.WriteString(fmt.Sprintf("databases: [%s]\n", strings.Join(collection.DatabaseTypes, ", ")))
+	sb.WriteString(fmt.Sprintf("operations: [%s]\n", strings.Join(collection.OperationTypes, ", ")))
+	sb.WriteString("filters:\n")
+	sb.WriteString(fmt.Sprintf("  databases: [%s]\n", strings.Join(filterOpts.Databases, ", ")))
+	sb.WriteString(fmt.Sprintf("  operations: [%s]\n", strings.Join(filterOpts.Operations, ", ")))
+	if !filterOpts.StartTime.IsZero() {
+		sb.WriteString(fmt.Sprintf("  startDate: %s\n", filterOpts.StartTime.Format("2006-01-02")))
+	}
+	if !filterOpts.EndTime.IsZero() {
+		sb.WriteString(fmt.Sprintf("  endDate: %s\n", filterOpts.EndTime.Format("2006-01-02")))
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString("# Benchmark Results Summary\n")
+
+	for _, metric := range []string{"throughput", "latency"} {
+		metricOpts := opts
+		metricOpts.MetricType = metric
+		groupedResults := groupResults(collection, metricOpts)
+
+		unit := "ops/sec"
+		if metric == "latency" {
+			unit = "ms"
+		}
+
+		// Columns are the keys of the non-grouped dimension
+		headers := []string{"Database"}
+		columns := collection.OperationTypes
+		groups := collection.DatabaseTypes
+		if opts.GroupBy != "database" {
+			headers = []string{"Operation"}
+			columns = collection.DatabaseTypes
+			groups = collection.OperationTypes
+		}
+		for _, column := range columns {
+			headers = append(headers, fmt.Sprintf("%s (%s)", column, unit))
+		}
+
+		var tableString strings.Builder
+		table := tablewriter.NewWriter(&tableString)
+		table.SetHeader(headers)
+		table.SetAutoFormatHeaders(false)
+		table.SetAutoWrapText(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: false, Right: true, Bottom: false})
+		table.SetCenterSeparator("|")
+
+		// Left-align the group name and right-align numeric columns
+		alignments := []int{tablewriter.ALIGN_LEFT}
+		for range columns {
+			alignments = append(alignments, tablewriter.ALIGN_RIGHT)
+		}
+		table.SetColumnAlignment(alignments)
+
+		for _, group := range groups {
+			values, ok := groupedResults[group]
+			if !ok {
+				continue
+			}
+
+			row := []string{group}
+			for _, column := range columns {
+				if val, ok := values[column]; ok {
+					row = append(row, fmt.Sprintf("%.2f", chartValue(val.Value, metric)))
+				} else {
+					row = append(row, "N/A")
+				}
+			}
+			table.Append(row)
+		}
+		table.Render()
+
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", strings.Title(metric)))
+		sb.WriteString(tableString.String())
+	}
+
+	outputFile := filepath.Join(opts.OutputDir, "summary.md")
+	if err := os.WriteFile(outputFile, []byte(sb.String()), 0644); err != nil {
+		fmt.Printf("Warning: Failed to write Markdown summary: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Markdown summary saved to: %s\n", outputFile)
+}
+
 // generateCSVReport generates a CSV report of the benchmark results
 func generateCSVReport(collection ResultsCollection, opts OutputOptions) {
 	outputFile := filepath.Join(opts.OutputDir, fmt.Sprintf("benchmark_results_%s_%s.csv", opts.GroupBy, opts.MetricType))
@@ -394,7 +596,7 @@ func generateCSVReport(collection ResultsCollection, opts OutputOptions) {
 	defer file.Close()
 
 	// Group results by database or operation
-	groupedResults := groupResults(collection, opts.GroupBy)
+	groupedResults := groupResults(collection, opts)
 
 	// Write CSV header
 	var header string
@@ -402,17 +604,28 @@ func generateCSVReport(collection ResultsCollection, opts OutputOptions) {
 		header = "Database"
 		for _, op := range collection.OperationTypes {
 			header += fmt.Sprintf(",%s", op)
+			for _, h := range percentileHeaders(op, opts, "") {
+				header += "," + h
+			}
 		}
 	} else {
 		header = "Operation"
 		for _, db := range collection.DatabaseTypes {
 			header += fmt.Sprintf(",%s", db)
+			for _, h := range percentileHeaders(db, opts, "") {
+				header += "," + h
+			}
 		}
 	}
+	header += ",Errors"
 	file.WriteString(header + "\n")
 
+	failures := countFailures(collection, opts.GroupBy)
+	percentiles := groupPercentiles(collection, opts)
+
 	// Write CSV rows
-	for groupName, results := range groupedResults {
+	for _, groupName := range groupNames(collection, opts.GroupBy) {
+		results := groupedResults[groupName]
 		row := groupName
 
 		var sortedKeys []string
@@ -424,17 +637,15 @@ func generateCSVReport(collection ResultsCollection, opts OutputOptions) {
 
 		for _, key := range sortedKeys {
 			if val, ok := results[key]; ok {
-				if opts.MetricType == "throughput" {
-					row += fmt.Sprintf(",%.2f", val)
-				} else {
-					// Convert nanoseconds to milliseconds
-					latencyMs := val / 1000000
-					row += fmt.Sprintf(",%.2f", latencyMs)
-				}
+				row += fmt.Sprintf(",%.*f", metricPrecision(opts.MetricType), chartValue(val.Value, opts.MetricType))
 			} else {
 				row += ",N/A"
 			}
+			for _, cell := range percentileCells(percentiles[groupName][key], opts) {
+				row += "," + cell
+			}
 		}
+		row += fmt.Sprintf(",%d", failures[groupName])
 
 		file.WriteString(row + "\n")
 	}
@@ -442,8 +653,183 @@ func generateCSVReport(collection ResultsCollection, opts OutputOptions) {
 	fmt.Printf("CSV report saved to: %s\n", outputFile)
 }
 
+// generateRawLatencyCSV writes one row per raw operation sample found in the results'
+// metrics.operations arrays. Results without raw samples are skipped.
+func generateRawLatencyCSV(collection ResultsCollection, opts OutputOptions) {
+	var rows [][]string
+	for _, result := range collection.Results {
+		ops, ok := result.Metrics["operations"].([]interface{})
+		if !ok {
+			continue
+		}
+		for i, entry := range ops {
+			op, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			durationNs, _ := metricFloat(op, "durationNs")
+			itemCount, _ := metricFloat(op, "itemCount")
+			byteCount, _ := metricFloat(op, "byteCount")
+			isColdStart, _ := op["isColdStart"].(bool)
+			errorMessage, _ := op["errorMessage"].(string)
+
+			rows = append(rows, []string{
+				result.DatabaseType,
+				result.OperationType,
+				strconv.Itoa(i),
+				strconv.FormatInt(int64(durationNs), 10),
+				strconv.FormatInt(int64(itemCount), 10),
+				strconv.FormatInt(int64(byteCount), 10),
+				strconv.FormatBool(isColdStart),
+				errorMessage,
+			})
+		}
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("No raw operation metrics found; skipping raw latency CSV. Run benchmarks with includeRawMetrics to collect them.")
+		return
+	}
+
+	outputFile := filepath.Join(opts.OutputDir, "raw_latencies.csv")
+	file, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create raw latency CSV file: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	// Error messages may contain commas and quotes, so rows are written with encoding/csv
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"database", "operation", "index", "durationNs", "itemCount", "byteCount", "isColdStart", "error"})
+	writer.WriteAll(rows)
+	if err := writer.Error(); err != nil {
+		fmt.Printf("Warning: Failed to write raw latency CSV file: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Raw latency CSV (%d samples) saved to: %s\n", len(rows), outputFile)
+}
+
+// mergedPercentiles are the percentiles reported from merged latency histograms
+var mergedPercentiles = []float64{50, 95, 99}
+
+// mergeLatencyHistograms decodes and merges the encoded HDR histograms in the
+// metrics.latencyHistogram entries of the given results. It returns the merged
+// histogram, nil if none of the results carry one, and the number of results merged.
+func mergeLatencyHistograms(results []BenchmarkResult) (*hdrhistogram.Histogram, int, error) {
+	var merged *hdrhistogram.Histogram
+	files := 0
+	for _, result := range results {
+		byType, ok := result.Metrics["latencyHistogram"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		found := false
+		for opType, entry := range byType {
+			fields, _ := entry.(map[string]interface{})
+			encoded, ok := fields["encoded"].(string)
+			if !ok {
+				continue
+			}
+			h, err := hdrhistogram.Decode([]byte(encoded))
+			if err != nil {
+				return nil, 0, fmt.Errorf("failed to decode %s histogram of %s/%s: %w", opType, result.DatabaseType, result.OperationType, err)
+			}
+			if merged == nil {
+				merged = h
+			} else if dropped := merged.Merge(h); dropped > 0 {
+				return nil, 0, fmt.Errorf("%d values of %s/%s fell outside the histogram range", dropped, result.DatabaseType, result.OperationType)
+			}
+			found = true
+		}
+		if found {
+			files++
+		}
+	}
+
+	return merged, files, nil
+}
+
+// generateMergedLatencySummary reports, for each database and operation, the latency
+// percentiles of the merged histograms of all its results. Unlike aggregating each
+// file's percentiles, this gives the percentiles of the combined distribution.
+func generateMergedLatencySummary(collection ResultsCollection, opts OutputOptions) {
+	headers := []string{"Database", "Operation", "Files", "Samples"}
+	for _, p := range mergedPercentiles {
+		headers = append(headers, fmt.Sprintf("p%v (ms)", p))
+	}
+
+	var rows [][]string
+	for _, dbType := range collection.DatabaseTypes {
+		for _, opType := range collection.OperationTypes {
+			var results []BenchmarkResult
+			for _, result := range collection.Results {
+				if result.DatabaseType == dbType && result.OperationType == opType {
+					results = append(results, result)
+				}
+			}
+
+			merged, files, err := mergeLatencyHistograms(results)
+			if err != nil {
+				fmt.Printf("Warning: %v\n", err)
+				continue
+			}
+			if merged == nil {
+				continue
+			}
+
+			row := []string{dbType, opType, strconv.Itoa(files), strconv.FormatInt(merged.TotalCount(), 10)}
+			for _, p := range mergedPercentiles {
+				row = append(row, fmt.Sprintf("%.2f", float64(merged.ValueAtPercentile(p))/1000000))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("No latency histograms found; skipping merged latency summary.")
+		return
+	}
+
+	var tableString strings.Builder
+	table := tablewriter.NewWriter(&tableString)
+	table.SetHeader(headers)
+	table.AppendBulk(rows)
+	table.Render()
+	fmt.Print(tableString.String())
+
+	outputFile := filepath.Join(opts.OutputDir, "merged_latency.txt")
+	content := "# Merged Latency Percentiles\n\n" + tableString.String()
+	if err := os.WriteFile(outputFile, []byte(content), 0644); err != nil {
+		fmt.Printf("Warning: Failed to write merged latency summary: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Merged latency summary saved to: %s\n", outputFile)
+}
+
 // generateCharts generates charts of the benchmark results
 func generateCharts(collection ResultsCollection, opts OutputOptions) {
+	if opts.ChartType == "trend" {
+		for _, dbType := range collection.DatabaseTypes {
+			for _, opType := range collection.OperationTypes {
+				generateTrendChart(collection, dbType, opType, opts)
+			}
+		}
+		return
+	}
+
+	if opts.ChartType == "concurrency" {
+		for _, dbType := range collection.DatabaseTypes {
+			for _, opType := range collection.OperationTypes {
+				generateConcurrencyChart(collection, dbType, opType, opts)
+			}
+		}
+		return
+	}
+
 	if opts.GroupBy == "database" {
 		// Generate one chart per database comparing operations
 		for _, dbType := range collection.DatabaseTypes {
@@ -458,39 +844,299 @@ func generateCharts(collection ResultsCollection, opts OutputOptions) {
 			generateOperationChart(collection, opType, opts)
 		}
 	}
+
+	// Cold start cost is independent of grouping and metric
+	generateColdStartChart(collection, opts)
 }
 
-// generateDatabaseChart generates a chart for a specific database
-func generateDatabaseChart(collection ResultsCollection, dbType string, opts OutputOptions) {
-	// Filter results for this database
-	var dbResults []BenchmarkResult
+// generateTrendChart plots a metric over time for one database and operation
+func generateTrendChart(collection ResultsCollection, dbType, opType string, opts OutputOptions) {
+	var points []BenchmarkResult
 	for _, result := range collection.Results {
-		if result.DatabaseType == dbType {
-			dbResults = append(dbResults, result)
+		if result.Success && result.DatabaseType == dbType && result.OperationType == opType {
+			points = append(points, result)
 		}
 	}
 
-	if len(dbResults) == 0 {
+	// A line needs at least two points
+	if len(points) < 2 {
 		return
 	}
 
-	// Group results by operation
-	opData := make(map[string]float64)
-	for _, result := range dbResults {
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
+
+	series := chart.TimeSeries{
+		Name:    fmt.Sprintf("%s %s", dbType, opType),
+		XValues: make([]time.Time, len(points)),
+		YValues: make([]float64, len(points)),
+		Style: chart.Style{
+			StrokeColor: drawing.Color{R: 77, G: 184, B: 255, A: 255},
+			StrokeWidth: 2,
+			DotWidth:    4,
+		},
+	}
+	for i, result := range points {
+		series.XValues[i] = result.Timestamp
 		if opts.MetricType == "throughput" {
-			opData[result.OperationType] = result.Throughput
+			series.YValues[i] = result.Throughput
 		} else {
-			// Convert nanoseconds to milliseconds
-			opData[result.OperationType] = float64(result.AvgOperationDurationNs) / 1000000
+			series.YValues[i] = chartValue(float64(result.AvgOperationDurationNs), opts.MetricType)
+		}
+	}
+
+	unit := "ops/sec"
+	if opts.MetricType == "latency" {
+		unit = "ms"
+	}
+
+	graph := chart.Chart{
+		Title: fmt.Sprintf("%s %s - %s over Time", dbType, opType, strings.Title(opts.MetricType)),
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:    40,
+				Left:   20,
+				Right:  20,
+				Bottom: 20,
+			},
+		},
+		Width:  1000,
+		Height: 400,
+		XAxis: chart.XAxis{
+			ValueFormatter: chart.TimeValueFormatterWithFormat("2006-01-02 15:04"),
+		},
+		YAxis: chart.YAxis{
+			ValueFormatter: func(v interface{}) string {
+				if vf, isFloat := v.(float64); isFloat {
+					return fmt.Sprintf("%.2f %s", vf, unit)
+				}
+				return ""
+			},
+		},
+		Series: []chart.Series{series},
+	}
+
+	// Save chart to file
+	outputFile := filepath.Join(opts.OutputDir, fmt.Sprintf("%s_%s_%s_trend.png", dbType, opType, opts.MetricType))
+	f, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create trend chart file: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if err := graph.Render(chart.PNG, f); err != nil {
+		fmt.Printf("Warning: Failed to render trend chart: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Trend chart for %s %s saved to: %s\n", dbType, opType, outputFile)
+}
+
+// concurrencyPoints returns the aggregated throughput of one database and operation per
+// concurrency level recorded in the result metrics, sorted by concurrency
+func concurrencyPoints(collection ResultsCollection, dbType, opType, aggregate string) (levels, throughputs []float64) {
+	samples := make(map[float64][]float64)
+	for _, result := range collection.Results {
+		if !result.Success || result.DatabaseType != dbType || result.OperationType != opType {
+			continue
+		}
+		if level, ok := metricFloat(result.Metrics, "concurrency"); ok {
+			samples[level] = append(samples[level], result.Throughput)
+		}
+	}
+
+	for level := range samples {
+		levels = append(levels, level)
+	}
+	sort.Float64s(levels)
+
+	for _, level := range levels {
+		throughputs = append(throughputs, aggregateValues(samples[level], aggregate))
+	}
+	return levels, throughputs
+}
+
+// generateConcurrencyChart plots throughput against concurrency level for one database and operation
+func generateConcurrencyChart(collection ResultsCollection, dbType, opType string, opts OutputOptions) {
+	levels, throughputs := concurrencyPoints(collection, dbType, opType, opts.Aggregate)
+
+	// A line needs at least two concurrency levels
+	if len(levels) < 2 {
+		return
+	}
+
+	series := chart.ContinuousSeries{
+		Name:    fmt.Sprintf("%s %s", dbType, opType),
+		XValues: levels,
+		YValues: throughputs,
+		Style: chart.Style{
+			StrokeColor: drawing.Color{R: 77, G: 184, B: 255, A: 255},
+			StrokeWidth: 2,
+			DotWidth:    4,
+		},
+	}
+
+	graph := chart.Chart{
+		Title: fmt.Sprintf("%s %s - Throughput by Concurrency", dbType, opType),
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:    40,
+				Left:   20,
+				Right:  20,
+				Bottom: 20,
+			},
+		},
+		Width:  1000,
+		Height: 400,
+		XAxis: chart.XAxis{
+			Name: "Concurrency",
+			ValueFormatter: func(v interface{}) string {
+				if vf, isFloat := v.(float64); isFloat {
+					return fmt.Sprintf("%.0f", vf)
+				}
+				return ""
+			},
+		},
+		YAxis: chart.YAxis{
+			ValueFormatter: func(v interface{}) string {
+				if vf, isFloat := v.(float64); isFloat {
+					return fmt.Sprintf("%.2f ops/sec", vf)
+				}
+				return ""
+			},
+		},
+		Series: []chart.Series{series},
+	}
+
+	// Save chart to file
+	outputFile := filepath.Join(opts.OutputDir, fmt.Sprintf("%s_%s_concurrency.png", dbType, opType))
+	f, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create concurrency chart file: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if err := graph.Render(chart.PNG, f); err != nil {
+		fmt.Printf("Warning: Failed to render concurrency chart: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Concurrency chart for %s %s (%d levels) saved to: %s\n", dbType, opType, len(levels), outputFile)
+}
+
+// generateColdStartChart compares average cold-start and warm latency per database
+func generateColdStartChart(collection ResultsCollection, opts OutputOptions) {
+	coldSamples := make(map[string][]float64)
+	warmSamples := make(map[string][]float64)
+
+	for _, result := range collection.Results {
+		if !result.Success {
+			continue
+		}
+		if v, ok := metricFloat(result.Metrics, "coldStartAvgNs"); ok {
+			coldSamples[result.DatabaseType] = append(coldSamples[result.DatabaseType], v)
+		}
+		if v, ok := metricFloat(result.Metrics, "warmAvgNs"); ok {
+			warmSamples[result.DatabaseType] = append(warmSamples[result.DatabaseType], v)
+		}
+	}
+
+	if len(coldSamples) == 0 && len(warmSamples) == 0 {
+		return
+	}
+
+	coldColor := drawing.Color{R: 250, G: 134, B: 94, A: 255} // Orange
+	warmColor := drawing.Color{R: 77, G: 184, B: 255, A: 255} // Blue
+
+	// Place the cold and warm bars for each database next to each other
+	var bars []chart.Value
+	for _, dbType := range collection.DatabaseTypes {
+		if values, ok := coldSamples[dbType]; ok {
+			bars = append(bars, chart.Value{
+				Label: fmt.Sprintf("%s cold", dbType),
+				Value: aggregateValues(values, opts.Aggregate) / 1000000,
+				Style: chart.Style{FillColor: coldColor, StrokeColor: coldColor},
+			})
+		}
+		if values, ok := warmSamples[dbType]; ok {
+			bars = append(bars, chart.Value{
+				Label: fmt.Sprintf("%s warm", dbType),
+				Value: aggregateValues(values, opts.Aggregate) / 1000000,
+				Style: chart.Style{FillColor: warmColor, StrokeColor: warmColor},
+			})
 		}
 	}
 
+	barChart := chart.BarChart{
+		Title: "Cold Start vs Warm Latency by Database",
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:    40,
+				Left:   20,
+				Right:  20,
+				Bottom: 20,
+			},
+		},
+		Width:  1000,
+		Height: 400,
+		Bars:   bars,
+	}
+	barChart.YAxis.Range = barRange(bars)
+	barChart.YAxis.ValueFormatter = func(v interface{}) string {
+		if vf, isFloat := v.(float64); isFloat {
+			return fmt.Sprintf("%.2f ms", vf)
+		}
+		return ""
+	}
+
+	// Save chart to file
+	outputFile := filepath.Join(opts.OutputDir, "cold_start_comparison_chart.png")
+	f, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create cold start chart file: %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	if err := barChart.Render(chart.PNG, f); err != nil {
+		fmt.Printf("Warning: Failed to render cold start chart: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Cold start comparison chart saved to: %s\n", outputFile)
+}
+
+// metricFloat reads a numeric value from a result's metrics map
+func metricFloat(metrics map[string]interface{}, key string) (float64, bool) {
+	switch v := metrics[key].(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// generateDatabaseChart generates a chart for a specific database
+func generateDatabaseChart(collection ResultsCollection, dbType string, opts OutputOptions) {
+	// Aggregate results for this database by operation
+	opData := groupResults(collection, opts)[dbType]
+	if len(opData) == 0 {
+		return
+	}
+
 	// Create bar chart
 	var bars []chart.Value
 	for op, value := range opData {
 		bars = append(bars, chart.Value{
 			Label: op,
-			Value: value,
+			Value: chartValue(value.Value, opts.MetricType),
 		})
 	}
 
@@ -514,22 +1160,16 @@ func generateDatabaseChart(collection ResultsCollection, dbType string, opts Out
 		Height: 400,
 		Bars:   bars,
 	}
+	barChart.YAxis.Range = barRange(bars)
 
 	// Set formatting on y-axis
-	if opts.MetricType == "latency" {
-		barChart.YAxis.ValueFormatter = func(v interface{}) string {
-			if vf, isFloat := v.(float64); isFloat {
-				return fmt.Sprintf("%.2f ms", vf)
-			}
-			return ""
-		}
-	} else {
-		barChart.YAxis.ValueFormatter = func(v interface{}) string {
-			if vf, isFloat := v.(float64); isFloat {
-				return fmt.Sprintf("%.2f ops/sec", vf)
-			}
-			return ""
+	unit := metricUnit(opts.MetricType)
+	precision := metricPrecision(opts.MetricType)
+	barChart.YAxis.ValueFormatter = func(v interface{}) string {
+		if vf, isFloat := v.(float64); isFloat {
+			return fmt.Sprintf("%.*f %s", precision, vf, unit)
 		}
+		return ""
 	}
 
 	// Save chart to file
@@ -551,35 +1191,18 @@ func generateDatabaseChart(collection ResultsCollection, dbType string, opts Out
 
 // generateOperationChart generates a chart for a specific operation
 func generateOperationChart(collection ResultsCollection, opType string, opts OutputOptions) {
-	// Filter results for this operation
-	var opResults []BenchmarkResult
-	for _, result := range collection.Results {
-		if result.OperationType == opType {
-			opResults = append(opResults, result)
-		}
-	}
-
-	if len(opResults) == 0 {
+	// Aggregate results for this operation by database
+	dbData := groupResults(collection, opts)[opType]
+	if len(dbData) == 0 {
 		return
 	}
 
-	// Group results by database
-	dbData := make(map[string]float64)
-	for _, result := range opResults {
-		if opts.MetricType == "throughput" {
-			dbData[result.DatabaseType] = result.Throughput
-		} else {
-			// Convert nanoseconds to milliseconds
-			dbData[result.DatabaseType] = float64(result.AvgOperationDurationNs) / 1000000
-		}
-	}
-
 	// Create bar chart
 	var bars []chart.Value
 	for db, value := range dbData {
 		bars = append(bars, chart.Value{
 			Label: db,
-			Value: value,
+			Value: chartValue(value.Value, opts.MetricType),
 		})
 	}
 
@@ -603,22 +1226,16 @@ func generateOperationChart(collection ResultsCollection, opType string, opts Ou
 		Height: 400,
 		Bars:   bars,
 	}
+	barChart.YAxis.Range = barRange(bars)
 
 	// Set formatting on y-axis
-	if opts.MetricType == "latency" {
-		barChart.YAxis.ValueFormatter = func(v interface{}) string {
-			if vf, isFloat := v.(float64); isFloat {
-				return fmt.Sprintf("%.2f ms", vf)
-			}
-			return ""
-		}
-	} else {
-		barChart.YAxis.ValueFormatter = func(v interface{}) string {
-			if vf, isFloat := v.(float64); isFloat {
-				return fmt.Sprintf("%.2f ops/sec", vf)
-			}
-			return ""
+	unit := metricUnit(opts.MetricType)
+	precision := metricPrecision(opts.MetricType)
+	barChart.YAxis.ValueFormatter = func(v interface{}) string {
+		if vf, isFloat := v.(float64); isFloat {
+			return fmt.Sprintf("%.*f %s", precision, vf, unit)
 		}
+		return ""
 	}
 
 	// Save chart to file
@@ -640,22 +1257,70 @@ func generateOperationChart(collection ResultsCollection, opType string, opts Ou
 
 // generateComparisonChart generates a comparison chart across all databases
 func generateComparisonChart(collection ResultsCollection, opts OutputOptions) {
-	// Only generate for throughput
-	if opts.MetricType != "throughput" {
-		return
+	series := comparisonSeries(collection, opts)
+
+	// Output file and labels depend on the metric
+	outputFile := filepath.Join(opts.OutputDir, "database_comparison_chart.png")
+	title := "Database Performance Comparison - Throughput (ops/sec)"
+	unit := metricUnit(opts.MetricType)
+	switch opts.MetricType {
+	case "latency":
+		outputFile = filepath.Join(opts.OutputDir, "database_comparison_latency_chart.png")
+		title = "Database Performance Comparison - Latency (ms)"
+	case "errorrate":
+		outputFile = filepath.Join(opts.OutputDir, "database_comparison_errorrate_chart.png")
+		title = "Database Performance Comparison - Error Rate (%)"
+	case "cost":
+		outputFile = filepath.Join(opts.OutputDir, "database_comparison_cost_chart.png")
+		title = "Database Performance Comparison - Estimated Cost (USD)"
 	}
 
-	// Group by database and operation
-	dbOpData := make(map[string]map[string]float64)
+	f, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create comparison chart file: %v\n", err)
+		return
+	}
+	defer f.Close()
 
-	for _, result := range collection.Results {
-		if _, ok := dbOpData[result.DatabaseType]; !ok {
-			dbOpData[result.DatabaseType] = make(map[string]float64)
-		}
+	// Create a legend
+	graph := chart.Chart{
+		Title: title,
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:    50,
+				Left:   20,
+				Right:  20,
+				Bottom: 30,
+			},
+		},
+		Width:  1000,
+		Height: 500,
+		YAxis: chart.YAxis{
+			ValueFormatter: func(v interface{}) string {
+				if vf, isFloat := v.(float64); isFloat {
+					return fmt.Sprintf("%.*f %s", metricPrecision(opts.MetricType), vf, unit)
+				}
+				return ""
+			},
+		},
+		Series: series,
+	}
 
-		dbOpData[result.DatabaseType][result.OperationType] = result.Throughput
+	// Render chart
+	if err := graph.Render(chart.PNG, f); err != nil {
+		fmt.Printf("Warning: Failed to render comparison chart: %v\n", err)
+		return
 	}
 
+	fmt.Printf("Database comparison chart saved to: %s\n", outputFile)
+}
+
+// comparisonSeries returns one series per database, in database order, holding the
+// grouped metric of each of its operations in chart units
+func comparisonSeries(collection ResultsCollection, opts OutputOptions) []chart.Series {
+	// Group by database and operation
+	dbOpData := groupResults(collection, opts)
+
 	// Generate multi-series bar chart with go-chart
 	series := []chart.Series{}
 
@@ -680,7 +1345,7 @@ func generateComparisonChart(collection ResultsCollection, opts OutputOptions) {
 			if value, ok := dbOpData[dbType][opType]; ok {
 				bars = append(bars, chart.Value{
 					Label: opType,
-					Value: value,
+					Value: chartValue(value.Value, opts.MetricType),
 					Style: chart.Style{
 						FillColor:   colors[colorIndex],
 						StrokeColor: colors[colorIndex].WithAlpha(255),
@@ -701,77 +1366,367 @@ func generateComparisonChart(collection ResultsCollection, opts OutputOptions) {
 		colorIndex++
 	}
 
-	// Output file
-	outputFile := filepath.Join(opts.OutputDir, "database_comparison_chart.png")
-	f, err := os.Create(outputFile)
-	if err != nil {
-		fmt.Printf("Warning: Failed to create comparison chart file: %v\n", err)
-		return
+	return series
+}
+
+// groupResults groups benchmark results by database or operation, combining
+// repeated results for the same database/operation with the configured aggregate.
+// Latency values are in nanoseconds and cost in USD. Failed results only contribute to the error rate.
+func groupResults(collection ResultsCollection, opts OutputOptions) map[string]map[string]GroupedValue {
+	samples := make(map[string]map[string][]float64)
+
+	for _, result := range collection.Results {
+		if !result.Success && opts.MetricType != "errorrate" {
+			continue
+		}
+
+		group, key := result.DatabaseType, result.OperationType
+		if opts.GroupBy != "database" {
+			group, key = result.OperationType, result.DatabaseType
+		}
+
+		var value float64
+		switch opts.MetricType {
+		case "throughput":
+			value = result.Throughput
+		case "errorrate":
+			value = errorRate(result)
+		case "cost":
+			// Only results that report consumed capacity can be priced
+			estimate, err := cost.EstimateDynamoDBCost(result.Metrics, opts.Pricing != "provisioned")
+			if err != nil {
+				continue
+			}
+			value = estimate
+		default:
+			value = float64(result.AvgOperationDurationNs)
+		}
+
+		if _, ok := samples[group]; !ok {
+			samples[group] = make(map[string][]float64)
+		}
+		samples[group][key] = append(samples[group][key], value)
 	}
-	defer f.Close()
 
-	// Create a legend
-	graph := chart.Chart{
-		Title: "Database Performance Comparison - Throughput (ops/sec)",
-		Background: chart.Style{
-			Padding: chart.Box{
-				Top:    50,
-				Left:   20,
-				Right:  20,
-				Bottom: 30,
-			},
-		},
-		Width:  1000,
-		Height: 500,
-		Series: series,
+	groupedResults := make(map[string]map[string]GroupedValue)
+	for group, values := range samples {
+		groupedResults[group] = make(map[string]GroupedValue)
+		for key, vals := range values {
+			groupedResults[group][key] = GroupedValue{
+				Value:   aggregateValues(vals, opts.Aggregate),
+				Samples: len(vals),
+			}
+		}
 	}
 
-	// Render chart
-	if err := graph.Render(chart.PNG, f); err != nil {
-		fmt.Printf("Warning: Failed to render comparison chart: %v\n", err)
-		return
+	return groupedResults
+}
+
+// summaryPercentiles are the latency percentiles shown next to each latency cell of the
+// text and CSV summaries
+var summaryPercentiles = []string{"p50", "p90", "p99"}
+
+// percentileHeaders returns the percentile column headers for key, e.g. "read p99 (ms)",
+// or nothing unless the metric is latency
+func percentileHeaders(key string, opts OutputOptions, suffix string) []string {
+	if opts.MetricType != "latency" {
+		return nil
 	}
+	headers := make([]string, 0, len(summaryPercentiles))
+	for _, p := range summaryPercentiles {
+		headers = append(headers, fmt.Sprintf("%s %s%s", key, p, suffix))
+	}
+	return headers
+}
 
-	fmt.Printf("Database comparison chart saved to: %s\n", outputFile)
+// percentileCells formats the grouped percentiles of one cell in milliseconds, with N/A
+// for percentiles none of its results reported
+func percentileCells(values map[string]GroupedValue, opts OutputOptions) []string {
+	if opts.MetricType != "latency" {
+		return nil
+	}
+	cells := make([]string, 0, len(summaryPercentiles))
+	for _, p := range summaryPercentiles {
+		if val, ok := values[p]; ok {
+			cells = append(cells, fmt.Sprintf("%.*f", metricPrecision(opts.MetricType), chartValue(val.Value, opts.MetricType)))
+		} else {
+			cells = append(cells, "N/A")
+		}
+	}
+	return cells
 }
 
-// groupResults groups benchmark results by database or operation
-func groupResults(collection ResultsCollection, groupBy string) map[string]map[string]float64 {
-	groupedResults := make(map[string]map[string]float64)
+// groupPercentiles groups the p50, p90 and p99 latencies of successful results like
+// groupResults, keyed by group, key and percentile. Values are in nanoseconds.
+func groupPercentiles(collection ResultsCollection, opts OutputOptions) map[string]map[string]map[string]GroupedValue {
+	grouped := make(map[string]map[string]map[string]GroupedValue)
+	if opts.MetricType != "latency" {
+		return grouped
+	}
 
-	if groupBy == "database" {
-		// Group by database type
-		for _, result := range collection.Results {
-			if result.Success {
-				if _, ok := groupedResults[result.DatabaseType]; !ok {
-					groupedResults[result.DatabaseType] = make(map[string]float64)
-				}
+	samples := make(map[string]map[string]map[string][]float64)
+	for _, result := range collection.Results {
+		if !result.Success {
+			continue
+		}
 
-				if *metricType == "throughput" {
-					groupedResults[result.DatabaseType][result.OperationType] = result.Throughput
-				} else {
-					groupedResults[result.DatabaseType][result.OperationType] = float64(result.AvgOperationDurationNs)
+		group, key := result.DatabaseType, result.OperationType
+		if opts.GroupBy != "database" {
+			group, key = result.OperationType, result.DatabaseType
+		}
+
+		for _, p := range summaryPercentiles {
+			value, ok := metricFloat(result.Metrics, p)
+			if !ok {
+				continue
+			}
+			if _, ok := samples[group]; !ok {
+				samples[group] = make(map[string]map[string][]float64)
+			}
+			if _, ok := samples[group][key]; !ok {
+				samples[group][key] = make(map[string][]float64)
+			}
+			samples[group][key][p] = append(samples[group][key][p], value)
+		}
+	}
+
+	for group, keys := range samples {
+		grouped[group] = make(map[string]map[string]GroupedValue)
+		for key, byPercentile := range keys {
+			grouped[group][key] = make(map[string]GroupedValue)
+			for p, vals := range byPercentile {
+				grouped[group][key][p] = GroupedValue{
+					Value:   aggregateValues(vals, opts.Aggregate),
+					Samples: len(vals),
 				}
 			}
 		}
-	} else {
-		// Group by operation type
-		for _, result := range collection.Results {
-			if result.Success {
-				if _, ok := groupedResults[result.OperationType]; !ok {
-					groupedResults[result.OperationType] = make(map[string]float64)
+	}
+
+	return grouped
+}
+
+// aggregateValues combines values using mean, median, min or max; it defaults to mean
+func aggregateValues(values []float64, method string) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	switch method {
+	case "min":
+		return sorted[0]
+	case "max":
+		return sorted[len(sorted)-1]
+	case "median":
+		mid := len(sorted) / 2
+		if len(sorted)%2 == 0 {
+			return (sorted[mid-1] + sorted[mid]) / 2
+		}
+		return sorted[mid]
+	default:
+		var sum float64
+		for _, v := range sorted {
+			sum += v
+		}
+		return sum / float64(len(sorted))
+	}
+}
+
+// chartValue converts a grouped value to chart units, using milliseconds for latency
+func chartValue(value float64, metric string) float64 {
+	if metric == "latency" {
+		return value / 1000000
+	}
+	return value
+}
+
+// metricUnit returns the display unit for a metric
+func metricUnit(metric string) string {
+	switch metric {
+	case "latency":
+		return "ms"
+	case "errorrate":
+		return "%"
+	case "cost":
+		return "USD"
+	default:
+		return "ops/sec"
+	}
+}
+
+// metricPrecision returns the number of decimals shown for a metric; costs are fractions of a cent
+func metricPrecision(metric string) int {
+	if metric == "cost" {
+		return 6
+	}
+	return 2
+}
+
+// errorRate returns the percentage of failed operations in a result. It prefers the
+// collector's successRate, falls back to errorCount/operationCount, and treats a
+// failed invocation as 100% errors.
+func errorRate(result BenchmarkResult) float64 {
+	if !result.Success {
+		return 100
+	}
+	if successRate, ok := metricFloat(result.Metrics, "successRate"); ok {
+		return (1 - successRate) * 100
+	}
+	errorCount, hasErrors := metricFloat(result.Metrics, "errorCount")
+	opCount, hasOps := metricFloat(result.Metrics, "operationCount")
+	if hasErrors && hasOps && opCount > 0 {
+		return errorCount / opCount * 100
+	}
+	return 0
+}
+
+// countFailures counts failed results per group
+func countFailures(collection ResultsCollection, groupBy string) map[string]int {
+	failures := make(map[string]int)
+	for _, result := range collection.Results {
+		if result.Success {
+			continue
+		}
+		if groupBy == "database" {
+			failures[result.DatabaseType]++
+		} else {
+			failures[result.OperationType]++
+		}
+	}
+	return failures
+}
+
+// groupNames returns the sorted group names for the grouping dimension
+func groupNames(collection ResultsCollection, groupBy string) []string {
+	if groupBy == "database" {
+		return collection.DatabaseTypes
+	}
+	return collection.OperationTypes
+}
+
+// Comparison is the change in one metric for a database/operation pair between a baseline and the current run
+type Comparison struct {
+	Database      string
+	Operation     string
+	Metric        string // throughput, latency
+	Baseline      float64
+	Current       float64
+	PercentChange float64
+	Regressed     bool
+}
+
+// compareResults matches results by database and operation and computes the percent
+// change in throughput and latency. A throughput drop or latency increase larger than
+// threshold percent is flagged as a regression.
+func compareResults(baselineCollection, currentCollection ResultsCollection, aggregate string, threshold float64) []Comparison {
+	var comparisons []Comparison
+
+	for _, metric := range []string{"throughput", "latency"} {
+		opts := OutputOptions{GroupBy: "database", MetricType: metric, Aggregate: aggregate}
+		baselineValues := groupResults(baselineCollection, opts)
+		currentValues := groupResults(currentCollection, opts)
+
+		for db, ops := range currentValues {
+			for op, current := range ops {
+				base, ok := baselineValues[db][op]
+				if !ok || base.Value == 0 {
+					continue
 				}
 
-				if *metricType == "throughput" {
-					groupedResults[result.OperationType][result.DatabaseType] = result.Throughput
-				} else {
-					groupedResults[result.OperationType][result.DatabaseType] = float64(result.AvgOperationDurationNs)
+				change := (current.Value - base.Value) / base.Value * 100
+
+				// Lower throughput and higher latency are regressions
+				regressed := change < -threshold
+				if metric == "latency" {
+					regressed = change > threshold
 				}
+
+				comparisons = append(comparisons, Comparison{
+					Database:      db,
+					Operation:     op,
+					Metric:        metric,
+					Baseline:      base.Value,
+					Current:       current.Value,
+					PercentChange: change,
+					Regressed:     regressed,
+				})
 			}
 		}
 	}
 
-	return groupedResults
+	sort.Slice(comparisons, func(i, j int) bool {
+		a, b := comparisons[i], comparisons[j]
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		return a.Metric < b.Metric
+	})
+
+	return comparisons
+}
+
+// generateRegressionReport prints and saves the baseline comparison, returning true if any metric regressed
+func generateRegressionReport(comparisons []Comparison, opts OutputOptions, threshold float64) bool {
+	headers := []string{"Database", "Operation", "Metric", "Baseline", "Current", "Change", "Status"}
+	var rows [][]string
+	regressions := 0
+
+	for _, c := range comparisons {
+		baseValue, currentValue := fmt.Sprintf("%.2f ops/sec", c.Baseline), fmt.Sprintf("%.2f ops/sec", c.Current)
+		if c.Metric == "latency" {
+			// Convert nanoseconds to milliseconds
+			baseValue, currentValue = fmt.Sprintf("%.2f ms", c.Baseline/1000000), fmt.Sprintf("%.2f ms", c.Current/1000000)
+		}
+
+		status := "OK"
+		if c.Regressed {
+			status = "REGRESSION"
+			regressions++
+		}
+
+		rows = append(rows, []string{c.Database, c.Operation, c.Metric, baseValue, currentValue, fmt.Sprintf("%+.2f%%", c.PercentChange), status})
+	}
+
+	var tableString strings.Builder
+	table := tablewriter.NewWriter(&tableString)
+	table.SetHeader(headers)
+	table.SetBorder(true)
+	for _, row := range rows {
+		table.Append(row)
+	}
+	table.Render()
+
+	fmt.Print(tableString.String())
+
+	outputFile := filepath.Join(opts.OutputDir, "regression_report.txt")
+	file, err := os.Create(outputFile)
+	if err != nil {
+		fmt.Printf("Warning: Failed to create regression report: %v\n", err)
+	} else {
+		defer file.Close()
+
+		file.WriteString("# Benchmark Regression Report\n\n")
+		file.WriteString(fmt.Sprintf("Threshold: %.2f%%\n", threshold))
+		file.WriteString(fmt.Sprintf("Compared: %d\n", len(comparisons)))
+		file.WriteString(fmt.Sprintf("Regressions: %d\n\n", regressions))
+		file.WriteString(tableString.String())
+
+		fmt.Printf("Regression report saved to: %s\n", outputFile)
+	}
+
+	if regressions > 0 {
+		fmt.Printf("Found %d regression(s) beyond %.2f%%\n", regressions, threshold)
+		return true
+	}
+
+	fmt.Println("No regressions found.")
+	return false
 }
 
 // Helper functions to extract values for chart
@@ -783,6 +1738,20 @@ func generateXValues(count int) []float64 {
 	return xvalues
 }
 
+// barRange anchors a bar chart's y-axis at zero. go-chart otherwise spans the
+// axis from the smallest to the largest bar, which is an empty range when there
+// is a single bar or all bars are equal.
+func barRange(bars []chart.Value) *chart.ContinuousRange {
+	max := 0.0
+	for _, bar := range bars {
+		max = math.Max(max, bar.Value)
+	}
+	if max <= 0 {
+		max = 1
+	}
+	return &chart.ContinuousRange{Min: 0, Max: max}
+}
+
 func extractYValues(bars []chart.Value) []float64 {
 	yvalues := make([]float64, len(bars))
 	for i, bar := range bars {
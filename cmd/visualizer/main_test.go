@@ -0,0 +1,698 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	chart "github.com/wcharczuk/go-chart/v2"
+)
+
+// newCollection builds a collection of results with its sorted database and operation types
+func newCollection(results ...BenchmarkResult) ResultsCollection {
+	collection := ResultsCollection{Results: results}
+	dbTypes, opTypes := map[string]bool{}, map[string]bool{}
+	for _, result := range results {
+		if !dbTypes[result.DatabaseType] {
+			dbTypes[result.DatabaseType] = true
+			collection.DatabaseTypes = append(collection.DatabaseTypes, result.DatabaseType)
+		}
+		if !opTypes[result.OperationType] {
+			opTypes[result.OperationType] = true
+			collection.OperationTypes = append(collection.OperationTypes, result.OperationType)
+		}
+	}
+	sort.Strings(collection.DatabaseTypes)
+	sort.Strings(collection.OperationTypes)
+	return collection
+}
+
+func TestAggregateValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		method string
+		want   float64
+	}{
+		{"mean", []float64{600, 100, 200}, "mean", 300},
+		{"median of an odd count", []float64{600, 100, 200}, "median", 200},
+		{"median of an even count", []float64{600, 100, 200, 400}, "median", 300},
+		{"min", []float64{600, 100, 200}, "min", 100},
+		{"max", []float64{600, 100, 200}, "max", 600},
+		{"unknown method defaults to mean", []float64{1, 2, 3}, "", 2},
+		{"empty", nil, "max", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregateValues(tt.values, tt.method); got != tt.want {
+				t.Errorf("aggregateValues(%v, %q) = %v, want %v", tt.values, tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupResultsAggregate(t *testing.T) {
+	// Three runs of the same database/operation, plus a failed run that must not count
+	collection := newCollection(
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: 100, AvgOperationDurationNs: 3000000},
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: 600, AvgOperationDurationNs: 1000000},
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: 200, AvgOperationDurationNs: 2000000},
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: false},
+	)
+
+	tests := []struct {
+		metric    string
+		aggregate string
+		want      float64
+	}{
+		{"throughput", "mean", 300},
+		{"throughput", "median", 200},
+		{"throughput", "min", 100},
+		{"throughput", "max", 600},
+		{"latency", "mean", 2000000},
+		{"latency", "median", 2000000},
+		{"latency", "min", 1000000},
+		{"latency", "max", 3000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric+"/"+tt.aggregate, func(t *testing.T) {
+			grouped := groupResults(collection, OutputOptions{GroupBy: "database", MetricType: tt.metric, Aggregate: tt.aggregate})
+			got, ok := grouped["dynamodb"]["read"]
+			if !ok {
+				t.Fatalf("groupResults() = %v, want a dynamodb/read entry", grouped)
+			}
+			if got.Value != tt.want || got.Samples != 3 {
+				t.Errorf("dynamodb/read = %+v, want %v from 3 samples", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupResultsByOperation(t *testing.T) {
+	collection := newCollection(
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: 100},
+		BenchmarkResult{DatabaseType: "redis", OperationType: "read", Success: true, Throughput: 900},
+	)
+
+	grouped := groupResults(collection, OutputOptions{GroupBy: "operation", MetricType: "throughput", Aggregate: "mean"})
+	if len(grouped) != 1 || grouped["read"]["dynamodb"].Value != 100 || grouped["read"]["redis"].Value != 900 {
+		t.Errorf("groupResults() = %v, want read grouped by database", grouped)
+	}
+}
+
+// assertPNG fails unless path holds a PNG image
+func assertPNG(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("chart not written: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Errorf("%s is not a PNG (%d bytes)", filepath.Base(path), len(data))
+	}
+}
+
+func TestComparisonChartLatency(t *testing.T) {
+	collection := newCollection(
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, AvgOperationDurationNs: 4000000},
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "write", Success: true, AvgOperationDurationNs: 8000000},
+		BenchmarkResult{DatabaseType: "redis", OperationType: "read", Success: true, AvgOperationDurationNs: 500000},
+		BenchmarkResult{DatabaseType: "redis", OperationType: "write", Success: true, AvgOperationDurationNs: 1500000},
+	)
+	opts := OutputOptions{OutputDir: t.TempDir(), GroupBy: "database", MetricType: "latency", Aggregate: "mean"}
+
+	// One series per database, with latencies converted to milliseconds
+	series := comparisonSeries(collection, opts)
+	want := map[string][]float64{"dynamodb": {4, 8}, "redis": {0.5, 1.5}}
+	if len(series) != len(collection.DatabaseTypes) {
+		t.Fatalf("comparisonSeries() returned %d series, want %d", len(series), len(collection.DatabaseTypes))
+	}
+	for i, s := range series {
+		continuous := s.(chart.ContinuousSeries)
+		if continuous.Name != collection.DatabaseTypes[i] {
+			t.Errorf("series %d name = %q, want %q", i, continuous.Name, collection.DatabaseTypes[i])
+		}
+		if !reflect.DeepEqual(continuous.YValues, want[continuous.Name]) {
+			t.Errorf("%s values = %v, want %v", continuous.Name, continuous.YValues, want[continuous.Name])
+		}
+	}
+
+	generateComparisonChart(collection, opts)
+	assertPNG(t, filepath.Join(opts.OutputDir, "database_comparison_latency_chart.png"))
+	if _, err := os.Stat(filepath.Join(opts.OutputDir, "database_comparison_chart.png")); err == nil {
+		t.Error("latency chart also wrote the throughput chart path")
+	}
+}
+
+func TestColdStartChart(t *testing.T) {
+	tests := []struct {
+		name      string
+		results   []BenchmarkResult
+		wantChart bool
+	}{
+		{
+			"cold and warm latencies",
+			[]BenchmarkResult{
+				// Values read back from result files are float64; collector output is int64
+				{DatabaseType: "dynamodb", OperationType: "read", Success: true, Metrics: map[string]interface{}{"coldStartAvgNs": 250000000.0, "warmAvgNs": 5000000.0}},
+				{DatabaseType: "redis", OperationType: "read", Success: true, Metrics: map[string]interface{}{"coldStartAvgNs": int64(90000000), "warmAvgNs": int64(1000000)}},
+			},
+			true,
+		},
+		{
+			"warm only",
+			[]BenchmarkResult{
+				{DatabaseType: "dynamodb", OperationType: "read", Success: true, Metrics: map[string]interface{}{"warmAvgNs": 5000000.0}},
+			},
+			true,
+		},
+		{
+			"no cold-start metrics",
+			[]BenchmarkResult{
+				{DatabaseType: "dynamodb", OperationType: "read", Success: true, Metrics: map[string]interface{}{"p50": 5000000.0}},
+				{DatabaseType: "redis", OperationType: "read", Success: false, Metrics: map[string]interface{}{"coldStartAvgNs": 90000000.0}},
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := OutputOptions{OutputDir: t.TempDir(), Aggregate: "mean"}
+			generateColdStartChart(newCollection(tt.results...), opts)
+
+			path := filepath.Join(opts.OutputDir, "cold_start_comparison_chart.png")
+			if tt.wantChart {
+				assertPNG(t, path)
+			} else if _, err := os.Stat(path); err == nil {
+				t.Error("chart written without cold-start metrics from successful results")
+			}
+		})
+	}
+}
+
+func TestCompareResults(t *testing.T) {
+	baseline := newCollection(
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: 1000, AvgOperationDurationNs: 2000000},
+		// Pairs missing from the current run are not compared
+		BenchmarkResult{DatabaseType: "redis", OperationType: "read", Success: true, Throughput: 5000, AvgOperationDurationNs: 200000},
+	)
+
+	tests := []struct {
+		name           string
+		throughput     float64
+		latencyNs      int64
+		wantChanges    map[string]float64
+		wantRegressed  map[string]bool
+		wantRegression bool
+	}{
+		{
+			"within threshold",
+			950, 2100000,
+			map[string]float64{"throughput": -5, "latency": 5},
+			map[string]bool{"throughput": false, "latency": false},
+			false,
+		},
+		{
+			"throughput drop beyond threshold",
+			800, 2000000,
+			map[string]float64{"throughput": -20, "latency": 0},
+			map[string]bool{"throughput": true, "latency": false},
+			true,
+		},
+		{
+			"latency increase beyond threshold",
+			1000, 3000000,
+			map[string]float64{"throughput": 0, "latency": 50},
+			map[string]bool{"throughput": false, "latency": true},
+			true,
+		},
+		{
+			// Improvements beyond the threshold are not regressions
+			"improvement beyond threshold",
+			1500, 1000000,
+			map[string]float64{"throughput": 50, "latency": -50},
+			map[string]bool{"throughput": false, "latency": false},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := newCollection(
+				BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: tt.throughput, AvgOperationDurationNs: tt.latencyNs},
+			)
+
+			comparisons := compareResults(baseline, current, "mean", 10)
+			if len(comparisons) != 2 {
+				t.Fatalf("compareResults() returned %d comparisons, want 2: %+v", len(comparisons), comparisons)
+			}
+			for _, c := range comparisons {
+				if c.Database != "dynamodb" || c.Operation != "read" {
+					t.Errorf("comparison for %s/%s, want dynamodb/read", c.Database, c.Operation)
+				}
+				if math.Abs(c.PercentChange-tt.wantChanges[c.Metric]) > 1e-9 {
+					t.Errorf("%s change = %v%%, want %v%%", c.Metric, c.PercentChange, tt.wantChanges[c.Metric])
+				}
+				if c.Regressed != tt.wantRegressed[c.Metric] {
+					t.Errorf("%s regressed = %v, want %v", c.Metric, c.Regressed, tt.wantRegressed[c.Metric])
+				}
+			}
+
+			// The report's result decides whether the visualizer exits non-zero
+			opts := OutputOptions{OutputDir: t.TempDir()}
+			if got := generateRegressionReport(comparisons, opts, 10); got != tt.wantRegression {
+				t.Errorf("generateRegressionReport() = %v, want %v", got, tt.wantRegression)
+			}
+
+			report, err := os.ReadFile(filepath.Join(opts.OutputDir, "regression_report.txt"))
+			if err != nil {
+				t.Fatalf("regression report not written: %v", err)
+			}
+			wantCount := 0
+			for _, regressed := range tt.wantRegressed {
+				if regressed {
+					wantCount++
+				}
+			}
+			if !strings.Contains(string(report), fmt.Sprintf("Regressions: %d\n", wantCount)) {
+				t.Errorf("regression report does not count %d regression(s):\n%s", wantCount, report)
+			}
+		})
+	}
+}
+
+// parseMarkdownSummary splits a Markdown summary into its top-level front matter
+// fields and the table rows under each "## " section, header row first, with the
+// separator row dropped and cells trimmed
+func parseMarkdownSummary(t *testing.T, content string) (map[string]string, map[string][][]string) {
+	t.Helper()
+
+	parts := strings.SplitN(content, "---\n", 3)
+	if len(parts) != 3 || parts[0] != "" {
+		t.Fatalf("summary does not start with front matter:\n%s", content)
+	}
+
+	frontMatter := make(map[string]string)
+	for _, line := range strings.Split(parts[1], "\n") {
+		if line == "" || strings.HasPrefix(line, " ") {
+			continue
+		}
+		key, value, _ := strings.Cut(line, ":")
+		frontMatter[key] = strings.TrimSpace(value)
+	}
+
+	tables := make(map[string][][]string)
+	var section string
+	for _, line := range strings.Split(parts[2], "\n") {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			section = strings.TrimPrefix(line, "## ")
+		case strings.HasPrefix(line, "|-"):
+			continue
+		case strings.HasPrefix(line, "|"):
+			var cells []string
+			for _, cell := range strings.Split(strings.Trim(line, "|"), "|") {
+				cells = append(cells, strings.TrimSpace(cell))
+			}
+			tables[section] = append(tables[section], cells)
+		}
+	}
+	return frontMatter, tables
+}
+
+func TestMarkdownSummary(t *testing.T) {
+	collection := newCollection(
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: 100, AvgOperationDurationNs: 2500000},
+		BenchmarkResult{DatabaseType: "redis", OperationType: "write", Success: true, Throughput: 900, AvgOperationDurationNs: 500000},
+	)
+	opts := OutputOptions{OutputDir: t.TempDir(), GroupBy: "database", Aggregate: "mean"}
+	filterOpts := FilterOptions{Databases: []string{"dynamodb", "redis"}, StartTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	generateMarkdownSummary(collection, opts, filterOpts)
+
+	content, err := os.ReadFile(filepath.Join(opts.OutputDir, "summary.md"))
+	if err != nil {
+		t.Fatalf("summary not written: %v", err)
+	}
+	frontMatter, tables := parseMarkdownSummary(t, string(content))
+
+	wantFrontMatter := map[string]string{
+		"title":      "Benchmark Results Summary",
+		"results":    "2",
+		"groupBy":    "database",
+		"aggregate":  "mean",
+		"databases":  "[dynamodb, redis]",
+		"operations": "[read, write]",
+	}
+	for key, want := range wantFrontMatter {
+		if got := frontMatter[key]; got != want {
+			t.Errorf("front matter %s = %q, want %q", key, got, want)
+		}
+	}
+	if _, err := time.Parse(time.RFC3339, frontMatter["generated"]); err != nil {
+		t.Errorf("front matter generated = %q, want an RFC 3339 time", frontMatter["generated"])
+	}
+	if !strings.Contains(string(content), "  startDate: 2024-01-01\n") {
+		t.Error("front matter does not list the start date filter")
+	}
+
+	wantTables := map[string][][]string{
+		"Throughput": {
+			{"Database", "read (ops/sec)", "write (ops/sec)"},
+			{"dynamodb", "100.00", "N/A"},
+			{"redis", "N/A", "900.00"},
+		},
+		"Latency": {
+			{"Database", "read (ms)", "write (ms)"},
+			{"dynamodb", "2.50", "N/A"},
+			{"redis", "N/A", "0.50"},
+		},
+	}
+	if !reflect.DeepEqual(tables, wantTables) {
+		t.Errorf("summary tables = %v, want %v", tables, wantTables)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		result BenchmarkResult
+		want   float64
+	}{
+		{"success rate", BenchmarkResult{Success: true, Metrics: map[string]interface{}{"successRate": 0.75, "errorCount": 1.0, "operationCount": 2.0}}, 25},
+		{"error count fallback", BenchmarkResult{Success: true, Metrics: map[string]interface{}{"errorCount": int64(5), "operationCount": int64(20)}}, 25},
+		{"no operations", BenchmarkResult{Success: true, Metrics: map[string]interface{}{"errorCount": 0.0, "operationCount": 0.0}}, 0},
+		{"no metrics", BenchmarkResult{Success: true}, 0},
+		{"failed invocation", BenchmarkResult{Success: false, Metrics: map[string]interface{}{"successRate": 1.0}}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorRate(tt.result); got != tt.want {
+				t.Errorf("errorRate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupResultsErrorRate(t *testing.T) {
+	collection := newCollection(
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Metrics: map[string]interface{}{"successRate": 0.9}},
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: false},
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "write", Success: true, Throughput: 100},
+		BenchmarkResult{DatabaseType: "redis", OperationType: "read", Success: false},
+	)
+
+	// Failed results count as 100% errors instead of being dropped
+	grouped := groupResults(collection, OutputOptions{GroupBy: "database", MetricType: "errorrate", Aggregate: "mean"})
+	want := map[string]map[string]GroupedValue{
+		"dynamodb": {"read": {Value: 55, Samples: 2}, "write": {Value: 0, Samples: 1}},
+		"redis":    {"read": {Value: 100, Samples: 1}},
+	}
+	if !reflect.DeepEqual(grouped, want) {
+		t.Errorf("groupResults() = %v, want %v", grouped, want)
+	}
+
+	// Other metrics still skip failed results
+	throughput := groupResults(collection, OutputOptions{GroupBy: "database", MetricType: "throughput", Aggregate: "mean"})
+	if _, ok := throughput["redis"]; ok || throughput["dynamodb"]["read"].Samples != 1 {
+		t.Errorf("throughput groupResults() = %v, want failed results skipped", throughput)
+	}
+
+	if got := countFailures(collection, "database"); !reflect.DeepEqual(got, map[string]int{"dynamodb": 1, "redis": 1}) {
+		t.Errorf("countFailures(database) = %v", got)
+	}
+	if got := countFailures(collection, "operation"); !reflect.DeepEqual(got, map[string]int{"read": 2}) {
+		t.Errorf("countFailures(operation) = %v", got)
+	}
+}
+
+func TestLoadResultFromFileSchema(t *testing.T) {
+	schema, err := compileResultSchema()
+	if err != nil {
+		t.Fatalf("compileResultSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			"valid",
+			`{"operationType": "read", "databaseType": "dynamodb", "success": true, "itemsProcessed": 100,
+			  "totalDurationNs": 500000000, "avgOperationDurationNs": 5000000, "throughput": 200.5,
+			  "timestamp": "2024-01-01T00:00:00Z", "metrics": {"p99": 9000000}}`,
+			"",
+		},
+		{
+			"missing required fields",
+			`{"operationType": "read", "databaseType": "dynamodb", "success": true}`,
+			"missing properties",
+		},
+		{
+			"integer field with a fraction",
+			`{"operationType": "read", "databaseType": "dynamodb", "success": true, "itemsProcessed": 100.5,
+			  "totalDurationNs": 500000000, "avgOperationDurationNs": 5000000, "throughput": 200,
+			  "timestamp": "2024-01-01T00:00:00Z"}`,
+			"itemsProcessed",
+		},
+		{
+			"wrong types",
+			`{"operationType": "read", "databaseType": "dynamodb", "success": "yes", "itemsProcessed": 100,
+			  "totalDurationNs": 500000000, "avgOperationDurationNs": 5000000, "throughput": "fast",
+			  "timestamp": "2024-01-01T00:00:00Z"}`,
+			"expected boolean",
+		},
+		{
+			"invalid timestamp",
+			`{"operationType": "read", "databaseType": "dynamodb", "success": true, "itemsProcessed": 100,
+			  "totalDurationNs": 500000000, "avgOperationDurationNs": 5000000, "throughput": 200,
+			  "timestamp": "yesterday"}`,
+			"date-time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "result.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := loadResultFromFile(path, schema)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("loadResultFromFile() error = %v", err)
+				}
+				if result.DatabaseType != "dynamodb" || result.ItemsProcessed != 100 || result.Throughput != 200.5 {
+					t.Errorf("loadResultFromFile() = %+v", result)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "schema validation failed") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadResultFromFile() error = %v, want a schema validation error mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRawLatencyCSV(t *testing.T) {
+	collection := newCollection(
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Metrics: map[string]interface{}{
+			"operations": []interface{}{
+				map[string]interface{}{"durationNs": 1500000.0, "itemCount": 1.0, "byteCount": 128.0, "isColdStart": true},
+				map[string]interface{}{"durationNs": 900000.0, "itemCount": 1.0, "byteCount": 128.0, "isColdStart": false},
+			},
+		}},
+		BenchmarkResult{DatabaseType: "redis", OperationType: "write", Success: true, Metrics: map[string]interface{}{
+			"operations": []interface{}{
+				map[string]interface{}{"durationNs": 300000.0, "itemCount": 0.0, "errorMessage": `timeout, "retry"`},
+			},
+		}},
+		// Results without raw samples are skipped
+		BenchmarkResult{DatabaseType: "redis", OperationType: "read", Success: true, Metrics: map[string]interface{}{"p50": 100000.0}},
+	)
+	opts := OutputOptions{OutputDir: t.TempDir()}
+
+	generateRawLatencyCSV(collection, opts)
+
+	file, err := os.Open(filepath.Join(opts.OutputDir, "raw_latencies.csv"))
+	if err != nil {
+		t.Fatalf("raw latency CSV not written: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("raw latency CSV is not valid CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"database", "operation", "index", "durationNs", "itemCount", "byteCount", "isColdStart", "error"},
+		{"dynamodb", "read", "0", "1500000", "1", "128", "true", ""},
+		{"dynamodb", "read", "1", "900000", "1", "128", "false", ""},
+		{"redis", "write", "0", "300000", "0", "0", "false", `timeout, "retry"`},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("raw latency CSV = %v, want %v", records, want)
+	}
+}
+
+func TestRawLatencyCSVWithoutSamples(t *testing.T) {
+	opts := OutputOptions{OutputDir: t.TempDir()}
+	generateRawLatencyCSV(newCollection(BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true}), opts)
+
+	if _, err := os.Stat(filepath.Join(opts.OutputDir, "raw_latencies.csv")); err == nil {
+		t.Error("raw latency CSV written without raw samples")
+	}
+}
+
+// histogramResult returns a result whose latency histogram for opType holds the
+// given latencies in milliseconds, encoded like the metrics collector does
+func histogramResult(t *testing.T, opType string, latenciesMs ...int64) BenchmarkResult {
+	t.Helper()
+
+	h := hdrhistogram.New(1, int64(time.Hour), 3)
+	for _, ms := range latenciesMs {
+		if err := h.RecordValue(ms * int64(time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	encoded, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Metrics: map[string]interface{}{
+		"latencyHistogram": map[string]interface{}{
+			opType: map[string]interface{}{"count": float64(h.TotalCount()), "encoded": string(encoded)},
+		},
+	}}
+}
+
+// rangeMs returns the whole milliseconds from first to last inclusive
+func rangeMs(first, last int64) []int64 {
+	values := make([]int64, 0, last-first+1)
+	for ms := first; ms <= last; ms++ {
+		values = append(values, ms)
+	}
+	return values
+}
+
+func TestMergeLatencyHistograms(t *testing.T) {
+	// A fast and a slow run: merged, their percentiles are those of 1..200ms, which
+	// averaging each run's own percentiles would not give
+	results := []BenchmarkResult{
+		histogramResult(t, "READ", rangeMs(1, 100)...),
+		histogramResult(t, "READ", rangeMs(101, 200)...),
+		{DatabaseType: "dynamodb", OperationType: "read", Success: true, Metrics: map[string]interface{}{"p50": 1000000.0}},
+	}
+
+	merged, files, err := mergeLatencyHistograms(results)
+	if err != nil {
+		t.Fatalf("mergeLatencyHistograms() error = %v", err)
+	}
+	if merged == nil || files != 2 || merged.TotalCount() != 200 {
+		t.Fatalf("mergeLatencyHistograms() = %v from %d files, want 200 samples from 2 files", merged, files)
+	}
+
+	for p, wantMs := range map[float64]float64{50: 100, 95: 190, 99: 198} {
+		got := float64(merged.ValueAtPercentile(p)) / float64(time.Millisecond)
+		// Three significant digits keep values within 0.1%
+		if math.Abs(got-wantMs) > wantMs*0.001 {
+			t.Errorf("merged p%v = %.3fms, want %vms", p, got, wantMs)
+		}
+	}
+}
+
+func TestMergeLatencyHistogramsErrors(t *testing.T) {
+	merged, files, err := mergeLatencyHistograms([]BenchmarkResult{{Metrics: map[string]interface{}{"p50": 1.0}}})
+	if merged != nil || files != 0 || err != nil {
+		t.Errorf("mergeLatencyHistograms() without histograms = %v, %d, %v, want nil, 0, nil", merged, files, err)
+	}
+
+	corrupt := BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Metrics: map[string]interface{}{
+		"latencyHistogram": map[string]interface{}{"READ": map[string]interface{}{"encoded": "not a histogram"}},
+	}}
+	if _, _, err := mergeLatencyHistograms([]BenchmarkResult{corrupt}); err == nil {
+		t.Error("mergeLatencyHistograms() of a corrupt histogram error = nil")
+	}
+}
+
+func TestCSVReportPercentileColumns(t *testing.T) {
+	collection := newCollection(
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: 100, AvgOperationDurationNs: 2000000,
+			Metrics: map[string]interface{}{"p50": 1000000.0, "p90": 3000000.0, "p99": 5000000.0}},
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "read", Success: true, Throughput: 300, AvgOperationDurationNs: 4000000,
+			Metrics: map[string]interface{}{"p50": int64(3000000), "p90": int64(5000000), "p99": int64(9000000)}},
+		// Older result files carry no percentiles
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "write", Success: true, Throughput: 50, AvgOperationDurationNs: 8000000},
+		BenchmarkResult{DatabaseType: "dynamodb", OperationType: "write", Success: false, Metrics: map[string]interface{}{"p50": 1.0}},
+	)
+
+	tests := []struct {
+		metric string
+		want   [][]string
+	}{
+		{
+			"latency",
+			[][]string{
+				{"Database", "read", "read p50", "read p90", "read p99", "write", "write p50", "write p90", "write p99", "Errors"},
+				{"dynamodb", "3.00", "2.00", "4.00", "7.00", "8.00", "N/A", "N/A", "N/A", "1"},
+			},
+		},
+		{
+			// Percentile columns only accompany latency
+			"throughput",
+			[][]string{
+				{"Database", "read", "write", "Errors"},
+				{"dynamodb", "200.00", "50.00", "1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			opts := OutputOptions{OutputDir: t.TempDir(), GroupBy: "database", MetricType: tt.metric, Aggregate: "mean"}
+			generateCSVReport(collection, opts)
+
+			file, err := os.Open(filepath.Join(opts.OutputDir, "benchmark_results_database_"+tt.metric+".csv"))
+			if err != nil {
+				t.Fatalf("CSV report not written: %v", err)
+			}
+			defer file.Close()
+			records, err := csv.NewReader(file).ReadAll()
+			if err != nil {
+				t.Fatalf("CSV report is not valid CSV: %v", err)
+			}
+			if !reflect.DeepEqual(records, tt.want) {
+				t.Errorf("CSV report = %v, want %v", records, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentileHeaders(t *testing.T) {
+	latency := OutputOptions{MetricType: "latency"}
+	if got, want := percentileHeaders("read", latency, " (ms)"), []string{"read p50 (ms)", "read p90 (ms)", "read p99 (ms)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("percentileHeaders() = %v, want %v", got, want)
+	}
+	if got := percentileHeaders("read", OutputOptions{MetricType: "errorrate"}, ""); got != nil {
+		t.Errorf("percentileHeaders() for error rate = %v, want none", got)
+	}
+	if got, want := percentileCells(map[string]GroupedValue{"p90": {Value: 1234567}}, latency), []string{"N/A", "1.23", "N/A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("percentileCells() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package cost
+
+import (
+	"fmt"
+)
+
+// DynamoDB pricing in USD for us-east-1 standard table class
+const (
+	// OnDemandReadRequestUnitPrice is the price of one on-demand read request unit
+	OnDemandReadRequestUnitPrice = 0.125 / 1000000
+	// OnDemandWriteRequestUnitPrice is the price of one on-demand write request unit
+	OnDemandWriteRequestUnitPrice = 0.625 / 1000000
+	// ProvisionedRCUHourPrice is the price of one read capacity unit provisioned for an hour
+	ProvisionedRCUHourPrice = 0.00013
+	// ProvisionedWCUHourPrice is the price of one write capacity unit provisioned for an hour
+	ProvisionedWCUHourPrice = 0.00065
+)
+
+// EstimateDynamoDBCost converts the consumed read and write capacity units of a
+// benchmark into an estimated dollar cost. metrics is either the adapter metrics or
+// a result's metrics, in which case the capacity is read from its dbMetrics entry.
+//
+// In provisioned mode the cost assumes fully utilized capacity: one capacity unit
+// sustains one unit per second, so a consumed unit costs 1/3600 of the hourly price.
+func EstimateDynamoDBCost(metrics map[string]interface{}, onDemand bool) (float64, error) {
+	if dbMetrics, ok := metrics["dbMetrics"].(map[string]interface{}); ok {
+		metrics = dbMetrics
+	}
+
+	readUnits, hasReads := capacityUnits(metrics, "readCapacityUnits")
+	writeUnits, hasWrites := capacityUnits(metrics, "writeCapacityUnits")
+	if !hasReads && !hasWrites {
+		return 0, fmt.Errorf("metrics contain no consumed capacity units")
+	}
+	if readUnits < 0 || writeUnits < 0 {
+		return 0, fmt.Errorf("consumed capacity units must not be negative, got %v read and %v write", readUnits, writeUnits)
+	}
+
+	if onDemand {
+		return readUnits*OnDemandReadRequestUnitPrice + writeUnits*OnDemandWriteRequestUnitPrice, nil
+	}
+	return (readUnits*ProvisionedRCUHourPrice + writeUnits*ProvisionedWCUHourPrice) / 3600, nil
+}
+
+// capacityUnits reads a numeric capacity metric
+func capacityUnits(metrics map[string]interface{}, key string) (float64, bool) {
+	switch v := metrics[key].(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
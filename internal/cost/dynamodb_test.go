@@ -0,0 +1,104 @@
+package cost
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateDynamoDBCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  map[string]interface{}
+		onDemand bool
+		want     float64
+	}{
+		{
+			name:     "on-demand reads",
+			metrics:  map[string]interface{}{"readCapacityUnits": 1000000.0},
+			onDemand: true,
+			want:     0.125,
+		},
+		{
+			name:     "on-demand writes",
+			metrics:  map[string]interface{}{"writeCapacityUnits": 1000000.0},
+			onDemand: true,
+			want:     0.625,
+		},
+		{
+			name:     "on-demand reads and writes",
+			metrics:  map[string]interface{}{"readCapacityUnits": 2000000.0, "writeCapacityUnits": 400000.0},
+			onDemand: true,
+			want:     0.25 + 0.25,
+		},
+		{
+			name:    "provisioned reads cost an hour of capacity per 3600 units",
+			metrics: map[string]interface{}{"readCapacityUnits": 3600.0},
+			want:    ProvisionedRCUHourPrice,
+		},
+		{
+			name:    "provisioned writes cost an hour of capacity per 3600 units",
+			metrics: map[string]interface{}{"writeCapacityUnits": 3600.0},
+			want:    ProvisionedWCUHourPrice,
+		},
+		{
+			name:    "provisioned reads and writes",
+			metrics: map[string]interface{}{"readCapacityUnits": 7200.0, "writeCapacityUnits": 360.0},
+			want:    2*ProvisionedRCUHourPrice + 0.1*ProvisionedWCUHourPrice,
+		},
+		{
+			name:     "integer capacity units",
+			metrics:  map[string]interface{}{"readCapacityUnits": 1000000, "writeCapacityUnits": int64(1000000)},
+			onDemand: true,
+			want:     0.75,
+		},
+		{
+			name: "result metrics read from dbMetrics",
+			metrics: map[string]interface{}{
+				"throughput": 500.0,
+				"dbMetrics":  map[string]interface{}{"readCapacityUnits": 1000000.0},
+			},
+			onDemand: true,
+			want:     0.125,
+		},
+		{
+			name:     "zero units",
+			metrics:  map[string]interface{}{"readCapacityUnits": 0.0},
+			onDemand: true,
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EstimateDynamoDBCost(tt.metrics, tt.onDemand)
+			if err != nil {
+				t.Fatalf("EstimateDynamoDBCost() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("EstimateDynamoDBCost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateDynamoDBCostErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics map[string]interface{}
+	}{
+		{"no capacity units", map[string]interface{}{"throughput": 500.0}},
+		{"non-numeric capacity units", map[string]interface{}{"readCapacityUnits": "100"}},
+		{"negative read units", map[string]interface{}{"readCapacityUnits": -1.0}},
+		{"negative write units", map[string]interface{}{"writeCapacityUnits": -1.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, onDemand := range []bool{true, false} {
+				if _, err := EstimateDynamoDBCost(tt.metrics, onDemand); err == nil {
+					t.Errorf("EstimateDynamoDBCost(onDemand=%v) error = nil, want an error", onDemand)
+				}
+			}
+		})
+	}
+}
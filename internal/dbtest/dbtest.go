@@ -0,0 +1,503 @@
+// Package dbtest provides an in-memory databases.Database for tests of the benchmark
+// handler and operations, with hooks to slow down, fail or drop individual calls.
+package dbtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// DB is an in-memory database keyed by account and transaction UUID. It is safe for
+// concurrent use.
+type DB struct {
+	// Hook, when set, runs at the start of every data call with the method name and the
+	// transaction UUID ("" for calls without one). A non-nil error fails the call.
+	Hook func(ctx context.Context, method, uuid string) error
+
+	// DropWrite, when set, reports writes that succeed without being stored
+	DropWrite func(transaction *databases.Transaction) bool
+
+	// Metrics is returned by GetMetrics; when nil, GetMetrics returns the call counters
+	Metrics map[string]interface{}
+
+	mu           sync.Mutex
+	items        map[string]*databases.Transaction
+	calls        map[string]int
+	initialized  int
+	closed       int
+	metricResets int
+}
+
+// New returns an empty database
+func New() *DB {
+	return &DB{
+		items: make(map[string]*databases.Transaction),
+		calls: make(map[string]int),
+	}
+}
+
+// key returns the storage key of a transaction
+func key(accountID, uuid string) string {
+	return accountID + "#" + uuid
+}
+
+// call counts a call to method and runs the hook
+func (db *DB) call(ctx context.Context, method, uuid string) error {
+	db.mu.Lock()
+	db.calls[method]++
+	db.mu.Unlock()
+
+	if db.Hook != nil {
+		return db.Hook(ctx, method, uuid)
+	}
+	return nil
+}
+
+// Calls returns the number of calls made to method
+func (db *DB) Calls(method string) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.calls[method]
+}
+
+// Initialized returns the number of Initialize calls
+func (db *DB) Initialized() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.initialized
+}
+
+// Closed returns the number of Close calls
+func (db *DB) Closed() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.closed
+}
+
+// MetricResets returns the number of ResetMetrics calls
+func (db *DB) MetricResets() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.metricResets
+}
+
+// Len returns the number of stored transactions
+func (db *DB) Len() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return len(db.items)
+}
+
+// Put stores transactions without going through the hooks
+func (db *DB) Put(transactions ...*databases.Transaction) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, transaction := range transactions {
+		copied := *transaction
+		db.items[key(transaction.AccountID, transaction.UUID)] = &copied
+	}
+}
+
+// Initialize implements databases.Database
+func (db *DB) Initialize(ctx context.Context) error {
+	db.mu.Lock()
+	db.initialized++
+	db.mu.Unlock()
+	return db.call(ctx, "Initialize", "")
+}
+
+// Close implements databases.Database
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.closed++
+	return nil
+}
+
+// CanReinitialize implements databases.Reinitializable
+func (db *DB) CanReinitialize() bool {
+	return true
+}
+
+// checkCondition applies attribute_exists and attribute_not_exists conditions on
+// either key attribute, which is what the operations and lambdas use; the caller
+// holds mu
+func (db *DB) checkCondition(condition, k string) error {
+	_, exists := db.items[k]
+	switch condition {
+	case "":
+		return nil
+	case "attribute_exists(uuid)", "attribute_exists(accountId)":
+		if !exists {
+			return databases.ErrConditionFailed
+		}
+	case "attribute_not_exists(uuid)", "attribute_not_exists(accountId)":
+		if exists {
+			return databases.ErrConditionFailed
+		}
+	default:
+		return fmt.Errorf("condition %q: %w", condition, databases.ErrNotSupported)
+	}
+	return nil
+}
+
+// store writes a transaction unless DropWrite drops it; the caller holds mu
+func (db *DB) store(transaction *databases.Transaction) {
+	if db.DropWrite != nil && db.DropWrite(transaction) {
+		return
+	}
+	copied := *transaction
+	db.items[key(transaction.AccountID, transaction.UUID)] = &copied
+}
+
+// ReadTransaction implements databases.Database
+func (db *DB) ReadTransaction(ctx context.Context, accountID, uuid string, options *databases.ReadOptions) (*databases.Transaction, error) {
+	if err := db.call(ctx, "ReadTransaction", uuid); err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	transaction, ok := db.items[key(accountID, uuid)]
+	if !ok {
+		return nil, databases.ErrTransactionNotFound
+	}
+	copied := *transaction
+	return &copied, nil
+}
+
+// WriteTransaction implements databases.Database
+func (db *DB) WriteTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) error {
+	if err := db.call(ctx, "WriteTransaction", transaction.UUID); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if options != nil {
+		if err := db.checkCondition(options.Condition, key(transaction.AccountID, transaction.UUID)); err != nil {
+			return err
+		}
+	}
+	db.store(transaction)
+	return nil
+}
+
+// UpdateTransaction implements databases.Database
+func (db *DB) UpdateTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) error {
+	if err := db.call(ctx, "UpdateTransaction", transaction.UUID); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if options != nil {
+		if err := db.checkCondition(options.Condition, key(transaction.AccountID, transaction.UUID)); err != nil {
+			return err
+		}
+	}
+	db.store(transaction)
+	return nil
+}
+
+// DeleteTransaction implements databases.Database
+func (db *DB) DeleteTransaction(ctx context.Context, accountID, uuid string, options *databases.DeleteOptions) error {
+	if err := db.call(ctx, "DeleteTransaction", uuid); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := key(accountID, uuid)
+	if options != nil {
+		if err := db.checkCondition(options.Condition, k); err != nil {
+			return err
+		}
+		if options.ReturnOldItem {
+			if old, ok := db.items[k]; ok {
+				copied := *old
+				options.OldItem = &copied
+			}
+		}
+	}
+	delete(db.items, k)
+	return nil
+}
+
+// account returns the account's transactions sorted by timestamp; the caller holds mu
+func (db *DB) account(accountID string, forward bool) []*databases.Transaction {
+	var transactions []*databases.Transaction
+	for _, transaction := range db.items {
+		if transaction.AccountID == accountID {
+			copied := *transaction
+			transactions = append(transactions, &copied)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if forward {
+			return transactions[i].Timestamp.Before(transactions[j].Timestamp)
+		}
+		return transactions[i].Timestamp.After(transactions[j].Timestamp)
+	})
+	return transactions
+}
+
+// limit truncates transactions to the query limit
+func limit(transactions []*databases.Transaction, n int64) []*databases.Transaction {
+	if n > 0 && int64(len(transactions)) > n {
+		return transactions[:n]
+	}
+	return transactions
+}
+
+// page returns the page of transactions starting at the offset held in token
+func page(transactions []*databases.Transaction, token string, n int64) (*databases.PagedTransactions, error) {
+	offset := 0
+	if token != "" {
+		var err error
+		if offset, err = strconv.Atoi(token); err != nil {
+			return nil, fmt.Errorf("invalid start token %q", token)
+		}
+	}
+	if offset > len(transactions) {
+		offset = len(transactions)
+	}
+
+	result := &databases.PagedTransactions{Transactions: limit(transactions[offset:], n)}
+	if next := offset + len(result.Transactions); next < len(transactions) {
+		result.NextToken = strconv.Itoa(next)
+	}
+	return result, nil
+}
+
+// QueryTransactionsByAccount implements databases.Database
+func (db *DB) QueryTransactionsByAccount(ctx context.Context, accountID string, options *databases.QueryOptions) ([]*databases.Transaction, error) {
+	if err := db.call(ctx, "QueryTransactionsByAccount", ""); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &databases.QueryOptions{ScanIndexForward: true}
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return limit(db.account(accountID, options.ScanIndexForward), options.Limit), nil
+}
+
+// QueryTransactionsByTimeRange implements databases.Database
+func (db *DB) QueryTransactionsByTimeRange(ctx context.Context, accountID string, startTime, endTime time.Time, options *databases.QueryOptions) ([]*databases.Transaction, error) {
+	if err := db.call(ctx, "QueryTransactionsByTimeRange", ""); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &databases.QueryOptions{ScanIndexForward: true}
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var transactions []*databases.Transaction
+	for _, transaction := range db.account(accountID, options.ScanIndexForward) {
+		if !transaction.Timestamp.Before(startTime) && !transaction.Timestamp.After(endTime) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return limit(transactions, options.Limit), nil
+}
+
+// QueryTransactionsByAccountPaged implements databases.Database
+func (db *DB) QueryTransactionsByAccountPaged(ctx context.Context, accountID string, options *databases.QueryOptions) (*databases.PagedTransactions, error) {
+	if err := db.call(ctx, "QueryTransactionsByAccountPaged", ""); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &databases.QueryOptions{ScanIndexForward: true}
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return page(db.account(accountID, options.ScanIndexForward), options.StartToken, options.Limit)
+}
+
+// CountTransactionsByAccount implements databases.Database
+func (db *DB) CountTransactionsByAccount(ctx context.Context, accountID string) (int64, error) {
+	if err := db.call(ctx, "CountTransactionsByAccount", ""); err != nil {
+		return 0, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return int64(len(db.account(accountID, true))), nil
+}
+
+// all returns every transaction sorted by account and UUID; the caller holds mu
+func (db *DB) all() []*databases.Transaction {
+	transactions := make([]*databases.Transaction, 0, len(db.items))
+	for _, transaction := range db.items {
+		copied := *transaction
+		transactions = append(transactions, &copied)
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return key(transactions[i].AccountID, transactions[i].UUID) < key(transactions[j].AccountID, transactions[j].UUID)
+	})
+	return transactions
+}
+
+// ScanTransactions implements databases.Database
+func (db *DB) ScanTransactions(ctx context.Context, options *databases.ScanOptions) ([]*databases.Transaction, error) {
+	if err := db.call(ctx, "ScanTransactions", ""); err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.all(), nil
+}
+
+// ScanTransactionsPaged implements databases.Database. Segments of a parallel scan
+// split the sorted transactions round-robin.
+func (db *DB) ScanTransactionsPaged(ctx context.Context, options *databases.ScanOptions) (*databases.PagedTransactions, error) {
+	if err := db.call(ctx, "ScanTransactionsPaged", ""); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &databases.ScanOptions{}
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	transactions := db.all()
+	if options.TotalSegments > 1 {
+		var segment []*databases.Transaction
+		for i, transaction := range transactions {
+			if int32(i)%options.TotalSegments == options.Segment {
+				segment = append(segment, transaction)
+			}
+		}
+		transactions = segment
+	}
+	return page(transactions, options.StartToken, options.Limit)
+}
+
+// BatchReadTransactions implements databases.Database; missing keys are skipped
+func (db *DB) BatchReadTransactions(ctx context.Context, keys []struct{ AccountID, UUID string }, options *databases.BatchOptions) ([]*databases.Transaction, error) {
+	if err := db.call(ctx, "BatchReadTransactions", ""); err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var transactions []*databases.Transaction
+	for _, k := range keys {
+		if transaction, ok := db.items[key(k.AccountID, k.UUID)]; ok {
+			copied := *transaction
+			transactions = append(transactions, &copied)
+		}
+	}
+	return transactions, nil
+}
+
+// BatchWriteTransactions implements databases.Database
+func (db *DB) BatchWriteTransactions(ctx context.Context, transactions []*databases.Transaction, options *databases.BatchOptions) error {
+	uuid := ""
+	if len(transactions) > 0 {
+		uuid = transactions[0].UUID
+	}
+	if err := db.call(ctx, "BatchWriteTransactions", uuid); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, transaction := range transactions {
+		db.store(transaction)
+	}
+	return nil
+}
+
+// ExecuteTransactWrite implements databases.Database
+func (db *DB) ExecuteTransactWrite(ctx context.Context, transactions []*databases.Transaction) error {
+	if err := db.call(ctx, "ExecuteTransactWrite", ""); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, transaction := range transactions {
+		db.store(transaction)
+	}
+	return nil
+}
+
+// ExecuteTransactRead implements databases.Database; unlike a batch read, a missing
+// key fails the whole read
+func (db *DB) ExecuteTransactRead(ctx context.Context, keys []struct{ AccountID, UUID string }) ([]*databases.Transaction, error) {
+	if err := db.call(ctx, "ExecuteTransactRead", ""); err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	transactions := make([]*databases.Transaction, 0, len(keys))
+	for _, k := range keys {
+		transaction, ok := db.items[key(k.AccountID, k.UUID)]
+		if !ok {
+			return nil, databases.ErrTransactionNotFound
+		}
+		copied := *transaction
+		transactions = append(transactions, &copied)
+	}
+	return transactions, nil
+}
+
+// GetMetrics implements databases.Database
+func (db *DB) GetMetrics() map[string]interface{} {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.Metrics != nil {
+		return db.Metrics
+	}
+	metrics := make(map[string]interface{}, len(db.calls))
+	for method, n := range db.calls {
+		metrics[method] = n
+	}
+	return metrics
+}
+
+// ResetMetrics implements databases.Database
+func (db *DB) ResetMetrics() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.metricResets++
+	db.calls = make(map[string]int)
+}
+
+// Factory is a databases.DatabaseFactory that always returns DB
+type Factory struct {
+	DB *DB
+}
+
+// CreateDatabase implements databases.DatabaseFactory
+func (f *Factory) CreateDatabase(config map[string]interface{}) (databases.Database, error) {
+	return f.DB, nil
+}
+
+var (
+	registerMu sync.Mutex
+	registered = make(map[string]*Factory)
+)
+
+// Register registers a factory under name that returns db, replacing the database of
+// an earlier registration under the same name, since the registry cannot unregister
+func Register(name string, db *DB) {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	if f, ok := registered[name]; ok {
+		f.DB = db
+		return
+	}
+	f := &Factory{DB: db}
+	databases.RegisterFactory(name, f)
+	registered[name] = f
+}
@@ -2,6 +2,9 @@ package metrics
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -14,6 +17,10 @@ const (
 	ReadOperation OperationType = "READ"
 	// WriteOperation represents a write to the database
 	WriteOperation OperationType = "WRITE"
+	// UpdateOperation represents an in-place update of an existing record
+	UpdateOperation OperationType = "UPDATE"
+	// DeleteOperation represents a delete from the database
+	DeleteOperation OperationType = "DELETE"
 	// QueryOperation represents a query operation
 	QueryOperation OperationType = "QUERY"
 	// BatchOperation represents a batch operation
@@ -32,6 +39,7 @@ type TestResult struct {
 	StartTime   time.Time              `json:"startTime"`
 	EndTime     time.Time              `json:"endTime"`
 	Duration    time.Duration          `json:"duration"`
+	WarmupCount int                    `json:"warmupCount,omitempty"`
 	Operations  []*OperationMetric     `json:"operations"`
 	Summary     map[string]interface{} `json:"summary"`
 }
@@ -45,8 +53,10 @@ type OperationMetric struct {
 	ItemCount     int64                  `json:"itemCount"`
 	ByteCount     int64                  `json:"byteCount"`
 	IsColdStart   bool                   `json:"isColdStart"`
+	IsWarmup      bool                   `json:"isWarmup,omitempty"`
 	Error         error                  `json:"error,omitempty"`
 	ErrorMessage  string                 `json:"errorMessage,omitempty"`
+	ErrorCategory ErrorCategory          `json:"errorCategory,omitempty"`
 	CustomMetrics map[string]interface{} `json:"customMetrics,omitempty"`
 }
 
@@ -55,8 +65,17 @@ type Collector struct {
 	mu          sync.Mutex
 	currentTest *TestResult
 	tests       map[string]*TestResult
+	percentiles []float64
+	warmupCount int
+	bucketSize  time.Duration
 }
 
+// defaultPercentiles are reported when no percentile set has been configured
+var defaultPercentiles = []float64{50, 90, 99}
+
+// defaultThroughputBucket is the bucket size of the throughput series when none has been configured
+const defaultThroughputBucket = time.Second
+
 // NewCollector creates a new metrics collector
 func NewCollector() *Collector {
 	return &Collector{
@@ -64,6 +83,48 @@ func NewCollector() *Collector {
 	}
 }
 
+// SetPercentiles configures which latency percentiles EndTest reports.
+// An empty slice restores the default set of 50, 90, and 99.
+func (c *Collector) SetPercentiles(percentiles []float64) error {
+	for _, p := range percentiles {
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("percentile %v out of range (0, 100]", p)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.percentiles = append([]float64(nil), percentiles...)
+	return nil
+}
+
+// SetWarmup configures how many initial operations of each test are treated as
+// warmup. Warmup operations are recorded but excluded from summary aggregates.
+// It also applies to the currently running test, if any.
+func (c *Collector) SetWarmup(n int) {
+	if n < 0 {
+		n = 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.warmupCount = n
+	if c.currentTest != nil {
+		c.currentTest.WarmupCount = n
+	}
+}
+
+// SetThroughputBucket configures the bucket size of the throughputSeries reported by
+// EndTest. A non-positive size restores the default of one second.
+func (c *Collector) SetThroughputBucket(bucketSize time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.bucketSize = bucketSize
+}
+
 // StartTest begins a new test and sets it as the current test
 func (c *Collector) StartTest(name, description, database string, config, parameters map[string]interface{}) {
 	c.mu.Lock()
@@ -78,6 +139,15 @@ func (c *Collector) StartTest(name, description, database string, config, parame
 		StartTime:   time.Now(),
 		Operations:  make([]*OperationMetric, 0),
 		Summary:     make(map[string]interface{}),
+		WarmupCount: c.warmupCount,
+	}
+
+	// Allow the warmup count to be overridden per test; negative counts mean no warmup
+	switch v := parameters["warmupCount"].(type) {
+	case int:
+		c.currentTest.WarmupCount = max(v, 0)
+	case float64:
+		c.currentTest.WarmupCount = max(int(v), 0)
 	}
 
 	c.tests[name] = c.currentTest
@@ -90,6 +160,37 @@ func (c *Collector) MeasureOperation(
 	byteCount int64,
 	isColdStart bool,
 	operation func() error,
+) error {
+	return c.MeasureOperationWithCustomMetrics(opType, itemCount, byteCount, isColdStart, nil, operation)
+}
+
+// MeasureOperationWithCustomMetrics measures a single operation and attaches the given
+// custom metrics to the recorded OperationMetric
+func (c *Collector) MeasureOperationWithCustomMetrics(
+	opType OperationType,
+	itemCount int64,
+	byteCount int64,
+	isColdStart bool,
+	customMetrics map[string]interface{},
+	operation func() error,
+) error {
+	if operation == nil {
+		return fmt.Errorf("operation function cannot be nil")
+	}
+
+	return c.MeasureOperationWithResult(opType, itemCount, byteCount, isColdStart, func() (map[string]interface{}, error) {
+		return customMetrics, operation()
+	})
+}
+
+// MeasureOperationWithResult measures a single operation whose closure returns custom
+// metrics, such as the AWS request ID of the call, to attach to the recorded OperationMetric
+func (c *Collector) MeasureOperationWithResult(
+	opType OperationType,
+	itemCount int64,
+	byteCount int64,
+	isColdStart bool,
+	operation func() (map[string]interface{}, error),
 ) error {
 	if operation == nil {
 		return fmt.Errorf("operation function cannot be nil")
@@ -110,13 +211,57 @@ func (c *Collector) MeasureOperation(
 		IsColdStart: isColdStart,
 	}
 
-	err := operation()
+	customMetrics, err := operation()
+	metric.CustomMetrics = customMetrics
+	c.recordMetric(metric, err)
+
+	return err
+}
+
+// MeasureCountedOperation measures a single operation whose item count is only known
+// once it returns, such as a scan page. The closure returns the number of items it
+// processed, and the recorded byte count is that number times itemBytes.
+func (c *Collector) MeasureCountedOperation(
+	opType OperationType,
+	itemBytes int64,
+	isColdStart bool,
+	operation func() (int64, error),
+) error {
+	if operation == nil {
+		return fmt.Errorf("operation function cannot be nil")
+	}
+
+	c.mu.Lock()
+	if c.currentTest == nil {
+		c.mu.Unlock()
+		return fmt.Errorf("no test is currently running")
+	}
+	c.mu.Unlock()
+
+	metric := &OperationMetric{
+		Type:        opType,
+		StartTime:   time.Now(),
+		IsColdStart: isColdStart,
+	}
+
+	itemCount, err := operation()
+	metric.ItemCount = itemCount
+	metric.ByteCount = itemCount * itemBytes
+	c.recordMetric(metric, err)
+
+	return err
+}
+
+// recordMetric stamps the end time and error details on a measured operation and
+// appends it to the current test
+func (c *Collector) recordMetric(metric *OperationMetric, err error) {
 	metric.EndTime = time.Now()
 	metric.Duration = metric.EndTime.Sub(metric.StartTime)
 
 	if err != nil {
 		metric.Error = err
 		metric.ErrorMessage = err.Error()
+		metric.ErrorCategory = classifyError(err)
 	}
 
 	c.mu.Lock()
@@ -125,8 +270,6 @@ func (c *Collector) MeasureOperation(
 	if c.currentTest != nil {
 		c.currentTest.Operations = append(c.currentTest.Operations, metric)
 	}
-
-	return err
 }
 
 // AddCustomMetric adds a custom metric to the current test
@@ -155,29 +298,78 @@ func (c *Collector) EndTest(testName string) *TestResult {
 	test.EndTime = time.Now()
 	test.Duration = test.EndTime.Sub(test.StartTime)
 
+	// Separate warmup operations from the measured ones
+	warmupCount := min(max(test.WarmupCount, 0), len(test.Operations))
+	warmupOps := test.Operations[:warmupCount]
+	measuredOps := test.Operations[warmupCount:]
+
+	if warmupCount > 0 {
+		var warmupDuration time.Duration
+		for _, op := range warmupOps {
+			op.IsWarmup = true
+			warmupDuration += op.Duration
+		}
+		test.Summary["warmupCount"] = int64(warmupCount)
+		test.Summary["warmupAvgDurationNs"] = warmupDuration.Nanoseconds() / int64(warmupCount)
+	}
+
+	// Throughput is measured over the window covered by the non-warmup operations
+	measuredWindow := test.Duration
+	if warmupCount > 0 && len(measuredOps) > 0 {
+		windowStart := measuredOps[0].StartTime
+		for _, op := range measuredOps {
+			if op.StartTime.Before(windowStart) {
+				windowStart = op.StartTime
+			}
+		}
+		measuredWindow = test.EndTime.Sub(windowStart)
+	}
+
 	// Calculate summary metrics
 	var totalDuration time.Duration
 	var totalItems, totalBytes int64
 	var successCount, errorCount int64
 	var coldStartCount int64
+	var coldDuration, warmDuration time.Duration
+	var throttledCount, timeoutCount, notFoundCount int64
+	var minDuration, maxDuration time.Duration
 
-	for _, op := range test.Operations {
+	for i, op := range measuredOps {
 		totalDuration += op.Duration
+		if i == 0 || op.Duration < minDuration {
+			minDuration = op.Duration
+		}
+		if op.Duration > maxDuration {
+			maxDuration = op.Duration
+		}
 		totalItems += op.ItemCount
 		totalBytes += op.ByteCount
 
-		if op.Error != nil {
-			errorCount++
-		} else {
+		// Misses are reported in notFoundCount rather than as errors
+		if op.Error == nil {
 			successCount++
+		} else if op.ErrorCategory != ErrorCategoryNotFound {
+			errorCount++
+		}
+
+		switch op.ErrorCategory {
+		case ErrorCategoryThrottled:
+			throttledCount++
+		case ErrorCategoryTimeout:
+			timeoutCount++
+		case ErrorCategoryNotFound:
+			notFoundCount++
 		}
 
 		if op.IsColdStart {
 			coldStartCount++
+			coldDuration += op.Duration
+		} else {
+			warmDuration += op.Duration
 		}
 	}
 
-	opCount := int64(len(test.Operations))
+	opCount := int64(len(measuredOps))
 
 	// Populate summary metrics
 	if opCount > 0 {
@@ -188,32 +380,55 @@ func (c *Collector) EndTest(testName string) *TestResult {
 		test.Summary["totalBytes"] = totalBytes
 		test.Summary["successCount"] = successCount
 		test.Summary["errorCount"] = errorCount
+		test.Summary["throttledCount"] = throttledCount
+		test.Summary["timeoutCount"] = timeoutCount
+		test.Summary["notFoundCount"] = notFoundCount
 		test.Summary["successRate"] = float64(successCount) / float64(opCount)
-		test.Summary["throughputItems"] = float64(totalItems) / test.Duration.Seconds()
-		test.Summary["throughputBytes"] = float64(totalBytes) / test.Duration.Seconds()
+		test.Summary["throughputItems"] = float64(totalItems) / measuredWindow.Seconds()
+		test.Summary["throughputBytes"] = float64(totalBytes) / measuredWindow.Seconds()
 		test.Summary["coldStartCount"] = coldStartCount
+		if coldStartCount > 0 {
+			test.Summary["coldStartAvgNs"] = coldDuration.Nanoseconds() / coldStartCount
+		}
+		if warmCount := opCount - coldStartCount; warmCount > 0 {
+			test.Summary["warmAvgNs"] = warmDuration.Nanoseconds() / warmCount
+		}
+		test.Summary["minDurationNs"] = minDuration.Nanoseconds()
+		test.Summary["maxDurationNs"] = maxDuration.Nanoseconds()
+
+		// Population standard deviation of operation durations
+		mean := float64(totalDuration.Nanoseconds()) / float64(opCount)
+		var sumSquares float64
+		for _, op := range measuredOps {
+			diff := float64(op.Duration.Nanoseconds()) - mean
+			sumSquares += diff * diff
+		}
+		test.Summary["stdDevDurationNs"] = math.Sqrt(sumSquares / float64(opCount))
 
 		// Calculate percentiles if we have enough data
 		if opCount >= 10 {
 			durations := make([]int64, 0, opCount)
-			for _, op := range test.Operations {
+			for _, op := range measuredOps {
 				durations = append(durations, op.Duration.Nanoseconds())
 			}
 
-			// Sort the durations
-			for i := int64(0); i < opCount; i++ {
-				for j := i + 1; j < opCount; j++ {
-					if durations[i] > durations[j] {
-						durations[i], durations[j] = durations[j], durations[i]
-					}
-				}
-			}
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
 
 			// Calculate percentiles
-			test.Summary["p50"] = durations[opCount*50/100]
-			test.Summary["p90"] = durations[opCount*90/100]
-			test.Summary["p99"] = durations[opCount*99/100]
+			for _, p := range c.activePercentiles() {
+				test.Summary[percentileKey(p)] = percentile(durations, p)
+			}
 		}
+
+		test.Summary["byOperationType"] = summarizeByOperationType(measuredOps, c.activePercentiles())
+		test.Summary["latencyHistogram"] = summarizeLatencyHistograms(measuredOps, c.activePercentiles())
+
+		bucketSize := c.bucketSize
+		if bucketSize <= 0 {
+			bucketSize = defaultThroughputBucket
+		}
+		test.Summary["throughputSeries"] = test.ThroughputOverTime(bucketSize)
+		test.Summary["throughputBucketMs"] = bucketSize.Milliseconds()
 	}
 
 	// Clear current test if this is the one that was active
@@ -224,6 +439,77 @@ func (c *Collector) EndTest(testName string) *TestResult {
 	return test
 }
 
+// summarizeByOperationType computes latency statistics separately for each
+// operation type so that mixed workloads don't blend read and write latencies.
+func summarizeByOperationType(ops []*OperationMetric, percentiles []float64) map[string]interface{} {
+	durationsByType := make(map[OperationType][]int64)
+	errorsByType := make(map[OperationType]int64)
+
+	for _, op := range ops {
+		durationsByType[op.Type] = append(durationsByType[op.Type], op.Duration.Nanoseconds())
+		if op.Error != nil && op.ErrorCategory != ErrorCategoryNotFound {
+			errorsByType[op.Type]++
+		}
+	}
+
+	breakdown := make(map[string]interface{}, len(durationsByType))
+	for opType, durations := range durationsByType {
+		var total int64
+		for _, d := range durations {
+			total += d
+		}
+
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+		count := int64(len(durations))
+		entry := map[string]interface{}{
+			"count":         count,
+			"avgDurationNs": total / count,
+			"errorCount":    errorsByType[opType],
+		}
+		for _, p := range percentiles {
+			entry[percentileKey(p)] = percentile(durations, p)
+		}
+		breakdown[string(opType)] = entry
+	}
+
+	return breakdown
+}
+
+// activePercentiles returns the configured percentiles or the defaults. Callers must hold c.mu.
+func (c *Collector) activePercentiles() []float64 {
+	if len(c.percentiles) == 0 {
+		return defaultPercentiles
+	}
+	return c.percentiles
+}
+
+// percentileKey formats a percentile as a summary key, e.g. 95 -> "p95", 99.9 -> "p99.9"
+func percentileKey(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// percentile returns the p-th percentile of an ascending-sorted slice using the
+// nearest-rank method: the value at rank ceil(p/100 * n), clamped to [1, n].
+func percentile(sorted []int64, p float64) int64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+
+	// Round away the float error of fractional percentiles first, e.g. 99.9/100 * 1000
+	// is 999.0000000000001 and would otherwise take rank 1000
+	rank := int(math.Ceil(math.Round(p/100*float64(n)*1e6) / 1e6))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > n {
+		rank = n
+	}
+
+	return sorted[rank-1]
+}
+
 // GetTestResult retrieves a test result by name
 func (c *Collector) GetTestResult(name string) *TestResult {
 	c.mu.Lock()
@@ -240,3 +526,45 @@ func (c *Collector) ResetCollector() {
 	c.currentTest = nil
 	c.tests = make(map[string]*TestResult)
 }
+
+// ThroughputOverTime buckets the non-warmup operations by their start time relative to
+// the test start and returns the items per second of each bucket. The final bucket is
+// divided by its actual length so a partial bucket is not understated.
+func (t *TestResult) ThroughputOverTime(bucketSize time.Duration) []float64 {
+	if bucketSize <= 0 {
+		return nil
+	}
+
+	duration := t.Duration
+	for _, op := range t.Operations {
+		if offset := op.StartTime.Sub(t.StartTime); offset >= duration {
+			duration = offset + 1
+		}
+	}
+	if duration <= 0 {
+		return []float64{}
+	}
+
+	bucketCount := int((duration + bucketSize - 1) / bucketSize)
+	items := make([]int64, bucketCount)
+	for _, op := range t.Operations {
+		if op.IsWarmup {
+			continue
+		}
+		offset := op.StartTime.Sub(t.StartTime)
+		if offset < 0 {
+			offset = 0
+		}
+		items[int(offset/bucketSize)] += op.ItemCount
+	}
+
+	series := make([]float64, bucketCount)
+	for i, count := range items {
+		width := bucketSize
+		if remaining := duration - time.Duration(i)*bucketSize; remaining < width {
+			width = remaining
+		}
+		series[i] = float64(count) / width.Seconds()
+	}
+	return series
+}
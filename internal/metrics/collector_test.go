@@ -0,0 +1,562 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+func TestPercentile(t *testing.T) {
+	oneToTen := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		name   string
+		sorted []int64
+		p      float64
+		want   int64
+	}{
+		{"empty", nil, 50, 0},
+		{"single value", []int64{42}, 99, 42},
+		{"p50", oneToTen, 50, 5},
+		{"p90", oneToTen, 90, 9},
+		{"p99 rounds up to the last rank", oneToTen, 99, 10},
+		{"p100", oneToTen, 100, 10},
+		{"p0 clamps to the first rank", oneToTen, 0, 1},
+		{"fractional percentile", oneToTen, 99.9, 10},
+		{"fractional percentile on an exact rank", ascending(1000), 99.9, 999},
+		{"p95 of 1000 values", ascending(1000), 95, 950},
+		{"p25 of four values", []int64{10, 20, 30, 40}, 25, 10},
+		{"p26 of four values", []int64{10, 20, 30, 40}, 26, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile(%v, %v) = %d, want %d", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentileKey(t *testing.T) {
+	tests := []struct {
+		p    float64
+		want string
+	}{
+		{50, "p50"},
+		{95, "p95"},
+		{99.9, "p99.9"},
+	}
+
+	for _, tt := range tests {
+		if got := percentileKey(tt.p); got != tt.want {
+			t.Errorf("percentileKey(%v) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeByOperationType(t *testing.T) {
+	var ops []*OperationMetric
+	// Reads take 1ms to 10ms, listed out of order
+	for _, ms := range []int{7, 3, 10, 1, 5, 9, 2, 8, 4, 6} {
+		ops = append(ops, &OperationMetric{Type: ReadOperation, Duration: time.Duration(ms) * time.Millisecond})
+	}
+	// A miss is not an error; a missing table is
+	ops = append(ops,
+		&OperationMetric{Type: WriteOperation, Duration: 20 * time.Millisecond},
+		&OperationMetric{
+			Type:          WriteOperation,
+			Duration:      40 * time.Millisecond,
+			Error:         databases.ErrTransactionNotFound,
+			ErrorCategory: ErrorCategoryNotFound,
+		},
+		&OperationMetric{
+			Type:          WriteOperation,
+			Duration:      60 * time.Millisecond,
+			Error:         errors.New("table not found"),
+			ErrorCategory: ErrorCategoryOther,
+		},
+	)
+
+	breakdown := summarizeByOperationType(ops, []float64{50, 90, 99})
+
+	tests := []struct {
+		opType OperationType
+		key    string
+		want   int64
+	}{
+		{ReadOperation, "count", 10},
+		{ReadOperation, "avgDurationNs", int64(5500 * time.Microsecond)},
+		{ReadOperation, "errorCount", 0},
+		{ReadOperation, "p50", int64(5 * time.Millisecond)},
+		{ReadOperation, "p90", int64(9 * time.Millisecond)},
+		{ReadOperation, "p99", int64(10 * time.Millisecond)},
+		{WriteOperation, "count", 3},
+		{WriteOperation, "avgDurationNs", int64(40 * time.Millisecond)},
+		{WriteOperation, "errorCount", 1},
+		{WriteOperation, "p50", int64(40 * time.Millisecond)},
+		{WriteOperation, "p99", int64(60 * time.Millisecond)},
+	}
+
+	if len(breakdown) != 2 {
+		t.Fatalf("breakdown has %d operation types, want 2: %v", len(breakdown), breakdown)
+	}
+	for _, tt := range tests {
+		entry, ok := breakdown[string(tt.opType)].(map[string]interface{})
+		if !ok {
+			t.Fatalf("breakdown has no entry for %s", tt.opType)
+		}
+		if got := entry[tt.key]; got != tt.want {
+			t.Errorf("%s %s = %v, want %d", tt.opType, tt.key, got, tt.want)
+		}
+	}
+}
+
+// endTestWith runs a test on c whose recorded operations are ops, so EndTest summarizes
+// known durations instead of measured ones
+func endTestWith(t *testing.T, c *Collector, params map[string]interface{}, ops []*OperationMetric) *TestResult {
+	t.Helper()
+	c.StartTest(t.Name(), "", "test", nil, params)
+	c.mu.Lock()
+	for _, op := range ops {
+		if op.StartTime.IsZero() {
+			op.StartTime = c.currentTest.StartTime
+		}
+	}
+	c.currentTest.Operations = append(c.currentTest.Operations, ops...)
+	c.mu.Unlock()
+
+	result := c.EndTest(t.Name())
+	if result == nil {
+		t.Fatal("EndTest() = nil, want the test result")
+	}
+	return result
+}
+
+// opsWithDurations returns one operation of the given type per duration in milliseconds
+func opsWithDurations(opType OperationType, ms ...int) []*OperationMetric {
+	ops := make([]*OperationMetric, 0, len(ms))
+	for _, d := range ms {
+		ops = append(ops, &OperationMetric{Type: opType, Duration: time.Duration(d) * time.Millisecond, ItemCount: 1})
+	}
+	return ops
+}
+
+// ascending returns 1 through n in ascending order
+func ascending(n int) []int64 {
+	sorted := make([]int64, n)
+	for i := range sorted {
+		sorted[i] = int64(i + 1)
+	}
+	return sorted
+}
+
+// shuffledRange returns 1 through n in a fixed shuffled order
+func shuffledRange(n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i + 1
+	}
+	rand.New(rand.NewSource(1)).Shuffle(n, func(i, j int) { values[i], values[j] = values[j], values[i] })
+	return values
+}
+
+func TestEndTestPercentiles(t *testing.T) {
+	ms := time.Millisecond.Nanoseconds()
+	tests := []struct {
+		name string
+		ops  int
+		want map[string]int64 // nil when no percentiles are reported
+	}{
+		{"1000 operations", 1000, map[string]int64{"p50": 500 * ms, "p90": 900 * ms, "p99": 990 * ms}},
+		{"exactly 10 operations", 10, map[string]int64{"p50": 5 * ms, "p90": 9 * ms, "p99": 10 * ms}},
+		{"fewer than 10 operations", 9, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := endTestWith(t, NewCollector(), nil, opsWithDurations(ReadOperation, shuffledRange(tt.ops)...))
+
+			for _, key := range []string{"p50", "p90", "p99"} {
+				got, ok := result.Summary[key]
+				if tt.want == nil {
+					if ok {
+						t.Errorf("%s = %v, want no percentiles below 10 operations", key, got)
+					}
+					continue
+				}
+				if got != tt.want[key] {
+					t.Errorf("%s = %v, want %d", key, got, tt.want[key])
+				}
+			}
+
+			// The per-type breakdown reports the same percentiles at any count
+			reads := result.Summary["byOperationType"].(map[string]interface{})[string(ReadOperation)].(map[string]interface{})
+			if reads["count"] != int64(tt.ops) {
+				t.Errorf("byOperationType READ count = %v, want %d", reads["count"], tt.ops)
+			}
+			if tt.want != nil && reads["p99"] != tt.want["p99"] {
+				t.Errorf("byOperationType READ p99 = %v, want %d", reads["p99"], tt.want["p99"])
+			}
+		})
+	}
+}
+
+func TestEndTestByOperationType(t *testing.T) {
+	// 50 reads of 1-50ms and 50 writes of 101-150ms, interleaved as a mixed run records them
+	var ops []*OperationMetric
+	for i := 1; i <= 50; i++ {
+		ops = append(ops, opsWithDurations(ReadOperation, i)...)
+		ops = append(ops, opsWithDurations(WriteOperation, 100+i)...)
+	}
+	result := endTestWith(t, NewCollector(), nil, ops)
+
+	ms := time.Millisecond.Nanoseconds()
+	tests := []struct {
+		opType OperationType
+		key    string
+		want   int64
+	}{
+		{ReadOperation, "count", 50},
+		{ReadOperation, "avgDurationNs", 25*ms + ms/2},
+		{ReadOperation, "p50", 25 * ms},
+		{ReadOperation, "p99", 50 * ms},
+		{WriteOperation, "count", 50},
+		{WriteOperation, "avgDurationNs", 125*ms + ms/2},
+		{WriteOperation, "p50", 125 * ms},
+		{WriteOperation, "p99", 150 * ms},
+	}
+
+	breakdown, ok := result.Summary["byOperationType"].(map[string]interface{})
+	if !ok || len(breakdown) != 2 {
+		t.Fatalf("byOperationType = %v, want READ and WRITE entries", result.Summary["byOperationType"])
+	}
+	for _, tt := range tests {
+		entry := breakdown[string(tt.opType)].(map[string]interface{})
+		if got := entry[tt.key]; got != tt.want {
+			t.Errorf("%s %s = %v, want %d", tt.opType, tt.key, got, tt.want)
+		}
+	}
+
+	// The blended p50 is the slowest read, hiding both distributions
+	if got := result.Summary["p50"]; got != 50*ms {
+		t.Errorf("overall p50 = %v, want %d", got, 50*ms)
+	}
+}
+
+func TestEndTestDistribution(t *testing.T) {
+	tests := []struct {
+		name       string
+		ms         []int
+		wantMin    time.Duration
+		wantMax    time.Duration
+		wantStdDev time.Duration
+	}{
+		// Mean 5ms; the squared deviations sum to 32ms² over 8 operations
+		{"spread", []int{9, 2, 4, 4, 5, 4, 7, 5}, 2 * time.Millisecond, 9 * time.Millisecond, 2 * time.Millisecond},
+		{"single operation", []int{3}, 3 * time.Millisecond, 3 * time.Millisecond, 0},
+		{"identical durations", []int{4, 4, 4}, 4 * time.Millisecond, 4 * time.Millisecond, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := endTestWith(t, NewCollector(), nil, opsWithDurations(ReadOperation, tt.ms...))
+
+			if got := result.Summary["minDurationNs"]; got != tt.wantMin.Nanoseconds() {
+				t.Errorf("minDurationNs = %v, want %d", got, tt.wantMin.Nanoseconds())
+			}
+			if got := result.Summary["maxDurationNs"]; got != tt.wantMax.Nanoseconds() {
+				t.Errorf("maxDurationNs = %v, want %d", got, tt.wantMax.Nanoseconds())
+			}
+			got, _ := result.Summary["stdDevDurationNs"].(float64)
+			if math.Abs(got-float64(tt.wantStdDev.Nanoseconds())) > 1e-6 {
+				t.Errorf("stdDevDurationNs = %v, want %d", got, tt.wantStdDev.Nanoseconds())
+			}
+		})
+	}
+}
+
+func TestEndTestDistributionExcludesWarmup(t *testing.T) {
+	// The 100ms warmup would otherwise be both the maximum and most of the spread
+	ops := opsWithDurations(ReadOperation, 100, 2, 4, 6)
+	result := endTestWith(t, NewCollector(), map[string]interface{}{"warmupCount": 1}, ops)
+
+	if result.Summary["minDurationNs"] != (2*time.Millisecond).Nanoseconds() || result.Summary["maxDurationNs"] != (6*time.Millisecond).Nanoseconds() {
+		t.Errorf("min = %v, max = %v, want 2ms and 6ms", result.Summary["minDurationNs"], result.Summary["maxDurationNs"])
+	}
+}
+
+func TestSetPercentiles(t *testing.T) {
+	c := NewCollector()
+	if err := c.SetPercentiles([]float64{50, 95, 99.9}); err != nil {
+		t.Fatalf("SetPercentiles() error = %v", err)
+	}
+	result := endTestWith(t, c, nil, opsWithDurations(ReadOperation, shuffledRange(1000)...))
+
+	ms := time.Millisecond.Nanoseconds()
+	want := map[string]int64{"p50": 500 * ms, "p95": 950 * ms, "p99.9": 999 * ms}
+	for key, value := range want {
+		if got := result.Summary[key]; got != value {
+			t.Errorf("%s = %v, want %d", key, got, value)
+		}
+	}
+	// Only the configured set is reported
+	for _, key := range []string{"p90", "p99"} {
+		if got, ok := result.Summary[key]; ok {
+			t.Errorf("%s = %v, want it left out", key, got)
+		}
+	}
+	reads := result.Summary["byOperationType"].(map[string]interface{})[string(ReadOperation)].(map[string]interface{})
+	if reads["p99.9"] != 999*ms {
+		t.Errorf("byOperationType READ p99.9 = %v, want %d", reads["p99.9"], 999*ms)
+	}
+}
+
+func TestSetPercentilesInvalid(t *testing.T) {
+	tests := [][]float64{
+		{0},
+		{-5},
+		{100.1},
+		{50, 150},
+	}
+
+	for _, percentiles := range tests {
+		c := NewCollector()
+		if err := c.SetPercentiles([]float64{75}); err != nil {
+			t.Fatalf("SetPercentiles() error = %v", err)
+		}
+		if err := c.SetPercentiles(percentiles); err == nil {
+			t.Errorf("SetPercentiles(%v) error = nil, want an out of range error", percentiles)
+		}
+
+		// A rejected set leaves the previous configuration in place
+		if got := c.activePercentiles(); len(got) != 1 || got[0] != 75 {
+			t.Errorf("after SetPercentiles(%v) percentiles = %v, want [75]", percentiles, got)
+		}
+	}
+
+	// An empty set restores the defaults
+	c := NewCollector()
+	c.SetPercentiles([]float64{75})
+	if err := c.SetPercentiles(nil); err != nil {
+		t.Fatalf("SetPercentiles(nil) error = %v", err)
+	}
+	if got := c.activePercentiles(); len(got) != 3 || got[0] != 50 || got[1] != 90 || got[2] != 99 {
+		t.Errorf("percentiles = %v, want the defaults [50 90 99]", got)
+	}
+}
+
+func TestEndTestWarmup(t *testing.T) {
+	// Five slow warmup operations followed by ten fast measured ones
+	ops := append(opsWithDurations(ReadOperation, 100, 100, 100, 100, 100), opsWithDurations(ReadOperation, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2)...)
+
+	tests := []struct {
+		name   string
+		warmup func(c *Collector)
+		params map[string]interface{}
+	}{
+		{"SetWarmup", func(c *Collector) { c.SetWarmup(5) }, nil},
+		// JSON requests decode the parameter as float64
+		{"warmupCount parameter", func(c *Collector) {}, map[string]interface{}{"warmupCount": float64(5)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCollector()
+			tt.warmup(c)
+			result := endTestWith(t, c, tt.params, cloneOps(ops))
+
+			ms := time.Millisecond.Nanoseconds()
+			want := map[string]interface{}{
+				"warmupCount":         int64(5),
+				"warmupAvgDurationNs": 100 * ms,
+				"operationCount":      int64(10),
+				"p50":                 1 * ms,
+				"p90":                 2 * ms,
+				"avgDuration":         ms + ms/2,
+			}
+			for key, value := range want {
+				if got := result.Summary[key]; got != value {
+					t.Errorf("%s = %v, want %v", key, got, value)
+				}
+			}
+			for i, op := range result.Operations {
+				if op.IsWarmup != (i < 5) {
+					t.Errorf("operation %d IsWarmup = %v, want %v", i, op.IsWarmup, i < 5)
+				}
+			}
+		})
+	}
+}
+
+func TestEndTestWarmupBounds(t *testing.T) {
+	tests := []struct {
+		name        string
+		warmupCount interface{}
+		wantWarmup  interface{} // nil when no warmup is reported
+		wantOps     interface{}
+	}{
+		{"negative count", -3, nil, int64(4)},
+		{"more than recorded", 10, int64(4), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := endTestWith(t, NewCollector(), map[string]interface{}{"warmupCount": tt.warmupCount}, opsWithDurations(ReadOperation, 1, 2, 3, 4))
+
+			if got := result.Summary["warmupCount"]; got != tt.wantWarmup {
+				t.Errorf("warmupCount = %v, want %v", got, tt.wantWarmup)
+			}
+			if got := result.Summary["operationCount"]; got != tt.wantOps {
+				t.Errorf("operationCount = %v, want %v", got, tt.wantOps)
+			}
+		})
+	}
+}
+
+// cloneOps copies ops so each test marks its own warmup operations
+func cloneOps(ops []*OperationMetric) []*OperationMetric {
+	cloned := make([]*OperationMetric, len(ops))
+	for i, op := range ops {
+		copied := *op
+		cloned[i] = &copied
+	}
+	return cloned
+}
+
+func TestThroughputOverTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration, items int64, warmup bool) *OperationMetric {
+		return &OperationMetric{StartTime: start.Add(offset), ItemCount: items, IsWarmup: warmup}
+	}
+	result := &TestResult{
+		StartTime: start,
+		Duration:  2500 * time.Millisecond,
+		Operations: []*OperationMetric{
+			at(200*time.Millisecond, 100, true), // Warmup is left out
+			at(100*time.Millisecond, 10, false),
+			at(900*time.Millisecond, 5, false),
+			at(time.Second, 20, false), // A bucket boundary belongs to the later bucket
+			at(2200*time.Millisecond, 3, false),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		bucketSize time.Duration
+		want       []float64
+	}{
+		// The last bucket covers only 500ms, so 3 items is 6 per second
+		{"one second", time.Second, []float64{15, 20, 6}},
+		{"500ms", 500 * time.Millisecond, []float64{20, 10, 40, 0, 6}},
+		{"one bucket", 10 * time.Second, []float64{38 / 2.5}},
+		{"zero bucket size", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := result.ThroughputOverTime(tt.bucketSize)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ThroughputOverTime(%v) = %v, want %v", tt.bucketSize, got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+					t.Errorf("bucket %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestThroughputOverTimeLateOperation(t *testing.T) {
+	// An operation starting after the recorded duration still gets a bucket
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := &TestResult{
+		StartTime:  start,
+		Duration:   time.Second,
+		Operations: []*OperationMetric{{StartTime: start.Add(1500 * time.Millisecond), ItemCount: 4}},
+	}
+
+	got := result.ThroughputOverTime(time.Second)
+	if len(got) != 2 || got[0] != 0 || got[1] <= 0 {
+		t.Errorf("ThroughputOverTime() = %v, want an empty first bucket and the operation in the second", got)
+	}
+}
+
+func TestEndTestThroughputBucket(t *testing.T) {
+	c := NewCollector()
+	c.SetThroughputBucket(250 * time.Millisecond)
+	result := endTestWith(t, c, nil, opsWithDurations(WriteOperation, 1, 1, 1))
+
+	if got := result.Summary["throughputBucketMs"]; got != int64(250) {
+		t.Errorf("throughputBucketMs = %v, want 250", got)
+	}
+	series, ok := result.Summary["throughputSeries"].([]float64)
+	if !ok || len(series) == 0 {
+		t.Fatalf("throughputSeries = %v, want a bucketed series", result.Summary["throughputSeries"])
+	}
+	// All three operations start with the test, so they fall in the first bucket
+	if series[0] <= 0 {
+		t.Errorf("first bucket = %v, want the three writes", series[0])
+	}
+}
+
+func TestMeasureOperationWithResult(t *testing.T) {
+	c := NewCollector()
+	c.StartTest(t.Name(), "", "test", nil, nil)
+
+	// Custom metrics are attached whether or not the operation fails
+	c.MeasureOperationWithResult(ReadOperation, 1, 64, false, func() (map[string]interface{}, error) {
+		return map[string]interface{}{"awsRequestId": "req-1", "retryAttempts": 2}, nil
+	})
+	failure := errors.New("throttled")
+	err := c.MeasureOperationWithResult(WriteOperation, 1, 64, false, func() (map[string]interface{}, error) {
+		return map[string]interface{}{"awsRequestId": "req-2"}, failure
+	})
+	if err != failure {
+		t.Errorf("MeasureOperationWithResult() error = %v, want the operation's error", err)
+	}
+	c.MeasureOperationWithCustomMetrics(ReadOperation, 1, 64, false, map[string]interface{}{"awsRequestId": "req-3"}, func() error {
+		return nil
+	})
+	c.MeasureOperation(ReadOperation, 1, 64, false, func() error { return nil })
+
+	result := c.EndTest(t.Name())
+	if result == nil || len(result.Operations) != 4 {
+		t.Fatalf("EndTest() = %v, want 4 recorded operations", result)
+	}
+
+	wantRequestIDs := []interface{}{"req-1", "req-2", "req-3", nil}
+	for i, op := range result.Operations {
+		if got := op.CustomMetrics["awsRequestId"]; got != wantRequestIDs[i] {
+			t.Errorf("operation %d awsRequestId = %v, want %v", i, got, wantRequestIDs[i])
+		}
+	}
+	if got := result.Operations[0].CustomMetrics["retryAttempts"]; got != 2 {
+		t.Errorf("retryAttempts = %v, want 2", got)
+	}
+	if result.Operations[1].ErrorMessage != "throttled" {
+		t.Errorf("ErrorMessage = %q, want the operation's error", result.Operations[1].ErrorMessage)
+	}
+}
+
+func TestMeasureOperationWithResultErrors(t *testing.T) {
+	c := NewCollector()
+	noop := func() (map[string]interface{}, error) { return nil, nil }
+
+	if err := c.MeasureOperationWithResult(ReadOperation, 1, 0, false, noop); err == nil {
+		t.Error("MeasureOperationWithResult() without a running test error = nil, want an error")
+	}
+
+	c.StartTest(t.Name(), "", "test", nil, nil)
+	if err := c.MeasureOperationWithResult(ReadOperation, 1, 0, false, nil); err == nil {
+		t.Error("MeasureOperationWithResult(nil) error = nil, want an error")
+	}
+	if result := c.EndTest(t.Name()); len(result.Operations) != 0 {
+		t.Errorf("recorded %d operations, want none", len(result.Operations))
+	}
+}
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// EMFNamespace is the CloudWatch namespace used for benchmark metrics
+const EMFNamespace = "LambdaGopherBenchmark"
+
+// EmitEMF writes the test summary as a CloudWatch Embedded Metric Format (EMF) log line.
+// When written to stdout inside Lambda, CloudWatch extracts the metrics automatically.
+func EmitEMF(test *TestResult, w io.Writer) error {
+	if test == nil {
+		return fmt.Errorf("test result cannot be nil")
+	}
+
+	operationType := ""
+	if v, ok := test.Config["operationType"].(string); ok {
+		operationType = v
+	}
+
+	var errorRate float64
+	if opCount := summaryFloat(test.Summary, "operationCount"); opCount > 0 {
+		errorRate = summaryFloat(test.Summary, "errorCount") / opCount
+	}
+
+	// Summary durations are in nanoseconds; CloudWatch has no nanosecond unit
+	avgLatencyMs := summaryFloat(test.Summary, "avgDuration") / float64(time.Millisecond)
+
+	timestamp := test.EndTime
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	document := map[string]interface{}{
+		"_aws": map[string]interface{}{
+			"Timestamp": timestamp.UnixMilli(),
+			"CloudWatchMetrics": []map[string]interface{}{
+				{
+					"Namespace":  EMFNamespace,
+					"Dimensions": [][]string{{"Database", "OperationType"}},
+					"Metrics": []map[string]string{
+						{"Name": "Throughput", "Unit": "Count/Second"},
+						{"Name": "AvgLatency", "Unit": "Milliseconds"},
+						{"Name": "ErrorRate", "Unit": "None"},
+					},
+				},
+			},
+		},
+		"Database":      test.Database,
+		"OperationType": operationType,
+		"TestName":      test.TestName,
+		"Throughput":    summaryFloat(test.Summary, "throughputItems"),
+		"AvgLatency":    avgLatencyMs,
+		"ErrorRate":     errorRate,
+	}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to marshal EMF document: %w", err)
+	}
+
+	if _, err := fmt.Fprintln(w, string(data)); err != nil {
+		return fmt.Errorf("failed to write EMF document: %w", err)
+	}
+
+	return nil
+}
+
+// summaryFloat reads a numeric summary value as float64, returning 0 if absent
+func summaryFloat(summary map[string]interface{}, key string) float64 {
+	switch v := summary[key].(type) {
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmitEMF(t *testing.T) {
+	endTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		test           *TestResult
+		wantDatabase   string
+		wantOperation  string
+		wantThroughput float64
+		wantLatencyMs  float64
+		wantErrorRate  float64
+	}{
+		{
+			name: "summary metrics",
+			test: &TestResult{
+				TestName: "read-test",
+				Database: "dynamodb",
+				Config:   map[string]interface{}{"operationType": "read"},
+				EndTime:  endTime,
+				Summary: map[string]interface{}{
+					"operationCount":  int64(10),
+					"errorCount":      int64(2),
+					"avgDuration":     int64(5 * time.Millisecond),
+					"throughputItems": 250.0,
+				},
+			},
+			wantDatabase:   "dynamodb",
+			wantOperation:  "read",
+			wantThroughput: 250,
+			wantLatencyMs:  5,
+			wantErrorRate:  0.2,
+		},
+		{
+			name: "no operations",
+			test: &TestResult{
+				TestName: "empty-test",
+				Database: "redis",
+				EndTime:  endTime,
+				Summary:  map[string]interface{}{},
+			},
+			wantDatabase: "redis",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EmitEMF(tt.test, &buf); err != nil {
+				t.Fatalf("EmitEMF() error = %v", err)
+			}
+			if !strings.HasSuffix(buf.String(), "\n") || strings.Count(buf.String(), "\n") != 1 {
+				t.Errorf("EmitEMF() wrote %q, want a single line", buf.String())
+			}
+
+			var document struct {
+				AWS struct {
+					Timestamp         int64 `json:"Timestamp"`
+					CloudWatchMetrics []struct {
+						Namespace  string     `json:"Namespace"`
+						Dimensions [][]string `json:"Dimensions"`
+						Metrics    []struct {
+							Name string `json:"Name"`
+							Unit string `json:"Unit"`
+						} `json:"Metrics"`
+					} `json:"CloudWatchMetrics"`
+				} `json:"_aws"`
+				Database      string  `json:"Database"`
+				OperationType string  `json:"OperationType"`
+				TestName      string  `json:"TestName"`
+				Throughput    float64 `json:"Throughput"`
+				AvgLatency    float64 `json:"AvgLatency"`
+				ErrorRate     float64 `json:"ErrorRate"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+				t.Fatalf("EmitEMF() wrote invalid JSON: %v", err)
+			}
+
+			if document.AWS.Timestamp != endTime.UnixMilli() {
+				t.Errorf("Timestamp = %d, want %d", document.AWS.Timestamp, endTime.UnixMilli())
+			}
+			if len(document.AWS.CloudWatchMetrics) != 1 {
+				t.Fatalf("CloudWatchMetrics has %d directives, want 1", len(document.AWS.CloudWatchMetrics))
+			}
+			directive := document.AWS.CloudWatchMetrics[0]
+			if directive.Namespace != EMFNamespace {
+				t.Errorf("Namespace = %q, want %q", directive.Namespace, EMFNamespace)
+			}
+			if len(directive.Dimensions) != 1 || strings.Join(directive.Dimensions[0], ",") != "Database,OperationType" {
+				t.Errorf("Dimensions = %v, want [[Database OperationType]]", directive.Dimensions)
+			}
+			units := make(map[string]string)
+			for _, m := range directive.Metrics {
+				units[m.Name] = m.Unit
+			}
+			wantUnits := map[string]string{"Throughput": "Count/Second", "AvgLatency": "Milliseconds", "ErrorRate": "None"}
+			for name, unit := range wantUnits {
+				if units[name] != unit {
+					t.Errorf("metric %s unit = %q, want %q", name, units[name], unit)
+				}
+			}
+
+			if document.Database != tt.wantDatabase {
+				t.Errorf("Database = %q, want %q", document.Database, tt.wantDatabase)
+			}
+			if document.OperationType != tt.wantOperation {
+				t.Errorf("OperationType = %q, want %q", document.OperationType, tt.wantOperation)
+			}
+			if document.TestName != tt.test.TestName {
+				t.Errorf("TestName = %q, want %q", document.TestName, tt.test.TestName)
+			}
+			if document.Throughput != tt.wantThroughput {
+				t.Errorf("Throughput = %v, want %v", document.Throughput, tt.wantThroughput)
+			}
+			if document.AvgLatency != tt.wantLatencyMs {
+				t.Errorf("AvgLatency = %v, want %v", document.AvgLatency, tt.wantLatencyMs)
+			}
+			if document.ErrorRate != tt.wantErrorRate {
+				t.Errorf("ErrorRate = %v, want %v", document.ErrorRate, tt.wantErrorRate)
+			}
+		})
+	}
+}
+
+func TestEmitEMFNilTest(t *testing.T) {
+	var buf bytes.Buffer
+	if err := EmitEMF(nil, &buf); err == nil {
+		t.Error("EmitEMF(nil) error = nil, want an error")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("EmitEMF(nil) wrote %q, want nothing", buf.String())
+	}
+}
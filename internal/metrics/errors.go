@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/smithy-go"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// ErrorCategory classifies operation failures so throttling and timeouts
+// can be reported separately from genuine errors
+type ErrorCategory string
+
+const (
+	// ErrorCategoryThrottled represents a request rejected due to throughput limits
+	ErrorCategoryThrottled ErrorCategory = "throttled"
+	// ErrorCategoryTimeout represents a request that exceeded its deadline
+	ErrorCategoryTimeout ErrorCategory = "timeout"
+	// ErrorCategoryNotFound represents a lookup for an item that does not exist. Only
+	// databases.ErrTransactionNotFound is a miss; a missing table or index is an error.
+	ErrorCategoryNotFound ErrorCategory = "notFound"
+	// ErrorCategoryConditionFailed represents a failed conditional write
+	ErrorCategoryConditionFailed ErrorCategory = "conditionFailed"
+	// ErrorCategoryOther represents any other failure
+	ErrorCategoryOther ErrorCategory = "other"
+)
+
+// AWS error codes that indicate throttling
+var throttlingErrorCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"ThrottlingException":                    true,
+	"Throttling":                             true,
+	"RequestLimitExceeded":                   true,
+	"TooManyRequestsException":               true,
+	"LimitExceededException":                 true,
+}
+
+// classifyError determines the category of an operation error by inspecting
+// AWS SDK error codes first and falling back to common message substrings
+func classifyError(err error) ErrorCategory {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCategoryTimeout
+	}
+	if errors.Is(err, databases.ErrTransactionNotFound) {
+		return ErrorCategoryNotFound
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		switch {
+		case throttlingErrorCodes[code]:
+			return ErrorCategoryThrottled
+		case code == "ConditionalCheckFailedException":
+			return ErrorCategoryConditionFailed
+		case code == "RequestTimeout" || code == "RequestTimeoutException":
+			return ErrorCategoryTimeout
+		}
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "throttl") || strings.Contains(msg, "rate exceeded") ||
+		strings.Contains(msg, "throughput exceeded"):
+		return ErrorCategoryThrottled
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "timed out") ||
+		strings.Contains(msg, "deadline exceeded"):
+		return ErrorCategoryTimeout
+	case strings.Contains(msg, "conditional") || strings.Contains(msg, "condition failed"):
+		return ErrorCategoryConditionFailed
+	}
+
+	return ErrorCategoryOther
+}
+
+// IsThrottled reports whether err is a throughput-limit rejection
+func IsThrottled(err error) bool {
+	return classifyError(err) == ErrorCategoryThrottled
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+func TestClassifyError(t *testing.T) {
+	apiError := func(code string) error {
+		return fmt.Errorf("operation error DynamoDB: %w", &smithy.GenericAPIError{Code: code, Message: "request failed"})
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCategory
+	}{
+		{"nil", nil, ""},
+		{"deadline exceeded", fmt.Errorf("read: %w", context.DeadlineExceeded), ErrorCategoryTimeout},
+		{"transaction not found", fmt.Errorf("read tx-1: %w", databases.ErrTransactionNotFound), ErrorCategoryNotFound},
+		{"provisioned throughput exceeded", apiError("ProvisionedThroughputExceededException"), ErrorCategoryThrottled},
+		{"throttling exception", apiError("ThrottlingException"), ErrorCategoryThrottled},
+		{"request limit exceeded", apiError("RequestLimitExceeded"), ErrorCategoryThrottled},
+		{"conditional check failed", apiError("ConditionalCheckFailedException"), ErrorCategoryConditionFailed},
+		{"request timeout code", apiError("RequestTimeout"), ErrorCategoryTimeout},
+		{"missing table is an error", apiError("ResourceNotFoundException"), ErrorCategoryOther},
+		{"not found message is an error", errors.New("index not found"), ErrorCategoryOther},
+		{"validation error", apiError("ValidationException"), ErrorCategoryOther},
+		{"rate exceeded message", errors.New("Rate exceeded"), ErrorCategoryThrottled},
+		{"throttled message", errors.New("request was throttled"), ErrorCategoryThrottled},
+		{"timed out message", errors.New("dial tcp: i/o timeout"), ErrorCategoryTimeout},
+		{"condition failed message", errors.New("write condition failed"), ErrorCategoryConditionFailed},
+		{"other", errors.New("connection refused"), ErrorCategoryOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.err); got != tt.want {
+				t.Errorf("classifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException"}, true},
+		{errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsThrottled(tt.err); got != tt.want {
+			t.Errorf("IsThrottled(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// Latency histograms track 1ns to 1h with three significant digits
+const (
+	histogramLowestNs   = 1
+	histogramHighestNs  = int64(time.Hour)
+	histogramSigFigures = 3
+)
+
+// summarizeLatencyHistograms records the durations of each operation type in an HDR
+// histogram and returns, per type, its percentiles and its V2 compressed base64 encoding.
+// Encoded histograms from several invocations can be decoded with hdrhistogram.Decode
+// and merged to compute percentiles over the combined run.
+func summarizeLatencyHistograms(ops []*OperationMetric, percentiles []float64) map[string]interface{} {
+	histograms := make(map[OperationType]*hdrhistogram.Histogram)
+	for _, op := range ops {
+		h, ok := histograms[op.Type]
+		if !ok {
+			h = hdrhistogram.New(histogramLowestNs, histogramHighestNs, histogramSigFigures)
+			histograms[op.Type] = h
+		}
+
+		// Clamp to the tracked range so outliers still count towards the distribution
+		ns := op.Duration.Nanoseconds()
+		if ns < histogramLowestNs {
+			ns = histogramLowestNs
+		}
+		if ns > histogramHighestNs {
+			ns = histogramHighestNs
+		}
+		_ = h.RecordValue(ns)
+	}
+
+	summary := make(map[string]interface{}, len(histograms))
+	for opType, h := range histograms {
+		entry := map[string]interface{}{
+			"count": h.TotalCount(),
+		}
+		for _, p := range percentiles {
+			entry[percentileKey(p)] = h.ValueAtPercentile(p)
+		}
+		if encoded, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase); err == nil {
+			entry["encoded"] = string(encoded)
+		}
+		summary[string(opType)] = entry
+	}
+
+	return summary
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+func TestLatencyHistogramPercentiles(t *testing.T) {
+	// Log-normal latencies around 5ms with a long tail, as real operations have
+	rng := rand.New(rand.NewSource(1))
+	var ops []*OperationMetric
+	for i := 0; i < 5000; i++ {
+		ns := math.Exp(rng.NormFloat64()*0.8) * float64(5*time.Millisecond)
+		ops = append(ops, &OperationMetric{Type: ReadOperation, Duration: time.Duration(ns)})
+	}
+	percentiles := []float64{50, 90, 99, 99.9}
+
+	sorted := make([]int64, len(ops))
+	for i, op := range ops {
+		sorted[i] = op.Duration.Nanoseconds()
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	entry := summarizeLatencyHistograms(ops, percentiles)[string(ReadOperation)].(map[string]interface{})
+	if entry["count"] != int64(len(ops)) {
+		t.Fatalf("count = %v, want %d", entry["count"], len(ops))
+	}
+
+	// Three significant digits keep every percentile within 0.1% of the exact value
+	for _, p := range percentiles {
+		exact := float64(percentile(sorted, p))
+		got := float64(entry[percentileKey(p)].(int64))
+		if math.Abs(got-exact)/exact > 0.001 {
+			t.Errorf("p%v = %v, want within 0.1%% of %v", p, got, exact)
+		}
+	}
+}
+
+func TestLatencyHistogramEncoding(t *testing.T) {
+	ops := opsWithDurations(WriteOperation, 1, 2, 3, 4, 5)
+	// Durations outside the tracked range are clamped rather than dropped
+	ops = append(ops, &OperationMetric{Type: WriteOperation, Duration: 2 * time.Hour})
+
+	entry := summarizeLatencyHistograms(ops, []float64{50})[string(WriteOperation)].(map[string]interface{})
+	encoded, ok := entry["encoded"].(string)
+	if !ok {
+		t.Fatalf("encoded = %v, want the base64 histogram", entry["encoded"])
+	}
+
+	decoded, err := hdrhistogram.Decode([]byte(encoded))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded.TotalCount() != 6 {
+		t.Errorf("decoded count = %d, want 6", decoded.TotalCount())
+	}
+	if got := decoded.Max(); got < histogramHighestNs*999/1000 {
+		t.Errorf("decoded max = %d, want the clamped %d", got, histogramHighestNs)
+	}
+}
@@ -0,0 +1,71 @@
+package workload
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// ZipfGenerator produces key indices in [0, n) following a Zipf distribution,
+// where index k is drawn with probability proportional to 1/(k+1)^s.
+// Unlike math/rand.Zipf, any exponent s > 0 is accepted, including the
+// commonly used s = 0.99.
+type ZipfGenerator struct {
+	cdf []float64
+	rng *rand.Rand
+}
+
+// NewZipfGenerator creates a generator over n keys with exponent s
+func NewZipfGenerator(n uint64, s float64) (*ZipfGenerator, error) {
+	return NewZipfGeneratorWithSeed(n, s, time.Now().UnixNano())
+}
+
+// NewZipfGeneratorWithSeed creates a generator with a fixed seed for reproducible sequences
+func NewZipfGeneratorWithSeed(n uint64, s float64, seed int64) (*ZipfGenerator, error) {
+	if n == 0 {
+		return nil, fmt.Errorf("key space must contain at least one key")
+	}
+	if s <= 0 || math.IsNaN(s) || math.IsInf(s, 0) {
+		return nil, fmt.Errorf("zipf exponent must be positive, got %v", s)
+	}
+
+	// Precompute the normalized cumulative distribution for inverse transform sampling
+	cdf := make([]float64, n)
+	var total float64
+	for k := uint64(0); k < n; k++ {
+		total += 1 / math.Pow(float64(k+1), s)
+		cdf[k] = total
+	}
+	for k := range cdf {
+		cdf[k] /= total
+	}
+
+	return &ZipfGenerator{
+		cdf: cdf,
+		rng: rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Next returns the next key index; index 0 is the hottest key.
+// It is not safe for concurrent use.
+func (g *ZipfGenerator) Next() uint64 {
+	u := g.rng.Float64()
+	idx := sort.SearchFloat64s(g.cdf, u)
+	if idx >= len(g.cdf) {
+		idx = len(g.cdf) - 1
+	}
+	return uint64(idx)
+}
+
+// Probability returns the probability that Next returns index k
+func (g *ZipfGenerator) Probability(k uint64) float64 {
+	if k >= uint64(len(g.cdf)) {
+		return 0
+	}
+	if k == 0 {
+		return g.cdf[0]
+	}
+	return g.cdf[k] - g.cdf[k-1]
+}
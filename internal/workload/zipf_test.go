@@ -0,0 +1,119 @@
+package workload
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewZipfGeneratorRejectsInvalidParameters(t *testing.T) {
+	tests := []struct {
+		name string
+		n    uint64
+		s    float64
+	}{
+		{"empty key space", 0, 0.99},
+		{"zero exponent", 10, 0},
+		{"negative exponent", 10, -1},
+		{"NaN exponent", 10, math.NaN()},
+		{"infinite exponent", 10, math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewZipfGeneratorWithSeed(tt.n, tt.s, 1); err == nil {
+				t.Errorf("NewZipfGeneratorWithSeed(%d, %v) error = nil, want an error", tt.n, tt.s)
+			}
+		})
+	}
+}
+
+func TestZipfProbability(t *testing.T) {
+	harmonic3 := 1 + 1.0/2 + 1.0/3
+
+	tests := []struct {
+		name string
+		n    uint64
+		s    float64
+		k    uint64
+		want float64
+	}{
+		{"single key", 1, 0.99, 0, 1},
+		{"hottest key, s=1", 3, 1, 0, 1 / harmonic3},
+		{"second key, s=1", 3, 1, 1, 0.5 / harmonic3},
+		{"coldest key, s=1", 3, 1, 2, (1.0 / 3) / harmonic3},
+		{"s=2", 2, 2, 1, 0.25 / 1.25},
+		{"outside the key space", 3, 1, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewZipfGeneratorWithSeed(tt.n, tt.s, 1)
+			if err != nil {
+				t.Fatalf("NewZipfGeneratorWithSeed() error = %v", err)
+			}
+			if got := g.Probability(tt.k); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("Probability(%d) = %v, want %v", tt.k, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZipfProbabilitiesSumToOneAndDecrease(t *testing.T) {
+	for _, s := range []float64{0.5, 0.99, 1, 1.5} {
+		g, err := NewZipfGeneratorWithSeed(100, s, 1)
+		if err != nil {
+			t.Fatalf("NewZipfGeneratorWithSeed(100, %v) error = %v", s, err)
+		}
+
+		var total float64
+		for k := uint64(0); k < 100; k++ {
+			p := g.Probability(k)
+			if k > 0 && p > g.Probability(k-1) {
+				t.Errorf("s=%v: Probability(%d) = %v exceeds Probability(%d) = %v", s, k, p, k-1, g.Probability(k-1))
+			}
+			total += p
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("s=%v: probabilities sum to %v, want 1", s, total)
+		}
+	}
+}
+
+func TestZipfNextFollowsDistribution(t *testing.T) {
+	const (
+		n       = 10
+		samples = 200000
+	)
+
+	g, err := NewZipfGeneratorWithSeed(n, 0.99, 42)
+	if err != nil {
+		t.Fatalf("NewZipfGeneratorWithSeed() error = %v", err)
+	}
+
+	counts := make([]int, n)
+	for i := 0; i < samples; i++ {
+		k := g.Next()
+		if k >= n {
+			t.Fatalf("Next() = %d, want an index below %d", k, n)
+		}
+		counts[k]++
+	}
+
+	for k := uint64(0); k < n; k++ {
+		got := float64(counts[k]) / samples
+		if want := g.Probability(k); math.Abs(got-want) > 0.01 {
+			t.Errorf("key %d drawn with frequency %.4f, want %.4f", k, got, want)
+		}
+	}
+}
+
+func TestZipfSeedIsReproducible(t *testing.T) {
+	a, _ := NewZipfGeneratorWithSeed(1000, 0.99, 7)
+	b, _ := NewZipfGeneratorWithSeed(1000, 0.99, 7)
+
+	for i := 0; i < 100; i++ {
+		if x, y := a.Next(), b.Next(); x != y {
+			t.Fatalf("draw %d: %d != %d with the same seed", i, x, y)
+		}
+	}
+}
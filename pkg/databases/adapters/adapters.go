@@ -0,0 +1,60 @@
+// Package adapters creates database adapters by type name so that the
+// benchmark entry points share the same configuration rules.
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+
+	// Adapter packages register their factories in init
+	_ "github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/dynamodb"
+	_ "github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/immudb"
+	_ "github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/redis"
+	_ "github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/timestream"
+)
+
+// Create creates and initializes the database adapter for dbType. Configuration
+// defaults come from the environment and are overridden by "db."-prefixed params.
+func Create(ctx context.Context, dbType string, params map[string]interface{}) (databases.Database, error) {
+	// Default configuration
+	config := map[string]interface{}{
+		"region":    os.Getenv("AWS_REGION"),
+		"tableName": os.Getenv("DB_TABLE_NAME"),
+	}
+
+	// Override with request parameters if provided
+	for k, v := range params {
+		if strings.HasPrefix(k, "db.") {
+			configKey := strings.TrimPrefix(k, "db.")
+			config[configKey] = v
+		}
+	}
+
+	// Special handling for local testing endpoints
+	if endpoint, ok := os.LookupEnv("DB_ENDPOINT"); ok && endpoint != "" {
+		config["endpoint"] = endpoint
+	}
+
+	// Create the adapter with the factory registered under dbType
+	factory, err := databases.GetFactory(dbType)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := factory.CreateDatabase(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating database adapter: %w", err)
+	}
+
+	// Initialize the database
+	err = db.Initialize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing database: %w", err)
+	}
+
+	return db, nil
+}
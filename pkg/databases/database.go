@@ -2,9 +2,29 @@ package databases
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNotSupported is returned by adapters for operations their database does not support
+var ErrNotSupported = errors.New("operation not supported by this database")
+
+// ErrTransactionNotFound is returned by adapters when a requested transaction does not exist
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// ErrConditionFailed is returned by adapters when the condition of a conditional write does not hold
+var ErrConditionFailed = errors.New("condition failed")
+
+// IsUnsupportedOperation reports whether err indicates the database does not support the operation
+func IsUnsupportedOperation(err error) bool {
+	return errors.Is(err, ErrNotSupported)
+}
+
+// IsConditionFailed reports whether err indicates a write was rejected by its condition
+func IsConditionFailed(err error) bool {
+	return errors.Is(err, ErrConditionFailed)
+}
+
 // TransactionType represents the type of banking transaction
 type TransactionType string
 
@@ -19,12 +39,13 @@ const (
 
 // Transaction represents a banking transaction record
 type Transaction struct {
-	AccountID       string          `json:"accountId"`       // 12 characters
-	UUID            string          `json:"uuid"`            // 36 characters
-	Timestamp       time.Time       `json:"timestamp"`       // ISO 8601 format
-	Amount          float64         `json:"amount"`          // Decimal with 2 precision points
-	TransactionType TransactionType `json:"transactionType"` // DEPOSIT, WITHDRAWAL, TRANSFER
-	Metadata        interface{}     `json:"metadata"`        // JSON object, configurable size
+	AccountID       string          `json:"accountId"`                                            // 12 characters
+	UUID            string          `json:"uuid"`                                                 // 36 characters
+	Timestamp       time.Time       `json:"timestamp"`                                            // ISO 8601 format
+	Amount          float64         `json:"amount"`                                               // Decimal with 2 precision points
+	TransactionType TransactionType `json:"transactionType"`                                      // DEPOSIT, WITHDRAWAL, TRANSFER
+	Metadata        interface{}     `json:"metadata"`                                             // JSON object, configurable size
+	ExpiresAt       int64           `json:"expiresAt,omitempty" dynamodbav:"expiresAt,omitempty"` // Unix seconds, set when a TTL is configured
 }
 
 // ReadOptions represents options for read operations
@@ -32,27 +53,61 @@ type ReadOptions struct {
 	ConsistentRead bool
 	IndexName      string
 	Limit          int64
+	Verified       bool // Request cryptographic verification where supported (ImmuDB)
 	// Add more options as needed
 }
 
 // WriteOptions represents options for write operations
 type WriteOptions struct {
-	Condition     string
+	Condition     string // DynamoDB condition expression; ImmuDB supports attribute_exists(...) and attribute_not_exists(...)
 	ReturnOldItem bool
+	Verified      bool // Request cryptographic verification where supported (ImmuDB)
 	// Add more options as needed
 }
 
+// DeleteOptions represents options for delete operations
+type DeleteOptions struct {
+	Condition     string // DynamoDB condition expression; ImmuDB supports attribute_exists(...) and attribute_not_exists(...)
+	ReturnOldItem bool
+	OldItem       *Transaction // Set to the deleted item when ReturnOldItem is true and the database returns it (DynamoDB)
+}
+
 // QueryOptions represents options for query operations
 type QueryOptions struct {
 	ScanIndexForward bool
 	Limit            int64
 	ConsistentRead   bool
+	StartToken       string // Continuation token returned by a previous paged query
+	IndexName        string // Index for time-range queries; empty uses the adapter default, BaseTableIndex skips secondary indexes
 	// Add more options as needed
 }
 
+// BaseTableIndex is the QueryOptions.IndexName value that queries the base table
+// instead of a secondary index
+const BaseTableIndex = "base-table"
+
+// PagedTransactions represents a single page of query results
+type PagedTransactions struct {
+	Transactions []*Transaction
+	NextToken    string // Empty when there are no more pages
+}
+
+// ScanOptions represents options for full-table scan operations
+type ScanOptions struct {
+	Segment        int32 // Segment scanned by this worker in a parallel scan
+	TotalSegments  int32 // 0 or 1 performs a single, non-parallel scan
+	Limit          int64 // Maximum items evaluated per page
+	ConsistentRead bool
+	StartToken     string // Continuation token returned by a previous paged scan
+}
+
 // BatchOptions represents options for batch operations
 type BatchOptions struct {
-	MaxBatchSize int
+	MaxBatchSize   int
+	MaxRetries     int           // Retries for unprocessed items; 0 uses the default of 3, negative disables
+	InitialBackoff time.Duration // Base delay for exponential backoff between retries
+	Parallel       bool          // Send the segments of a batch concurrently instead of one at a time
+	MaxConcurrency int           // Segments in flight when Parallel is set; 0 uses the adapter default
 	// Add more options as needed
 }
 
@@ -65,11 +120,18 @@ type Database interface {
 	// Single-item operations
 	ReadTransaction(ctx context.Context, accountID, uuid string, options *ReadOptions) (*Transaction, error)
 	WriteTransaction(ctx context.Context, transaction *Transaction, options *WriteOptions) error
-	DeleteTransaction(ctx context.Context, accountID, uuid string) error
+	UpdateTransaction(ctx context.Context, transaction *Transaction, options *WriteOptions) error
+	DeleteTransaction(ctx context.Context, accountID, uuid string, options *DeleteOptions) error
 
 	// Query operations
 	QueryTransactionsByAccount(ctx context.Context, accountID string, options *QueryOptions) ([]*Transaction, error)
 	QueryTransactionsByTimeRange(ctx context.Context, accountID string, startTime, endTime time.Time, options *QueryOptions) ([]*Transaction, error)
+	QueryTransactionsByAccountPaged(ctx context.Context, accountID string, options *QueryOptions) (*PagedTransactions, error)
+	CountTransactionsByAccount(ctx context.Context, accountID string) (int64, error)
+
+	// Scan operations; adapters without scan support return ErrNotSupported
+	ScanTransactions(ctx context.Context, options *ScanOptions) ([]*Transaction, error)
+	ScanTransactionsPaged(ctx context.Context, options *ScanOptions) (*PagedTransactions, error)
 
 	// Batch operations
 	BatchReadTransactions(ctx context.Context, keys []struct{ AccountID, UUID string }, options *BatchOptions) ([]*Transaction, error)
@@ -77,12 +139,31 @@ type Database interface {
 
 	// Transaction operations
 	ExecuteTransactWrite(ctx context.Context, transactions []*Transaction) error
+	ExecuteTransactRead(ctx context.Context, keys []struct{ AccountID, UUID string }) ([]*Transaction, error)
 
 	// Metrics and diagnostics
 	GetMetrics() map[string]interface{}
 	ResetMetrics()
 }
 
+// Aggregator is implemented by databases that can aggregate transaction amounts
+// server-side. aggFunc is one of SUM, AVG, MIN, MAX or COUNT.
+type Aggregator interface {
+	AggregateTransactions(ctx context.Context, accountID string, startTime, endTime time.Time, aggFunc string) (float64, error)
+}
+
+// Reinitializable is implemented by databases that can be initialized again after
+// Close, which the cold-start operation uses to measure connection setup repeatedly
+type Reinitializable interface {
+	CanReinitialize() bool
+}
+
+// AmountRangeQuerier is implemented by databases that can query an account's
+// transactions by an inclusive range of amounts
+type AmountRangeQuerier interface {
+	QueryTransactionsByAmountRange(ctx context.Context, accountID string, minAmount, maxAmount float64, options *QueryOptions) ([]*Transaction, error)
+}
+
 // DatabaseFactory creates and configures a specific database implementation
 type DatabaseFactory interface {
 	// CreateDatabase creates a new database instance with the given configuration
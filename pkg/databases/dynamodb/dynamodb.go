@@ -2,23 +2,70 @@ package dynamodb
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
 )
 
+// timestampIndexName is the GSI keyed on accountId and timestamp used for time-range queries
+const timestampIndexName = "TimestampIndex"
+
+// amountIndexName is the optional LSI keyed on accountId and amount used for amount-range queries
+const amountIndexName = "AmountIndex"
+
+// Billing modes accepted in the billingMode config key
+const (
+	BillingModeProvisioned   = "provisioned"
+	BillingModePayPerRequest = "payPerRequest"
+)
+
+// ttlAttributeName is the attribute holding the expiry time when TTL is enabled
+const ttlAttributeName = "expiresAt"
+
+// dynamoDBAPI is the subset of the DynamoDB client used by the adapter, so tests can
+// substitute a mock for *dynamodb.Client
+type dynamoDBAPI interface {
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error)
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	TransactGetItems(ctx context.Context, params *dynamodb.TransactGetItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactGetItemsOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
 // DynamoDBDatabase is an implementation of the Database interface for AWS DynamoDB
 type DynamoDBDatabase struct {
-	client      *dynamodb.Client
+	client      dynamoDBAPI
 	tableName   string
+	ttl         time.Duration // Zero disables TTL
+	amountIndex bool          // Whether the table has the amount LSI
 	metrics     map[string]interface{}
+	metricsMu   sync.Mutex
 	initialized bool
 }
 
@@ -30,11 +77,20 @@ type DynamoDBConfig struct {
 	ProvisionedRCUs int64
 	ProvisionedWCUs int64
 	CreateTable     bool
+	BillingMode     string // provisioned (default) or payPerRequest
+	TTLSeconds      int64  // When positive, enables TTL and sets expiresAt on written items
+	MaxRetries      int    // Client-side retries per call; 0 disables retries, negative keeps the SDK default
+	RetryMode       string // standard or adaptive; empty keeps the SDK default
+	AmountIndex     bool   // Create the table with an LSI on amount and use it for amount-range queries
 }
 
 // DynamoDBFactory creates DynamoDB database instances
 type DynamoDBFactory struct{}
 
+func init() {
+	databases.RegisterFactory("dynamodb", NewDynamoDBFactory())
+}
+
 // NewDynamoDBFactory creates a new DynamoDB factory
 func NewDynamoDBFactory() *DynamoDBFactory {
 	return &DynamoDBFactory{}
@@ -49,6 +105,8 @@ func (f *DynamoDBFactory) CreateDatabase(config map[string]interface{}) (databas
 		ProvisionedRCUs: 5,
 		ProvisionedWCUs: 5,
 		CreateTable:     false,
+		BillingMode:     BillingModeProvisioned,
+		MaxRetries:      -1,
 	}
 
 	if region, ok := config["region"].(string); ok {
@@ -69,24 +127,155 @@ func (f *DynamoDBFactory) CreateDatabase(config map[string]interface{}) (databas
 	if createTable, ok := config["createTable"].(bool); ok {
 		dbConfig.CreateTable = createTable
 	}
+	if billingMode, ok := config["billingMode"].(string); ok && billingMode != "" {
+		if billingMode != BillingModeProvisioned && billingMode != BillingModePayPerRequest {
+			return nil, fmt.Errorf("unsupported billing mode %q (must be %s or %s)", billingMode, BillingModeProvisioned, BillingModePayPerRequest)
+		}
+		dbConfig.BillingMode = billingMode
+	}
+	if ttl, ok := intConfig(config, "ttlSeconds"); ok {
+		dbConfig.TTLSeconds = ttl
+	}
+	if maxRetries, ok := intConfig(config, "maxRetries"); ok {
+		dbConfig.MaxRetries = int(maxRetries)
+	}
+	if amountIndex, ok := config["amountIndex"].(bool); ok {
+		dbConfig.AmountIndex = amountIndex
+	}
+	if retryMode, ok := config["retryMode"].(string); ok && retryMode != "" {
+		if _, err := aws.ParseRetryMode(retryMode); err != nil {
+			return nil, fmt.Errorf("unsupported retry mode %q: %w", retryMode, err)
+		}
+		dbConfig.RetryMode = retryMode
+	}
 
 	return NewDynamoDBDatabase(dbConfig)
 }
 
+// intConfig reads an integer config value that may have been decoded from JSON as a float
+func intConfig(config map[string]interface{}, key string) (int64, bool) {
+	switch v := config[key].(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// addRequestIDRecorder adds a middleware that copies each response's request ID into
+// the request trace of the call's context, including for failed calls
+func addRequestIDRecorder(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("RecordRequestID",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleDeserialize(ctx, in)
+			if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+				databases.SetRequestID(ctx, requestID)
+			}
+			return out, metadata, err
+		}), middleware.Before)
+}
+
+// operationMetrics maps the data-plane API operations to their operation counter
+var operationMetrics = map[string]string{
+	"GetItem":            "readOperations",
+	"PutItem":            "writeOperations",
+	"UpdateItem":         "writeOperations",
+	"DeleteItem":         "writeOperations",
+	"Query":              "queryOperations",
+	"Scan":               "queryOperations",
+	"BatchGetItem":       "batchReadOperations",
+	"BatchWriteItem":     "batchWriteOperations",
+	"TransactGetItems":   "transactionOperations",
+	"TransactWriteItems": "transactionOperations",
+}
+
+// throttlingErrorCodes are the error codes DynamoDB returns when it throttles a request
+var throttlingErrorCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"ThrottlingException":                    true,
+	"RequestLimitExceeded":                   true,
+}
+
+// isThrottlingError reports whether err is a DynamoDB throttling error
+func isThrottlingError(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && throttlingErrorCodes[apiErr.ErrorCode()]
+}
+
+// addOperationCounters adds middlewares that count the data-plane calls made by the
+// client. The initialize step runs once per call, after the SDK's retries, and counts
+// the call in totalOperations and its operation counter, failed calls in
+// failedOperations and calls that failed throttled in throttledOperations. The
+// deserialize step runs once per attempt and counts every throttled attempt, including
+// those the SDK retried, in throttlingExceptions.
+func (db *DynamoDBDatabase) addOperationCounters(stack *middleware.Stack) error {
+	err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("CountOperations",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if counter, ok := operationMetrics[awsmiddleware.GetOperationName(ctx)]; ok {
+				db.incrementMetric("totalOperations")
+				db.incrementMetric(counter)
+				if err != nil {
+					db.incrementMetric("failedOperations")
+					if isThrottlingError(err) {
+						db.incrementMetric("throttledOperations")
+					}
+				}
+			}
+			return out, metadata, err
+		}), middleware.After)
+	if err != nil {
+		return err
+	}
+
+	return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("CountThrottlingExceptions",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleDeserialize(ctx, in)
+			if _, ok := operationMetrics[awsmiddleware.GetOperationName(ctx)]; ok && isThrottlingError(err) {
+				db.incrementMetric("throttlingExceptions")
+			}
+			return out, metadata, err
+		}), middleware.Before)
+}
+
+// loadAWSConfig loads the SDK configuration for the region, applying the client-side
+// retry settings so benchmarks can be run with and without SDK retries
+func loadAWSConfig(dbConfig DynamoDBConfig) (aws.Config, error) {
+	loadOptions := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(dbConfig.Region),
+	}
+	if dbConfig.MaxRetries >= 0 {
+		// The SDK counts the initial call as an attempt
+		loadOptions = append(loadOptions, awsconfig.WithRetryMaxAttempts(dbConfig.MaxRetries+1))
+	}
+	if dbConfig.RetryMode != "" {
+		mode, err := aws.ParseRetryMode(dbConfig.RetryMode)
+		if err != nil {
+			return aws.Config{}, err
+		}
+		loadOptions = append(loadOptions, awsconfig.WithRetryMode(mode))
+	}
+
+	return awsconfig.LoadDefaultConfig(context.Background(), loadOptions...)
+}
+
 // NewDynamoDBDatabase creates a new DynamoDB database instance
 func NewDynamoDBDatabase(dbConfig DynamoDBConfig) (*DynamoDBDatabase, error) {
 	db := &DynamoDBDatabase{
 		tableName:   dbConfig.TableName,
-		metrics:     make(map[string]interface{}),
+		ttl:         time.Duration(dbConfig.TTLSeconds) * time.Second,
+		amountIndex: dbConfig.AmountIndex,
 		initialized: false,
 	}
+	// Metrics are reset here rather than in Initialize so they cover every
+	// initialization of a cold-start run
+	db.ResetMetrics()
 
 	// Create AWS configuration
-	var err error
-
-	// Fix AWS SDK configuration loading with renamed package and variable
-	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(),
-		awsconfig.WithRegion(dbConfig.Region))
+	awsCfg, err := loadAWSConfig(dbConfig)
 
 	if dbConfig.Endpoint != "" {
 		// Use a custom endpoint (e.g., for local DynamoDB)
@@ -104,11 +293,13 @@ func NewDynamoDBDatabase(dbConfig DynamoDBConfig) (*DynamoDBDatabase, error) {
 	}
 
 	// Create DynamoDB client
-	db.client = dynamodb.NewFromConfig(awsCfg)
+	db.client = dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, addRequestIDRecorder, db.addOperationCounters)
+	})
 
 	// Create table if requested
 	if dbConfig.CreateTable {
-		err = db.createTransactionTable(dbConfig.ProvisionedRCUs, dbConfig.ProvisionedWCUs)
+		err = db.createTransactionTable(dbConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create table: %w", err)
 		}
@@ -136,8 +327,13 @@ func (db *DynamoDBDatabase) Initialize(ctx context.Context) error {
 		return fmt.Errorf("error checking table: %w", err)
 	}
 
+	if db.ttl > 0 {
+		if err := db.enableTTL(ctx); err != nil {
+			return err
+		}
+	}
+
 	db.initialized = true
-	db.ResetMetrics()
 	return nil
 }
 
@@ -148,6 +344,12 @@ func (db *DynamoDBDatabase) Close() error {
 	return nil
 }
 
+// CanReinitialize implements the databases.Reinitializable interface; Close keeps the
+// client, so Initialize only describes the table again
+func (db *DynamoDBDatabase) CanReinitialize() bool {
+	return true
+}
+
 // ReadTransaction implements the Database interface
 func (db *DynamoDBDatabase) ReadTransaction(ctx context.Context, accountID, uuid string, options *databases.ReadOptions) (*databases.Transaction, error) {
 	if !db.initialized {
@@ -168,7 +370,8 @@ func (db *DynamoDBDatabase) ReadTransaction(ctx context.Context, accountID, uuid
 			"accountId": &types.AttributeValueMemberS{Value: accountID},
 			"uuid":      &types.AttributeValueMemberS{Value: uuid},
 		},
-		ConsistentRead: aws.Bool(options.ConsistentRead),
+		ConsistentRead:         aws.Bool(options.ConsistentRead),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 	}
 
 	// Execute GetItem operation
@@ -176,10 +379,11 @@ func (db *DynamoDBDatabase) ReadTransaction(ctx context.Context, accountID, uuid
 	if err != nil {
 		return nil, fmt.Errorf("GetItem operation failed: %w", err)
 	}
+	db.recordCapacity("readCapacityUnits", result.ConsumedCapacity)
 
 	// Check if item exists
 	if result.Item == nil || len(result.Item) == 0 {
-		return nil, fmt.Errorf("transaction not found")
+		return nil, fmt.Errorf("%w: %s", databases.ErrTransactionNotFound, uuid)
 	}
 
 	// Unmarshal DynamoDB item to Transaction struct
@@ -203,15 +407,16 @@ func (db *DynamoDBDatabase) WriteTransaction(ctx context.Context, transaction *d
 	}
 
 	// Marshal transaction to DynamoDB attribute values
-	item, err := attributevalue.MarshalMap(transaction)
+	item, err := db.marshalTransaction(transaction)
 	if err != nil {
 		return fmt.Errorf("failed to marshal transaction: %w", err)
 	}
 
 	// Create PutItem input
 	input := &dynamodb.PutItemInput{
-		TableName: aws.String(db.tableName),
-		Item:      item,
+		TableName:              aws.String(db.tableName),
+		Item:                   item,
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 	}
 
 	// Add condition expression if provided
@@ -220,16 +425,67 @@ func (db *DynamoDBDatabase) WriteTransaction(ctx context.Context, transaction *d
 	}
 
 	// Execute PutItem operation
-	_, err = db.client.PutItem(ctx, input)
+	result, err := db.client.PutItem(ctx, input)
 	if err != nil {
-		return fmt.Errorf("PutItem operation failed: %w", err)
+		return fmt.Errorf("PutItem operation failed: %w", db.conditionError(err))
 	}
+	db.recordCapacity("writeCapacityUnits", result.ConsumedCapacity)
+
+	return nil
+}
+
+// UpdateTransaction implements the Database interface
+func (db *DynamoDBDatabase) UpdateTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) error {
+	if !db.initialized {
+		return errors.New("database not initialized")
+	}
+
+	if transaction == nil {
+		return errors.New("transaction cannot be nil")
+	}
+
+	// Marshal metadata to a DynamoDB attribute value
+	metadata, err := attributevalue.Marshal(transaction.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	// Create UpdateItem input
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(db.tableName),
+		Key: map[string]types.AttributeValue{
+			"accountId": &types.AttributeValueMemberS{Value: transaction.AccountID},
+			"uuid":      &types.AttributeValueMemberS{Value: transaction.UUID},
+		},
+		UpdateExpression: aws.String("SET #amount = :amount, #metadata = :metadata"),
+		ExpressionAttributeNames: map[string]string{
+			"#amount":   "amount",
+			"#metadata": "metadata",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":amount":   &types.AttributeValueMemberN{Value: strconv.FormatFloat(transaction.Amount, 'f', -1, 64)},
+			":metadata": metadata,
+		},
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+
+	// Add condition expression if provided
+	if options != nil && options.Condition != "" {
+		input.ConditionExpression = aws.String(options.Condition)
+	}
+
+	// Execute UpdateItem operation
+	result, err := db.client.UpdateItem(ctx, input)
+	if err != nil {
+		return fmt.Errorf("UpdateItem operation failed: %w", db.conditionError(err))
+	}
+	db.recordCapacity("writeCapacityUnits", result.ConsumedCapacity)
 
 	return nil
 }
 
 // DeleteTransaction implements the Database interface
-func (db *DynamoDBDatabase) DeleteTransaction(ctx context.Context, accountID, uuid string) error {
+func (db *DynamoDBDatabase) DeleteTransaction(ctx context.Context, accountID, uuid string, options *databases.DeleteOptions) error {
 	if !db.initialized {
 		return errors.New("database not initialized")
 	}
@@ -243,10 +499,26 @@ func (db *DynamoDBDatabase) DeleteTransaction(ctx context.Context, accountID, uu
 		},
 	}
 
+	if options != nil && options.Condition != "" {
+		input.ConditionExpression = aws.String(options.Condition)
+	}
+	if options != nil && options.ReturnOldItem {
+		input.ReturnValues = types.ReturnValueAllOld
+	}
+
 	// Execute DeleteItem operation
-	_, err := db.client.DeleteItem(ctx, input)
+	result, err := db.client.DeleteItem(ctx, input)
 	if err != nil {
-		return fmt.Errorf("DeleteItem operation failed: %w", err)
+		return fmt.Errorf("DeleteItem operation failed: %w", db.conditionError(err))
+	}
+
+	// Attributes is empty when the item did not exist
+	if options != nil && options.ReturnOldItem && len(result.Attributes) > 0 {
+		var transaction databases.Transaction
+		if err := attributevalue.UnmarshalMap(result.Attributes, &transaction); err != nil {
+			return fmt.Errorf("failed to unmarshal deleted transaction: %w", err)
+		}
+		options.OldItem = &transaction
 	}
 
 	return nil
@@ -274,8 +546,9 @@ func (db *DynamoDBDatabase) QueryTransactionsByAccount(ctx context.Context, acco
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":accountId": &types.AttributeValueMemberS{Value: accountID},
 		},
-		ScanIndexForward: aws.Bool(options.ScanIndexForward),
-		ConsistentRead:   aws.Bool(options.ConsistentRead),
+		ScanIndexForward:       aws.Bool(options.ScanIndexForward),
+		ConsistentRead:         aws.Bool(options.ConsistentRead),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 	}
 
 	if options.Limit > 0 {
@@ -287,6 +560,7 @@ func (db *DynamoDBDatabase) QueryTransactionsByAccount(ctx context.Context, acco
 	if err != nil {
 		return nil, fmt.Errorf("Query operation failed: %w", err)
 	}
+	db.recordCapacity("readCapacityUnits", result.ConsumedCapacity)
 
 	// Unmarshal items to Transaction structs
 	transactions := make([]*databases.Transaction, 0, len(result.Items))
@@ -302,6 +576,113 @@ func (db *DynamoDBDatabase) QueryTransactionsByAccount(ctx context.Context, acco
 	return transactions, nil
 }
 
+// CountTransactionsByAccount implements the Database interface. The query selects
+// COUNT so no items are returned, and follows LastEvaluatedKey across 1 MB pages.
+func (db *DynamoDBDatabase) CountTransactionsByAccount(ctx context.Context, accountID string) (int64, error) {
+	if !db.initialized {
+		return 0, errors.New("database not initialized")
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName),
+		KeyConditionExpression: aws.String("accountId = :accountId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":accountId": &types.AttributeValueMemberS{Value: accountID},
+		},
+		Select:                 types.SelectCount,
+		ConsistentRead:         aws.Bool(true),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+
+	var count int64
+	for {
+		result, err := db.client.Query(ctx, input)
+		if err != nil {
+			return 0, fmt.Errorf("Query operation failed: %w", err)
+		}
+		db.recordCapacity("readCapacityUnits", result.ConsumedCapacity)
+
+		count += int64(result.Count)
+		if len(result.LastEvaluatedKey) == 0 {
+			return count, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// QueryTransactionsByAccountPaged implements the Database interface
+func (db *DynamoDBDatabase) QueryTransactionsByAccountPaged(ctx context.Context, accountID string, options *databases.QueryOptions) (*databases.PagedTransactions, error) {
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	// Set default options if not provided
+	if options == nil {
+		options = &databases.QueryOptions{
+			ScanIndexForward: true,
+			ConsistentRead:   true,
+			Limit:            100,
+		}
+	}
+
+	// Create Query input
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(db.tableName),
+		KeyConditionExpression: aws.String("accountId = :accountId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":accountId": &types.AttributeValueMemberS{Value: accountID},
+		},
+		ScanIndexForward:       aws.Bool(options.ScanIndexForward),
+		ConsistentRead:         aws.Bool(options.ConsistentRead),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+
+	if options.Limit > 0 {
+		input.Limit = aws.Int32(int32(options.Limit))
+	}
+
+	// Resume from the previous page if a token was provided
+	if options.StartToken != "" {
+		startKey, err := decodePageToken(options.StartToken)
+		if err != nil {
+			return nil, err
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	// Execute Query operation
+	result, err := db.client.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("Query operation failed: %w", err)
+	}
+	db.recordCapacity("readCapacityUnits", result.ConsumedCapacity)
+
+	// Unmarshal items to Transaction structs
+	transactions := make([]*databases.Transaction, 0, len(result.Items))
+	for _, item := range result.Items {
+		var transaction databases.Transaction
+		err = attributevalue.UnmarshalMap(item, &transaction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+		}
+		transactions = append(transactions, &transaction)
+	}
+
+	page := &databases.PagedTransactions{
+		Transactions: transactions,
+	}
+
+	// Encode the last evaluated key so the caller can fetch the next page
+	if len(result.LastEvaluatedKey) > 0 {
+		page.NextToken, err = encodePageToken(result.LastEvaluatedKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
 // QueryTransactionsByTimeRange implements the Database interface
 func (db *DynamoDBDatabase) QueryTransactionsByTimeRange(ctx context.Context, accountID string, startTime, endTime time.Time, options *databases.QueryOptions) ([]*databases.Transaction, error) {
 	if !db.initialized {
@@ -321,19 +702,94 @@ func (db *DynamoDBDatabase) QueryTransactionsByTimeRange(ctx context.Context, ac
 	startTimeStr := startTime.Format(time.RFC3339)
 	endTimeStr := endTime.Format(time.RFC3339)
 
-	// Create Query input
+	// "timestamp" is a DynamoDB reserved word and must be referenced through an attribute name
 	input := &dynamodb.QueryInput{
-		TableName:              aws.String(db.tableName),
-		KeyConditionExpression: aws.String("accountId = :accountId AND timestamp BETWEEN :startTime AND :endTime"),
+		TableName: aws.String(db.tableName),
+		ExpressionAttributeNames: map[string]string{
+			"#ts": "timestamp",
+		},
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":accountId": &types.AttributeValueMemberS{Value: accountID},
 			":startTime": &types.AttributeValueMemberS{Value: startTimeStr},
 			":endTime":   &types.AttributeValueMemberS{Value: endTimeStr},
 		},
-		ScanIndexForward: aws.Bool(options.ScanIndexForward),
-		ConsistentRead:   aws.Bool(options.ConsistentRead),
+		ScanIndexForward:       aws.Bool(options.ScanIndexForward),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+
+	if options.IndexName == databases.BaseTableIndex {
+		// The base table's range key is uuid, so the time window is applied as a filter
+		// and consistent reads are honored
+		input.KeyConditionExpression = aws.String("accountId = :accountId")
+		input.FilterExpression = aws.String("#ts BETWEEN :startTime AND :endTime")
+		input.ConsistentRead = aws.Bool(options.ConsistentRead)
+		return db.queryFiltered(ctx, input, options.Limit)
+	}
+
+	// Query the timestamp GSI by default. Global secondary indexes don't support
+	// strongly consistent reads.
+	indexName := options.IndexName
+	if indexName == "" {
+		indexName = timestampIndexName
+	}
+	input.IndexName = aws.String(indexName)
+	input.KeyConditionExpression = aws.String("accountId = :accountId AND #ts BETWEEN :startTime AND :endTime")
+	input.ConsistentRead = aws.Bool(false)
+
+	if options.Limit > 0 {
+		input.Limit = aws.Int32(int32(options.Limit))
+	}
+
+	// Execute Query operation
+	result, err := db.client.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("Query operation failed: %w", err)
+	}
+	db.recordCapacity("readCapacityUnits", result.ConsumedCapacity)
+
+	return unmarshalTransactions(result.Items)
+}
+
+// QueryTransactionsByAmountRange implements the databases.AmountRangeQuerier interface.
+// With the amountIndex option it queries the amount LSI, which supports consistent reads;
+// otherwise it queries the account on the base table and filters by amount.
+func (db *DynamoDBDatabase) QueryTransactionsByAmountRange(ctx context.Context, accountID string, minAmount, maxAmount float64, options *databases.QueryOptions) ([]*databases.Transaction, error) {
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	// Set default options if not provided
+	if options == nil {
+		options = &databases.QueryOptions{
+			ScanIndexForward: true,
+			ConsistentRead:   true,
+			Limit:            100,
+		}
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName: aws.String(db.tableName),
+		ExpressionAttributeNames: map[string]string{
+			"#amount": "amount",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":accountId": &types.AttributeValueMemberS{Value: accountID},
+			":minAmount": &types.AttributeValueMemberN{Value: strconv.FormatFloat(minAmount, 'f', -1, 64)},
+			":maxAmount": &types.AttributeValueMemberN{Value: strconv.FormatFloat(maxAmount, 'f', -1, 64)},
+		},
+		ScanIndexForward:       aws.Bool(options.ScanIndexForward),
+		ConsistentRead:         aws.Bool(options.ConsistentRead),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
 	}
 
+	if !db.amountIndex || options.IndexName == databases.BaseTableIndex {
+		input.KeyConditionExpression = aws.String("accountId = :accountId")
+		input.FilterExpression = aws.String("#amount BETWEEN :minAmount AND :maxAmount")
+		return db.queryFiltered(ctx, input, options.Limit)
+	}
+
+	input.IndexName = aws.String(amountIndexName)
+	input.KeyConditionExpression = aws.String("accountId = :accountId AND #amount BETWEEN :minAmount AND :maxAmount")
 	if options.Limit > 0 {
 		input.Limit = aws.Int32(int32(options.Limit))
 	}
@@ -343,6 +799,121 @@ func (db *DynamoDBDatabase) QueryTransactionsByTimeRange(ctx context.Context, ac
 	if err != nil {
 		return nil, fmt.Errorf("Query operation failed: %w", err)
 	}
+	db.recordCapacity("readCapacityUnits", result.ConsumedCapacity)
+
+	return unmarshalTransactions(result.Items)
+}
+
+// queryFiltered runs a query with a filter expression, following pages until limit
+// matching items are found. DynamoDB applies Limit before filtering, so a single page
+// may hold fewer matches than requested.
+func (db *DynamoDBDatabase) queryFiltered(ctx context.Context, input *dynamodb.QueryInput, limit int64) ([]*databases.Transaction, error) {
+	var transactions []*databases.Transaction
+	for {
+		result, err := db.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("Query operation failed: %w", err)
+		}
+		db.recordCapacity("readCapacityUnits", result.ConsumedCapacity)
+
+		page, err := unmarshalTransactions(result.Items)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, page...)
+
+		if limit > 0 && int64(len(transactions)) >= limit {
+			return transactions[:limit], nil
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return transactions, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// unmarshalTransactions converts query result items to Transaction structs
+func unmarshalTransactions(items []map[string]types.AttributeValue) ([]*databases.Transaction, error) {
+	transactions := make([]*databases.Transaction, 0, len(items))
+	for _, item := range items {
+		var transaction databases.Transaction
+		if err := attributevalue.UnmarshalMap(item, &transaction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+		}
+		transactions = append(transactions, &transaction)
+	}
+	return transactions, nil
+}
+
+// ScanTransactions implements the Database interface by reading every page of the
+// requested segment
+func (db *DynamoDBDatabase) ScanTransactions(ctx context.Context, options *databases.ScanOptions) ([]*databases.Transaction, error) {
+	pageOptions := databases.ScanOptions{}
+	if options != nil {
+		pageOptions = *options
+	}
+
+	var transactions []*databases.Transaction
+	for {
+		page, err := db.ScanTransactionsPaged(ctx, &pageOptions)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, page.Transactions...)
+
+		if page.NextToken == "" {
+			return transactions, nil
+		}
+		pageOptions.StartToken = page.NextToken
+	}
+}
+
+// ScanTransactionsPaged implements the Database interface
+func (db *DynamoDBDatabase) ScanTransactionsPaged(ctx context.Context, options *databases.ScanOptions) (*databases.PagedTransactions, error) {
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	// Set default options if not provided
+	if options == nil {
+		options = &databases.ScanOptions{}
+	}
+
+	// Create Scan input
+	input := &dynamodb.ScanInput{
+		TableName:              aws.String(db.tableName),
+		ConsistentRead:         aws.Bool(options.ConsistentRead),
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	}
+
+	if options.Limit > 0 {
+		input.Limit = aws.Int32(int32(options.Limit))
+	}
+
+	// Restrict the scan to one segment for parallel scans
+	if options.TotalSegments > 1 {
+		if options.Segment < 0 || options.Segment >= options.TotalSegments {
+			return nil, fmt.Errorf("segment %d out of range for %d segments", options.Segment, options.TotalSegments)
+		}
+		input.Segment = aws.Int32(options.Segment)
+		input.TotalSegments = aws.Int32(options.TotalSegments)
+	}
+
+	// Resume from the previous page if a token was provided
+	if options.StartToken != "" {
+		startKey, err := decodePageToken(options.StartToken)
+		if err != nil {
+			return nil, err
+		}
+		input.ExclusiveStartKey = startKey
+	}
+
+	// Execute Scan operation
+	result, err := db.client.Scan(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("Scan operation failed: %w", err)
+	}
+	db.recordCapacity("readCapacityUnits", result.ConsumedCapacity)
 
 	// Unmarshal items to Transaction structs
 	transactions := make([]*databases.Transaction, 0, len(result.Items))
@@ -355,7 +926,19 @@ func (db *DynamoDBDatabase) QueryTransactionsByTimeRange(ctx context.Context, ac
 		transactions = append(transactions, &transaction)
 	}
 
-	return transactions, nil
+	page := &databases.PagedTransactions{
+		Transactions: transactions,
+	}
+
+	// Encode the last evaluated key so the caller can fetch the next page
+	if len(result.LastEvaluatedKey) > 0 {
+		page.NextToken, err = encodePageToken(result.LastEvaluatedKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
 }
 
 // BatchReadTransactions implements the Database interface
@@ -373,17 +956,15 @@ func (db *DynamoDBDatabase) BatchReadTransactions(ctx context.Context, keys []st
 	if options != nil && options.MaxBatchSize > 0 && options.MaxBatchSize < maxBatchSize {
 		maxBatchSize = options.MaxBatchSize
 	}
+	maxRetries, initialBackoff := batchRetryPolicy(options)
 
-	var transactions []*databases.Transaction
-	var unprocessedKeys []struct{ AccountID, UUID string }
+	// Each segment fills its own slots so segments can run concurrently
+	numSegments := (len(keys) + maxBatchSize - 1) / maxBatchSize
+	segmentResults := make([][]*databases.Transaction, numSegments)
+	segmentUnprocessed := make([]int, numSegments)
 
-	// Process keys in batches
-	for i := 0; i < len(keys); i += maxBatchSize {
-		end := i + maxBatchSize
-		if end > len(keys) {
-			end = len(keys)
-		}
-		batchKeys := keys[i:end]
+	err := forEachSegment(ctx, len(keys), maxBatchSize, options, func(segment, start, end int) error {
+		batchKeys := keys[start:end]
 
 		// Create BatchGetItem input
 		keysMap := make([]map[string]types.AttributeValue, 0, len(batchKeys))
@@ -394,46 +975,67 @@ func (db *DynamoDBDatabase) BatchReadTransactions(ctx context.Context, keys []st
 			})
 		}
 
-		input := &dynamodb.BatchGetItemInput{
-			RequestItems: map[string]types.KeysAndAttributes{
-				db.tableName: {
-					Keys: keysMap,
-				},
+		requestItems := map[string]types.KeysAndAttributes{
+			db.tableName: {
+				Keys: keysMap,
 			},
 		}
 
-		// Execute BatchGetItem operation
-		result, err := db.client.BatchGetItem(ctx, input)
-		if err != nil {
-			return nil, fmt.Errorf("BatchGetItem operation failed: %w", err)
-		}
+		// Re-submit only the unprocessed keys until none remain or retries are exhausted
+		for attempt := 0; ; attempt++ {
+			// Execute BatchGetItem operation
+			result, err := db.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems:           requestItems,
+				ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+			})
+			if err != nil {
+				return fmt.Errorf("BatchGetItem operation failed: %w", err)
+			}
+			for i := range result.ConsumedCapacity {
+				db.recordCapacity("readCapacityUnits", &result.ConsumedCapacity[i])
+			}
 
-		// Process results
-		if items, ok := result.Responses[db.tableName]; ok {
-			for _, item := range items {
-				var transaction databases.Transaction
-				err = attributevalue.UnmarshalMap(item, &transaction)
-				if err != nil {
-					return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+			// Process results
+			if items, ok := result.Responses[db.tableName]; ok {
+				for _, item := range items {
+					var transaction databases.Transaction
+					err = attributevalue.UnmarshalMap(item, &transaction)
+					if err != nil {
+						return fmt.Errorf("failed to unmarshal transaction: %w", err)
+					}
+					segmentResults[segment] = append(segmentResults[segment], &transaction)
 				}
-				transactions = append(transactions, &transaction)
 			}
-		}
 
-		// Handle unprocessed keys
-		if unprocessedKeyMap, ok := result.UnprocessedKeys[db.tableName]; ok && len(unprocessedKeyMap.Keys) > 0 {
-			for _, keyMap := range unprocessedKeyMap.Keys {
-				accountID := keyMap["accountId"].(*types.AttributeValueMemberS).Value
-				uuid := keyMap["uuid"].(*types.AttributeValueMemberS).Value
-				unprocessedKeys = append(unprocessedKeys, struct{ AccountID, UUID string }{accountID, uuid})
+			unprocessed, ok := result.UnprocessedKeys[db.tableName]
+			if !ok || len(unprocessed.Keys) == 0 {
+				return nil
 			}
+
+			if attempt >= maxRetries {
+				segmentUnprocessed[segment] = len(unprocessed.Keys)
+				return nil
+			}
+
+			if err := sleepWithContext(ctx, backoffDelay(initialBackoff, attempt)); err != nil {
+				return err
+			}
+			requestItems = map[string]types.KeysAndAttributes{db.tableName: unprocessed}
 		}
+	})
+
+	var transactions []*databases.Transaction
+	unprocessedCount := 0
+	for i := range segmentResults {
+		transactions = append(transactions, segmentResults[i]...)
+		unprocessedCount += segmentUnprocessed[i]
 	}
 
-	// Retry unprocessed keys if any (in a production implementation)
-	// Here we just return what we have
-	if len(unprocessedKeys) > 0 {
-		return transactions, fmt.Errorf("%d keys were not processed", len(unprocessedKeys))
+	if unprocessedCount > 0 {
+		err = errors.Join(err, fmt.Errorf("%d keys were not processed after %d retries", unprocessedCount, maxRetries))
+	}
+	if err != nil {
+		return transactions, err
 	}
 
 	return transactions, nil
@@ -454,21 +1056,18 @@ func (db *DynamoDBDatabase) BatchWriteTransactions(ctx context.Context, transact
 	if options != nil && options.MaxBatchSize > 0 && options.MaxBatchSize < maxBatchSize {
 		maxBatchSize = options.MaxBatchSize
 	}
+	maxRetries, initialBackoff := batchRetryPolicy(options)
 
-	var unprocessedItems []*databases.Transaction
+	numSegments := (len(transactions) + maxBatchSize - 1) / maxBatchSize
+	segmentUnprocessed := make([]int, numSegments)
 
-	// Process transactions in batches
-	for i := 0; i < len(transactions); i += maxBatchSize {
-		end := i + maxBatchSize
-		if end > len(transactions) {
-			end = len(transactions)
-		}
-		batchTransactions := transactions[i:end]
+	err := forEachSegment(ctx, len(transactions), maxBatchSize, options, func(segment, start, end int) error {
+		batchTransactions := transactions[start:end]
 
 		// Create BatchWriteItem input
 		writeRequests := make([]types.WriteRequest, 0, len(batchTransactions))
 		for _, transaction := range batchTransactions {
-			item, err := attributevalue.MarshalMap(transaction)
+			item, err := db.marshalTransaction(transaction)
 			if err != nil {
 				return fmt.Errorf("failed to marshal transaction: %w", err)
 			}
@@ -480,40 +1079,51 @@ func (db *DynamoDBDatabase) BatchWriteTransactions(ctx context.Context, transact
 			})
 		}
 
-		input := &dynamodb.BatchWriteItemInput{
-			RequestItems: map[string][]types.WriteRequest{
-				db.tableName: writeRequests,
-			},
+		requestItems := map[string][]types.WriteRequest{
+			db.tableName: writeRequests,
 		}
 
-		// Execute BatchWriteItem operation
-		result, err := db.client.BatchWriteItem(ctx, input)
-		if err != nil {
-			return fmt.Errorf("BatchWriteItem operation failed: %w", err)
-		}
+		// Re-submit only the unprocessed items until none remain or retries are exhausted
+		for attempt := 0; ; attempt++ {
+			// Execute BatchWriteItem operation
+			result, err := db.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems:           requestItems,
+				ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+			})
+			if err != nil {
+				return fmt.Errorf("BatchWriteItem operation failed: %w", err)
+			}
+			for i := range result.ConsumedCapacity {
+				db.recordCapacity("writeCapacityUnits", &result.ConsumedCapacity[i])
+			}
 
-		// Handle unprocessed items
-		if unprocessedItemsMap, ok := result.UnprocessedItems[db.tableName]; ok && len(unprocessedItemsMap) > 0 {
-			for _, writeRequest := range unprocessedItemsMap {
-				if writeRequest.PutRequest != nil {
-					var transaction databases.Transaction
-					err = attributevalue.UnmarshalMap(writeRequest.PutRequest.Item, &transaction)
-					if err != nil {
-						return fmt.Errorf("failed to unmarshal unprocessed transaction: %w", err)
-					}
-					unprocessedItems = append(unprocessedItems, &transaction)
-				}
+			unprocessed, ok := result.UnprocessedItems[db.tableName]
+			if !ok || len(unprocessed) == 0 {
+				return nil
+			}
+
+			if attempt >= maxRetries {
+				segmentUnprocessed[segment] = len(unprocessed)
+				return nil
 			}
+
+			if err := sleepWithContext(ctx, backoffDelay(initialBackoff, attempt)); err != nil {
+				return err
+			}
+			requestItems = map[string][]types.WriteRequest{db.tableName: unprocessed}
 		}
+	})
+
+	unprocessedCount := 0
+	for _, n := range segmentUnprocessed {
+		unprocessedCount += n
 	}
 
-	// Retry unprocessed items if any (in a production implementation)
-	// Here we just return an error
-	if len(unprocessedItems) > 0 {
-		return fmt.Errorf("%d transactions were not processed", len(unprocessedItems))
+	if unprocessedCount > 0 {
+		err = errors.Join(err, fmt.Errorf("%d transactions were not processed after %d retries", unprocessedCount, maxRetries))
 	}
 
-	return nil
+	return err
 }
 
 // ExecuteTransactWrite implements the Database interface
@@ -534,7 +1144,7 @@ func (db *DynamoDBDatabase) ExecuteTransactWrite(ctx context.Context, transactio
 	// Create TransactWriteItems input
 	transactItems := make([]types.TransactWriteItem, 0, len(transactions))
 	for _, transaction := range transactions {
-		item, err := attributevalue.MarshalMap(transaction)
+		item, err := db.marshalTransaction(transaction)
 		if err != nil {
 			return fmt.Errorf("failed to marshal transaction: %w", err)
 		}
@@ -560,8 +1170,68 @@ func (db *DynamoDBDatabase) ExecuteTransactWrite(ctx context.Context, transactio
 	return nil
 }
 
+// ExecuteTransactRead implements the Database interface
+func (db *DynamoDBDatabase) ExecuteTransactRead(ctx context.Context, keys []struct{ AccountID, UUID string }) ([]*databases.Transaction, error) {
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	if len(keys) == 0 {
+		return []*databases.Transaction{}, nil
+	}
+
+	// DynamoDB TransactGetItems limit is 25
+	if len(keys) > 25 {
+		return nil, fmt.Errorf("too many keys for a single transact read (limit is 25)")
+	}
+
+	// Create TransactGetItems input
+	transactItems := make([]types.TransactGetItem, 0, len(keys))
+	for _, key := range keys {
+		transactItems = append(transactItems, types.TransactGetItem{
+			Get: &types.Get{
+				TableName: aws.String(db.tableName),
+				Key: map[string]types.AttributeValue{
+					"accountId": &types.AttributeValueMemberS{Value: key.AccountID},
+					"uuid":      &types.AttributeValueMemberS{Value: key.UUID},
+				},
+			},
+		})
+	}
+
+	// Execute TransactGetItems operation
+	result, err := db.client.TransactGetItems(ctx, &dynamodb.TransactGetItemsInput{
+		TransactItems:          transactItems,
+		ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("TransactGetItems operation failed: %w", err)
+	}
+	for i := range result.ConsumedCapacity {
+		db.recordCapacity("readCapacityUnits", &result.ConsumedCapacity[i])
+	}
+
+	// Responses are positional; keys that do not exist come back empty
+	transactions := make([]*databases.Transaction, 0, len(result.Responses))
+	for _, response := range result.Responses {
+		if len(response.Item) == 0 {
+			continue
+		}
+		var transaction databases.Transaction
+		if err := attributevalue.UnmarshalMap(response.Item, &transaction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+		}
+		transactions = append(transactions, &transaction)
+	}
+
+	return transactions, nil
+}
+
 // GetMetrics implements the Database interface
 func (db *DynamoDBDatabase) GetMetrics() map[string]interface{} {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
 	// Return a copy to avoid race conditions
 	metrics := make(map[string]interface{})
 	for k, v := range db.metrics {
@@ -572,6 +1242,9 @@ func (db *DynamoDBDatabase) GetMetrics() map[string]interface{} {
 
 // ResetMetrics implements the Database interface
 func (db *DynamoDBDatabase) ResetMetrics() {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
 	db.metrics = map[string]interface{}{
 		"readOperations":         0,
 		"writeOperations":        0,
@@ -598,10 +1271,77 @@ func (db *DynamoDBDatabase) ResetMetrics() {
 	}
 }
 
+// conditionError marks a rejected condition expression as databases.ErrConditionFailed
+// and counts it in the conditionalCheckFailed metric
+func (db *DynamoDBDatabase) conditionError(err error) error {
+	var conditionErr *types.ConditionalCheckFailedException
+	if !errors.As(err, &conditionErr) {
+		return err
+	}
+
+	db.metricsMu.Lock()
+	current, _ := db.metrics["conditionalCheckFailed"].(int)
+	db.metrics["conditionalCheckFailed"] = current + 1
+	db.metricsMu.Unlock()
+
+	return fmt.Errorf("%w: %w", databases.ErrConditionFailed, err)
+}
+
+// incrementMetric adds one to the given counter metric
+func (db *DynamoDBDatabase) incrementMetric(metric string) {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
+	current, _ := db.metrics[metric].(int)
+	db.metrics[metric] = current + 1
+}
+
+// recordCapacity adds the consumed capacity from a response to the given metric
+func (db *DynamoDBDatabase) recordCapacity(metric string, capacity *types.ConsumedCapacity) {
+	if capacity == nil || capacity.CapacityUnits == nil {
+		return
+	}
+
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
+	current, _ := db.metrics[metric].(float64)
+	db.metrics[metric] = current + *capacity.CapacityUnits
+}
+
 // createTransactionTable creates a new DynamoDB table for transactions
-func (db *DynamoDBDatabase) createTransactionTable(rcus, wcus int64) error {
-	createTableInput := &dynamodb.CreateTableInput{
+func (db *DynamoDBDatabase) createTransactionTable(dbConfig DynamoDBConfig) error {
+	createTableInput := newCreateTableInput(dbConfig)
+
+	_, err := db.client.CreateTable(context.Background(), createTableInput)
+	if err != nil {
+		var alreadyExistsErr *types.ResourceInUseException
+		if errors.As(err, &alreadyExistsErr) {
+			// Table already exists, which is fine
+			return nil
+		}
+		return err
+	}
+
+	// Wait for table to become active
+	describeTableInput := &dynamodb.DescribeTableInput{
 		TableName: aws.String(db.tableName),
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(db.client)
+	err = waiter.Wait(context.Background(), describeTableInput, 5*time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to wait for table creation: %w", err)
+	}
+
+	return nil
+}
+
+// newCreateTableInput builds the table definition for the configured billing mode.
+// Provisioned throughput is only set on the table and GSI in provisioned mode.
+func newCreateTableInput(dbConfig DynamoDBConfig) *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String(dbConfig.TableName),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{
 				AttributeName: aws.String("accountId"),
@@ -628,7 +1368,7 @@ func (db *DynamoDBDatabase) createTransactionTable(rcus, wcus int64) error {
 		},
 		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
 			{
-				IndexName: aws.String("TimestampIndex"),
+				IndexName: aws.String(timestampIndexName),
 				KeySchema: []types.KeySchemaElement{
 					{
 						AttributeName: aws.String("accountId"),
@@ -642,38 +1382,229 @@ func (db *DynamoDBDatabase) createTransactionTable(rcus, wcus int64) error {
 				Projection: &types.Projection{
 					ProjectionType: types.ProjectionTypeAll,
 				},
-				ProvisionedThroughput: &types.ProvisionedThroughput{
-					ReadCapacityUnits:  aws.Int64(rcus),
-					WriteCapacityUnits: aws.Int64(wcus),
-				},
 			},
 		},
-		ProvisionedThroughput: &types.ProvisionedThroughput{
-			ReadCapacityUnits:  aws.Int64(rcus),
-			WriteCapacityUnits: aws.Int64(wcus),
-		},
 	}
 
-	_, err := db.client.CreateTable(context.Background(), createTableInput)
-	if err != nil {
-		var alreadyExistsErr *types.ResourceInUseException
-		if errors.As(err, &alreadyExistsErr) {
-			// Table already exists, which is fine
-			return nil
+	// Local secondary indexes can only be created with the table and share its throughput
+	if dbConfig.AmountIndex {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String("amount"),
+			AttributeType: types.ScalarAttributeTypeN,
+		})
+		input.LocalSecondaryIndexes = []types.LocalSecondaryIndex{
+			{
+				IndexName: aws.String(amountIndexName),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("accountId"),
+						KeyType:       types.KeyTypeHash,
+					},
+					{
+						AttributeName: aws.String("amount"),
+						KeyType:       types.KeyTypeRange,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeAll,
+				},
+			},
 		}
-		return err
 	}
 
-	// Wait for table to become active
-	describeTableInput := &dynamodb.DescribeTableInput{
+	if dbConfig.BillingMode == BillingModePayPerRequest {
+		input.BillingMode = types.BillingModePayPerRequest
+		return input
+	}
+
+	throughput := &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(dbConfig.ProvisionedRCUs),
+		WriteCapacityUnits: aws.Int64(dbConfig.ProvisionedWCUs),
+	}
+	input.BillingMode = types.BillingModeProvisioned
+	input.ProvisionedThroughput = throughput
+	for i := range input.GlobalSecondaryIndexes {
+		input.GlobalSecondaryIndexes[i].ProvisionedThroughput = throughput
+	}
+
+	return input
+}
+
+// enableTTL turns on TTL for the expiresAt attribute unless it is already enabled
+func (db *DynamoDBDatabase) enableTTL(ctx context.Context) error {
+	described, err := db.client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{
 		TableName: aws.String(db.tableName),
+	})
+	if err != nil {
+		return fmt.Errorf("DescribeTimeToLive operation failed: %w", err)
+	}
+	if desc := described.TimeToLiveDescription; desc != nil &&
+		aws.ToString(desc.AttributeName) == ttlAttributeName &&
+		(desc.TimeToLiveStatus == types.TimeToLiveStatusEnabled || desc.TimeToLiveStatus == types.TimeToLiveStatusEnabling) {
+		return nil
 	}
 
-	waiter := dynamodb.NewTableExistsWaiter(db.client)
-	err = waiter.Wait(context.Background(), describeTableInput, 5*time.Minute)
+	_, err = db.client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(db.tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(ttlAttributeName),
+			Enabled:       aws.Bool(true),
+		},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to wait for table creation: %w", err)
+		return fmt.Errorf("UpdateTimeToLive operation failed: %w", err)
 	}
 
 	return nil
 }
+
+// marshalTransaction converts a transaction to a DynamoDB item, setting expiresAt
+// from the configured TTL when the transaction does not carry its own expiry
+func (db *DynamoDBDatabase) marshalTransaction(transaction *databases.Transaction) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	if db.ttl > 0 && transaction.ExpiresAt == 0 {
+		expiresAt := time.Now().Add(db.ttl).Unix()
+		item[ttlAttributeName] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)}
+	}
+
+	return item, nil
+}
+
+// encodePageToken serializes a LastEvaluatedKey into an opaque continuation token
+func encodePageToken(key map[string]types.AttributeValue) (string, error) {
+	var plain map[string]interface{}
+	if err := attributevalue.UnmarshalMap(key, &plain); err != nil {
+		return "", fmt.Errorf("failed to unmarshal last evaluated key: %w", err)
+	}
+
+	data, err := json.Marshal(plain)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode page token: %w", err)
+	}
+
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+// decodePageToken converts a continuation token back into an ExclusiveStartKey
+func decodePageToken(token string) (map[string]types.AttributeValue, error) {
+	data, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page token: %w", err)
+	}
+
+	var plain map[string]interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, fmt.Errorf("invalid page token: %w", err)
+	}
+
+	key, err := attributevalue.MarshalMap(plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal start key: %w", err)
+	}
+
+	return key, nil
+}
+
+// defaultBatchConcurrency is the number of segments in flight when BatchOptions.Parallel
+// is set without a MaxConcurrency
+const defaultBatchConcurrency = 10
+
+// forEachSegment splits n items into segments of at most size items and calls fn with
+// each segment's index and bounds. Segments run one after another, stopping at the first
+// error, unless options.Parallel is set, in which case they run on a pool of
+// options.MaxConcurrency workers and the errors of all failed segments are joined.
+func forEachSegment(ctx context.Context, n, size int, options *databases.BatchOptions, fn func(segment, start, end int) error) error {
+	numSegments := (n + size - 1) / size
+	bounds := func(segment int) (int, int) {
+		return segment * size, min((segment+1)*size, n)
+	}
+
+	if options == nil || !options.Parallel {
+		for segment := 0; segment < numSegments; segment++ {
+			start, end := bounds(segment)
+			if err := fn(segment, start, end); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	workers := options.MaxConcurrency
+	if workers <= 0 {
+		workers = defaultBatchConcurrency
+	}
+	workers = min(workers, numSegments)
+
+	segments := make(chan int, numSegments)
+	for segment := 0; segment < numSegments; segment++ {
+		segments <- segment
+	}
+	close(segments)
+
+	segmentErrs := make([]error, numSegments)
+	var skipped atomic.Bool
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for segment := range segments {
+				// Skip the remaining segments once the context is done
+				if ctx.Err() != nil {
+					skipped.Store(true)
+					continue
+				}
+				start, end := bounds(segment)
+				segmentErrs[segment] = fn(segment, start, end)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if skipped.Load() {
+		segmentErrs = append(segmentErrs, ctx.Err())
+	}
+	return errors.Join(segmentErrs...)
+}
+
+// batchRetryPolicy returns the retry limit and initial backoff for unprocessed batch items
+func batchRetryPolicy(options *databases.BatchOptions) (int, time.Duration) {
+	maxRetries := 3
+	initialBackoff := 50 * time.Millisecond
+
+	if options != nil {
+		if options.MaxRetries > 0 {
+			maxRetries = options.MaxRetries
+		} else if options.MaxRetries < 0 {
+			maxRetries = 0 // Retries disabled
+		}
+		if options.InitialBackoff > 0 {
+			initialBackoff = options.InitialBackoff
+		}
+	}
+
+	return maxRetries, initialBackoff
+}
+
+// backoffDelay computes an exponential backoff with full jitter for the given attempt
+func backoffDelay(initial time.Duration, attempt int) time.Duration {
+	maxDelay := initial << uint(attempt)
+	return time.Duration(rand.Int63n(int64(maxDelay) + 1))
+}
+
+// sleepWithContext waits for the given duration or until the context is cancelled
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
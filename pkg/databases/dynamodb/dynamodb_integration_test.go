@@ -0,0 +1,140 @@
+//go:build integration
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// newTestDatabase creates a pay-per-request table unique to the test on the DynamoDB
+// Local endpoint at DYNAMODB_ENDPOINT (default: http://localhost:8000) and deletes it
+// when the test finishes. The test is skipped when no endpoint is reachable.
+func newTestDatabase(t *testing.T) *DynamoDBDatabase {
+	t.Helper()
+
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://localhost:8000"
+	}
+	// DynamoDB Local accepts any credentials, but the SDK still needs some to sign with
+	if os.Getenv("AWS_ACCESS_KEY_ID") == "" {
+		t.Setenv("AWS_ACCESS_KEY_ID", "local")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "local")
+	}
+
+	tableName := fmt.Sprintf("test-%s-%d", t.Name(), time.Now().UnixNano())
+	db, err := NewDynamoDBDatabase(DynamoDBConfig{
+		Region:      "us-east-1",
+		TableName:   tableName,
+		Endpoint:    endpoint,
+		CreateTable: true,
+		BillingMode: BillingModePayPerRequest,
+	})
+	if err != nil {
+		t.Skipf("DynamoDB Local is not reachable at %s: %v", endpoint, err)
+	}
+
+	ctx := context.Background()
+	if err := db.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.client.DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String(tableName)})
+	})
+
+	return db
+}
+
+func TestConditionalDelete(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	transaction := &databases.Transaction{
+		AccountID:       "account-1",
+		UUID:            "tx-1",
+		Timestamp:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Amount:          42.5,
+		TransactionType: databases.Deposit,
+	}
+	if err := db.WriteTransaction(ctx, transaction, nil); err != nil {
+		t.Fatalf("WriteTransaction() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		uuid          string
+		condition     string
+		wantCondition bool
+		wantOldItem   bool
+	}{
+		{"condition not met", transaction.UUID, "attribute_not_exists(uuid)", true, false},
+		{"condition met", transaction.UUID, "attribute_exists(uuid)", false, true},
+		// The item is gone, so deleting it again with the same condition fails
+		{"item already deleted", transaction.UUID, "attribute_exists(uuid)", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &databases.DeleteOptions{Condition: tt.condition, ReturnOldItem: true}
+			err := db.DeleteTransaction(ctx, transaction.AccountID, tt.uuid, options)
+
+			if tt.wantCondition {
+				if !databases.IsConditionFailed(err) {
+					t.Fatalf("DeleteTransaction() error = %v, want ErrConditionFailed", err)
+				}
+			} else if err != nil {
+				t.Fatalf("DeleteTransaction() error = %v", err)
+			}
+
+			if tt.wantOldItem {
+				if options.OldItem == nil || options.OldItem.UUID != transaction.UUID || options.OldItem.Amount != transaction.Amount {
+					t.Errorf("OldItem = %+v, want the deleted transaction", options.OldItem)
+				}
+			} else if options.OldItem != nil {
+				t.Errorf("OldItem = %+v, want nil", options.OldItem)
+			}
+		})
+	}
+
+	if got := db.GetMetrics()["conditionalCheckFailed"]; got != 2 {
+		t.Errorf("conditionalCheckFailed = %v, want 2", got)
+	}
+}
+
+func TestMetricsSurviveReinitialize(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	transaction := &databases.Transaction{
+		AccountID:       "account-1",
+		UUID:            "tx-1",
+		Timestamp:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Amount:          42.5,
+		TransactionType: databases.Deposit,
+	}
+	for i := 0; i < 3; i++ {
+		if err := db.WriteTransaction(ctx, transaction, nil); err != nil {
+			t.Fatalf("WriteTransaction() error = %v", err)
+		}
+		// A cold-start run closes and initializes the adapter between measurements
+		if err := db.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if err := db.Initialize(ctx); err != nil {
+			t.Fatalf("Initialize() error = %v", err)
+		}
+	}
+
+	if got := db.GetMetrics()["writeOperations"]; got != 3 {
+		t.Errorf("writeOperations = %v, want 3", got)
+	}
+}
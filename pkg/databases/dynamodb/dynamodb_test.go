@@ -0,0 +1,266 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// mockClient answers batch calls with the configured functions and records each
+// request; single-item calls and queries report capacity consumed. Calls to other
+// methods panic on the nil embedded interface.
+type mockClient struct {
+	dynamoDBAPI
+
+	capacity       float64
+	mu             sync.Mutex
+	batchWriteItem func(attempt int, input *dynamodb.BatchWriteItemInput) *dynamodb.BatchWriteItemOutput
+	batchGetItem   func(attempt int, input *dynamodb.BatchGetItemInput) *dynamodb.BatchGetItemOutput
+	writeRequests  [][]types.WriteRequest
+	getRequests    [][]map[string]types.AttributeValue
+}
+
+func (m *mockClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.writeRequests = append(m.writeRequests, params.RequestItems[testTableName])
+	return m.batchWriteItem(len(m.writeRequests)-1, params), nil
+}
+
+func (m *mockClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.getRequests = append(m.getRequests, params.RequestItems[testTableName].Keys)
+	return m.batchGetItem(len(m.getRequests)-1, params), nil
+}
+
+func (m *mockClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{
+		Item:             map[string]types.AttributeValue{"UUID": &types.AttributeValueMemberS{Value: "tx-0"}},
+		ConsumedCapacity: m.consumedCapacity(params.ReturnConsumedCapacity),
+	}, nil
+}
+
+func (m *mockClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return &dynamodb.PutItemOutput{ConsumedCapacity: m.consumedCapacity(params.ReturnConsumedCapacity)}, nil
+}
+
+func (m *mockClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return &dynamodb.QueryOutput{ConsumedCapacity: m.consumedCapacity(params.ReturnConsumedCapacity)}, nil
+}
+
+// consumedCapacity returns the configured capacity, or nil when the request did not ask
+// for it, as DynamoDB does
+func (m *mockClient) consumedCapacity(mode types.ReturnConsumedCapacity) *types.ConsumedCapacity {
+	if mode == "" || mode == types.ReturnConsumedCapacityNone {
+		return nil
+	}
+	return &types.ConsumedCapacity{TableName: aws.String(testTableName), CapacityUnits: aws.Float64(m.capacity)}
+}
+
+const testTableName = "transactions"
+
+// newMockDatabase returns an initialized adapter that sends its calls to client
+func newMockDatabase(client dynamoDBAPI) *DynamoDBDatabase {
+	db := &DynamoDBDatabase{client: client, tableName: testTableName, initialized: true}
+	db.ResetMetrics()
+	return db
+}
+
+// testTransactions returns n transactions for one account with UUIDs tx-0 to tx-n-1
+func testTransactions(n int) []*databases.Transaction {
+	transactions := make([]*databases.Transaction, n)
+	for i := range transactions {
+		transactions[i] = &databases.Transaction{
+			AccountID:       "account-1",
+			UUID:            fmt.Sprintf("tx-%d", i),
+			Timestamp:       time.Date(2024, 1, 1, 0, i, 0, 0, time.UTC),
+			Amount:          float64(i),
+			TransactionType: databases.Deposit,
+		}
+	}
+	return transactions
+}
+
+// uuidOf returns the uuid attribute of a key
+func uuidOf(item map[string]types.AttributeValue) string {
+	return item["uuid"].(*types.AttributeValueMemberS).Value
+}
+
+func TestBatchWriteRetriesUnprocessedItems(t *testing.T) {
+	client := &mockClient{
+		batchWriteItem: func(attempt int, input *dynamodb.BatchWriteItemInput) *dynamodb.BatchWriteItemOutput {
+			output := &dynamodb.BatchWriteItemOutput{}
+			// The first attempt leaves the last two items unprocessed
+			if attempt == 0 {
+				requests := input.RequestItems[testTableName]
+				output.UnprocessedItems = map[string][]types.WriteRequest{testTableName: requests[len(requests)-2:]}
+			}
+			return output
+		},
+	}
+	db := newMockDatabase(client)
+
+	err := db.BatchWriteTransactions(context.Background(), testTransactions(5), &databases.BatchOptions{InitialBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("BatchWriteTransactions() error = %v", err)
+	}
+
+	if len(client.writeRequests) != 2 {
+		t.Fatalf("BatchWriteItem called %d times, want 2", len(client.writeRequests))
+	}
+	if !reflect.DeepEqual(client.writeRequests[1], client.writeRequests[0][3:]) {
+		t.Errorf("retry submitted %d items, want only the 2 unprocessed ones", len(client.writeRequests[1]))
+	}
+}
+
+func TestBatchWriteRetriesExhausted(t *testing.T) {
+	client := &mockClient{
+		batchWriteItem: func(attempt int, input *dynamodb.BatchWriteItemInput) *dynamodb.BatchWriteItemOutput {
+			// Every attempt leaves the first item unprocessed
+			requests := input.RequestItems[testTableName]
+			return &dynamodb.BatchWriteItemOutput{
+				UnprocessedItems: map[string][]types.WriteRequest{testTableName: requests[:1]},
+			}
+		},
+	}
+	db := newMockDatabase(client)
+
+	err := db.BatchWriteTransactions(context.Background(), testTransactions(3), &databases.BatchOptions{MaxRetries: 2, InitialBackoff: time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "1 transactions were not processed after 2 retries") {
+		t.Errorf("BatchWriteTransactions() error = %v, want 1 transaction not processed after 2 retries", err)
+	}
+	if len(client.writeRequests) != 3 {
+		t.Errorf("BatchWriteItem called %d times, want 3 (one attempt plus 2 retries)", len(client.writeRequests))
+	}
+}
+
+func TestBatchWriteRetriesDisabled(t *testing.T) {
+	client := &mockClient{
+		batchWriteItem: func(attempt int, input *dynamodb.BatchWriteItemInput) *dynamodb.BatchWriteItemOutput {
+			return &dynamodb.BatchWriteItemOutput{UnprocessedItems: input.RequestItems}
+		},
+	}
+	db := newMockDatabase(client)
+
+	err := db.BatchWriteTransactions(context.Background(), testTransactions(2), &databases.BatchOptions{MaxRetries: -1})
+	if err == nil {
+		t.Error("BatchWriteTransactions() error = nil, want unprocessed items reported")
+	}
+	if len(client.writeRequests) != 1 {
+		t.Errorf("BatchWriteItem called %d times, want 1", len(client.writeRequests))
+	}
+}
+
+func TestBatchReadRetriesUnprocessedKeys(t *testing.T) {
+	stored := make(map[string]map[string]types.AttributeValue)
+	for _, transaction := range testTransactions(4) {
+		item, err := newMockDatabase(nil).marshalTransaction(transaction)
+		if err != nil {
+			t.Fatalf("marshalTransaction() error = %v", err)
+		}
+		stored[transaction.UUID] = item
+	}
+
+	client := &mockClient{
+		batchGetItem: func(attempt int, input *dynamodb.BatchGetItemInput) *dynamodb.BatchGetItemOutput {
+			keys := input.RequestItems[testTableName].Keys
+			// The first attempt only returns the first key and leaves the rest unprocessed
+			if attempt == 0 {
+				return &dynamodb.BatchGetItemOutput{
+					Responses:       map[string][]map[string]types.AttributeValue{testTableName: {stored[uuidOf(keys[0])]}},
+					UnprocessedKeys: map[string]types.KeysAndAttributes{testTableName: {Keys: keys[1:]}},
+				}
+			}
+			var items []map[string]types.AttributeValue
+			for _, key := range keys {
+				items = append(items, stored[uuidOf(key)])
+			}
+			return &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{testTableName: items}}
+		},
+	}
+	db := newMockDatabase(client)
+
+	keys := []struct{ AccountID, UUID string }{
+		{"account-1", "tx-0"}, {"account-1", "tx-1"}, {"account-1", "tx-2"}, {"account-1", "tx-3"},
+	}
+	got, err := db.BatchReadTransactions(context.Background(), keys, &databases.BatchOptions{InitialBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("BatchReadTransactions() error = %v", err)
+	}
+	if len(got) != len(keys) {
+		t.Errorf("BatchReadTransactions() returned %d transactions, want %d", len(got), len(keys))
+	}
+
+	if len(client.getRequests) != 2 {
+		t.Fatalf("BatchGetItem called %d times, want 2", len(client.getRequests))
+	}
+	if retried := len(client.getRequests[1]); retried != 3 {
+		t.Errorf("retry submitted %d keys, want only the 3 unprocessed", retried)
+	}
+}
+
+func TestConsumedCapacity(t *testing.T) {
+	client := &mockClient{
+		capacity: 0.5,
+		batchWriteItem: func(attempt int, input *dynamodb.BatchWriteItemInput) *dynamodb.BatchWriteItemOutput {
+			return &dynamodb.BatchWriteItemOutput{
+				ConsumedCapacity: []types.ConsumedCapacity{{TableName: aws.String(testTableName), CapacityUnits: aws.Float64(4)}},
+			}
+		},
+		batchGetItem: func(attempt int, input *dynamodb.BatchGetItemInput) *dynamodb.BatchGetItemOutput {
+			return &dynamodb.BatchGetItemOutput{
+				ConsumedCapacity: []types.ConsumedCapacity{{TableName: aws.String(testTableName), CapacityUnits: aws.Float64(1.5)}},
+			}
+		},
+	}
+	db := newMockDatabase(client)
+	ctx := context.Background()
+	transactions := testTransactions(2)
+	keys := []struct{ AccountID, UUID string }{{"account-1", "tx-0"}}
+
+	// Capacity accumulates across repeated calls of every operation
+	for i := 0; i < 2; i++ {
+		if _, err := db.ReadTransaction(ctx, "account-1", "tx-0", nil); err != nil {
+			t.Fatalf("ReadTransaction() error = %v", err)
+		}
+		if _, err := db.QueryTransactionsByAccount(ctx, "account-1", nil); err != nil {
+			t.Fatalf("QueryTransactionsByAccount() error = %v", err)
+		}
+		if _, err := db.BatchReadTransactions(ctx, keys, nil); err != nil {
+			t.Fatalf("BatchReadTransactions() error = %v", err)
+		}
+		if err := db.WriteTransaction(ctx, transactions[0], nil); err != nil {
+			t.Fatalf("WriteTransaction() error = %v", err)
+		}
+		if err := db.BatchWriteTransactions(ctx, transactions, nil); err != nil {
+			t.Fatalf("BatchWriteTransactions() error = %v", err)
+		}
+	}
+
+	metrics := db.GetMetrics()
+	// Two rounds of GetItem (0.5), Query (0.5) and BatchGetItem (1.5)
+	if got := metrics["readCapacityUnits"]; got != 5.0 {
+		t.Errorf("readCapacityUnits = %v, want 5", got)
+	}
+	// Two rounds of PutItem (0.5) and BatchWriteItem (4)
+	if got := metrics["writeCapacityUnits"]; got != 9.0 {
+		t.Errorf("writeCapacityUnits = %v, want 9", got)
+	}
+
+	db.ResetMetrics()
+	if got := db.GetMetrics()["readCapacityUnits"]; got != 0.0 {
+		t.Errorf("readCapacityUnits after ResetMetrics() = %v, want 0", got)
+	}
+}
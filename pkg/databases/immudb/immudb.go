@@ -2,27 +2,73 @@ package immudb
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/client"
 	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // ImmuDBAdapter implements the Database interface for ImmuDB
 type ImmuDBAdapter struct {
-	client    client.ImmuClient
 	options   *client.Options
 	dbName    string
 	tableName string
+	poolSize  int
+	mu        sync.Mutex // Guards connected, sessions and pool
 	connected bool
+	sessions  []client.ImmuClient    // Every open session, closed by Close
+	pool      chan client.ImmuClient // Idle sessions handed out to callers
 	config    map[string]interface{}
 	metrics   map[string]interface{}
+	metricsMu sync.Mutex
+	latencies map[string]time.Duration // Cumulative latency per operation kind, used for averages
+	queries   immuDBQueries            // CRUD statements for tableName, built by Initialize
 }
 
+// immuDBQueries holds the CRUD statements, which only depend on the table name, so the
+// hot paths don't format SQL on every call
+type immuDBQueries struct {
+	selectByUUID string
+	insert       string
+	update       string
+	delete       string
+}
+
+// newImmuDBQueries builds the CRUD statements for tableName
+func newImmuDBQueries(tableName string) immuDBQueries {
+	return immuDBQueries{
+		selectByUUID: fmt.Sprintf("SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM %s WHERE uuid = @uuid", tableName),
+		insert: fmt.Sprintf(
+			"INSERT INTO %s (uuid, account_id, timestamp, amount, transaction_type, metadata) VALUES (@uuid, @account_id, @timestamp, @amount, @transaction_type, @metadata)",
+			tableName,
+		),
+		update: fmt.Sprintf("UPDATE %s SET amount = @amount, metadata = @metadata WHERE uuid = @uuid", tableName),
+		delete: fmt.Sprintf("DELETE FROM %s WHERE uuid = @uuid", tableName),
+	}
+}
+
+// defaultPoolSize is the number of sessions opened when poolSize is not configured
+const defaultPoolSize = 4
+
 // ImmuDBFactory creates ImmuDB database instances
 type ImmuDBFactory struct{}
 
+func init() {
+	databases.RegisterFactory("immudb", NewImmuDBFactory())
+}
+
 // NewImmuDBFactory creates a new factory for ImmuDB
 func NewImmuDBFactory() *ImmuDBFactory {
 	return &ImmuDBFactory{}
@@ -38,6 +84,7 @@ func (f *ImmuDBFactory) CreateDatabase(config map[string]interface{}) (databases
 		"password":  "immudb",
 		"database":  "defaultdb",
 		"tableName": "transactions",
+		"poolSize":  defaultPoolSize,
 	}
 
 	// Override defaults with provided config
@@ -64,6 +111,18 @@ func (f *ImmuDBFactory) CreateDatabase(config map[string]interface{}) (databases
 	password := fmt.Sprintf("%v", defaultConfig["password"])
 	dbName := fmt.Sprintf("%v", defaultConfig["database"])
 	tableName := fmt.Sprintf("%v", defaultConfig["tableName"])
+	var poolSize int
+	switch v := defaultConfig["poolSize"].(type) {
+	case int:
+		poolSize = v
+	case float64:
+		poolSize = int(v)
+	default:
+		poolSize = defaultPoolSize
+	}
+	if poolSize < 1 {
+		return nil, fmt.Errorf("poolSize must be at least 1, got %d", poolSize)
+	}
 
 	// Create ImmuDB options
 	options := client.DefaultOptions().
@@ -72,38 +131,98 @@ func (f *ImmuDBFactory) CreateDatabase(config map[string]interface{}) (databases
 		WithUsername(username).
 		WithPassword(password)
 
+	// Use TLS transport when requested; otherwise the default plaintext dial options apply
+	if useTLS, ok := defaultConfig["tls"].(bool); ok && useTLS {
+		tlsConfig, err := newTLSConfig(defaultConfig)
+		if err != nil {
+			return nil, err
+		}
+		options = options.WithDialOptions([]grpc.DialOption{
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		})
+	}
+
 	// Create adapter
 	adapter := &ImmuDBAdapter{
 		options:   options,
 		dbName:    dbName,
 		tableName: tableName,
+		poolSize:  poolSize,
 		config:    defaultConfig,
-		metrics:   make(map[string]interface{}),
 	}
+	adapter.ResetMetrics()
 
 	return adapter, nil
 }
 
-// Initialize establishes a connection to the ImmuDB database and ensures the required table exists
+// newTLSConfig builds the client TLS configuration from the serverName, caCertPath,
+// clientCertPath and clientKeyPath config keys. The server is verified against the
+// system roots unless a CA certificate is given, and a client certificate enables mTLS.
+func newTLSConfig(config map[string]interface{}) (*tls.Config, error) {
+	serverName, _ := config["serverName"].(string)
+	caCertPath, _ := config["caCertPath"].(string)
+	clientCertPath, _ := config["clientCertPath"].(string)
+	clientKeyPath, _ := config["clientKeyPath"].(string)
+
+	tlsConfig := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caCertPath != "" {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in %s", caCertPath)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if clientCertPath != "" || clientKeyPath != "" {
+		if clientCertPath == "" || clientKeyPath == "" {
+			return nil, fmt.Errorf("mTLS requires both clientCertPath and clientKeyPath")
+		}
+		cert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Initialize opens the session pool and ensures the required table exists
 func (a *ImmuDBAdapter) Initialize(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	if a.connected {
 		return nil
 	}
 
-	// Create client
-	c := client.NewClient()
+	// Open one session per pooled client; an immudb session runs one transaction at a time
+	sessions := make([]client.ImmuClient, 0, a.poolSize)
+	for i := 0; i < a.poolSize; i++ {
+		c := client.NewClient().WithOptions(a.options)
 
-	// Connect to server with the right types for username and password ([]byte)
-	err := c.OpenSession(ctx, []byte(a.options.Username), []byte(a.options.Password), a.options.Database)
-	if err != nil {
-		return fmt.Errorf("failed to connect to ImmuDB: %w", err)
+		// Connect to server with the right types for username and password ([]byte)
+		err := c.OpenSession(ctx, []byte(a.options.Username), []byte(a.options.Password), a.dbName)
+		if err != nil {
+			closeSessions(sessions)
+			return fmt.Errorf("failed to connect to ImmuDB: %w", err)
+		}
+		sessions = append(sessions, c)
 	}
+	c := sessions[0]
 
-	a.client = c
-	a.connected = true
-
-	// Create the table if it doesn't exist
-	// Determine if table exists
+	// Create the table if it doesn't exist.
+	// The timestamp column stores nanoseconds since the Unix epoch. Tables created
+	// before this change hold seconds and must be dropped and recreated (or have
+	// their timestamps multiplied by 1e9) to be queried correctly.
 	sqlStmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+
 		"uuid VARCHAR[36] NOT NULL, "+
 		"account_id VARCHAR[36] NOT NULL, "+
@@ -114,10 +233,9 @@ func (a *ImmuDBAdapter) Initialize(ctx context.Context) error {
 		"PRIMARY KEY uuid"+
 		")", a.tableName)
 
-	_, err = c.SQLExec(ctx, sqlStmt, nil)
+	_, err := c.SQLExec(ctx, sqlStmt, nil)
 	if err != nil {
-		c.CloseSession(ctx)
-		a.connected = false
+		closeSessions(sessions)
 		return fmt.Errorf("failed to create table: %w", err)
 	}
 
@@ -135,107 +253,299 @@ func (a *ImmuDBAdapter) Initialize(ctx context.Context) error {
 		}
 	}
 
+	a.queries = newImmuDBQueries(a.tableName)
+	a.pool = make(chan client.ImmuClient, len(sessions))
+	for _, session := range sessions {
+		a.pool <- session
+	}
+	a.sessions = sessions
+	a.connected = true
+
 	return nil
 }
 
-// Close closes the ImmuDB connection
-func (db *ImmuDBAdapter) Close() error {
-	if db.connected && db.client != nil {
-		ctx := context.Background()
-		err := db.client.CloseSession(ctx)
-		if err == nil {
-			db.connected = false
+// closeSessions closes every session in the list, ignoring errors
+func closeSessions(sessions []client.ImmuClient) {
+	for _, session := range sessions {
+		session.CloseSession(context.Background())
+	}
+}
+
+// acquire connects if needed and takes an idle session from the pool, waiting until
+// one is returned or ctx is done. Callers must hand the session back with release.
+func (a *ImmuDBAdapter) acquire(ctx context.Context) (client.ImmuClient, error) {
+	if err := a.Initialize(ctx); err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	pool := a.pool
+	a.mu.Unlock()
+
+	select {
+	case c := <-pool:
+		return c, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for an ImmuDB session: %w", ctx.Err())
+	}
+}
+
+// release returns a session taken with acquire to the pool
+func (a *ImmuDBAdapter) release(c client.ImmuClient) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Sessions checked out before Close are already closed and must not be reused
+	for _, session := range a.sessions {
+		if session == c {
+			a.pool <- c
+			return
 		}
-		return err
 	}
-	return nil
 }
 
-// ReadTransaction retrieves a transaction by its UUID
-func (a *ImmuDBAdapter) ReadTransaction(ctx context.Context, accountID, uuid string, options *databases.ReadOptions) (*databases.Transaction, error) {
-	if !a.connected {
-		if err := a.Initialize(ctx); err != nil {
-			return nil, err
+// Close closes every session in the pool
+func (db *ImmuDBAdapter) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !db.connected {
+		return nil
+	}
+
+	var firstErr error
+	for _, session := range db.sessions {
+		if err := session.CloseSession(context.Background()); err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
+	db.sessions = nil
+	db.pool = nil
+	db.connected = false
+	return firstErr
+}
 
-	query := fmt.Sprintf("SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM %s WHERE uuid = ?", a.tableName)
+// CanReinitialize implements the databases.Reinitializable interface; Initialize opens
+// new sessions after Close has closed them
+func (a *ImmuDBAdapter) CanReinitialize() bool {
+	return true
+}
+
+// ReadTransaction retrieves a transaction by its UUID
+func (a *ImmuDBAdapter) ReadTransaction(ctx context.Context, accountID, uuid string, options *databases.ReadOptions) (_ *databases.Transaction, err error) {
+	defer a.recordOperation("read", time.Now(), &err)
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer a.release(c)
 
 	// Execute query
 	params := map[string]interface{}{
 		"uuid": uuid,
 	}
 
-	result, err := a.client.SQLQuery(ctx, query, params, true)
+	result, err := c.SQLQuery(ctx, a.queries.selectByUUID, params, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read transaction: %w", err)
 	}
 
 	if len(result.Rows) == 0 {
-		return nil, fmt.Errorf("transaction not found: %s", uuid)
+		return nil, fmt.Errorf("%w: %s", databases.ErrTransactionNotFound, uuid)
 	}
 
 	// Parse the result
 	row := result.Rows[0]
 
+	// Verify the row against the server-provided proof if requested
+	if options != nil && options.Verified {
+		if err := c.VerifyRow(ctx, row, a.tableName, primaryKeyValues(uuid)); err != nil {
+			return nil, fmt.Errorf("failed to verify transaction: %w", err)
+		}
+	}
+
 	// Extract values based on column order
 	transaction := &databases.Transaction{
 		UUID:            row.Values[0].GetS(),
 		AccountID:       row.Values[1].GetS(),
-		Timestamp:       time.Unix(row.Values[2].GetN(), 0),
+		Timestamp:       time.Unix(0, row.Values[2].GetN()),
 		Amount:          float64(row.Values[3].GetF()),
 		TransactionType: databases.TransactionType(row.Values[4].GetS()),
-		Metadata:        row.Values[5].GetS(),
+		Metadata:        decodeMetadata(row.Values[5].GetS()),
 	}
 
 	return transaction, nil
 }
 
 // WriteTransaction stores a transaction in the database
-func (a *ImmuDBAdapter) WriteTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) error {
-	if !a.connected {
-		if err := a.Initialize(ctx); err != nil {
-			return err
-		}
+func (a *ImmuDBAdapter) WriteTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) (err error) {
+	defer a.recordOperation("write", time.Now(), &err)
+
+	metadata, err := encodeMetadata(transaction.Metadata)
+	if err != nil {
+		return err
 	}
 
-	query := fmt.Sprintf(
-		"INSERT INTO %s (uuid, account_id, timestamp, amount, transaction_type, metadata) VALUES (?, ?, ?, ?, ?, ?)",
-		a.tableName,
-	)
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.release(c)
 
 	params := map[string]interface{}{
 		"uuid":             transaction.UUID,
 		"account_id":       transaction.AccountID,
-		"timestamp":        transaction.Timestamp.Unix(),
+		"timestamp":        transaction.Timestamp.UnixNano(),
 		"amount":           transaction.Amount,
 		"transaction_type": string(transaction.TransactionType),
-		"metadata":         transaction.Metadata,
+		"metadata":         metadata,
 	}
 
-	_, err := a.client.SQLExec(ctx, query, params)
+	if options != nil && options.Condition != "" {
+		err = a.execConditional(ctx, c, options.Condition, transaction.UUID, a.queries.insert, params)
+	} else {
+		_, err = c.SQLExec(ctx, a.queries.insert, params)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to write transaction: %w", err)
 	}
 
+	// Read the row back and verify it against the server-provided proof if requested
+	if options != nil && options.Verified {
+		if err := a.verifyStoredRow(ctx, c, transaction.UUID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// DeleteTransaction removes a transaction by its UUID
-func (a *ImmuDBAdapter) DeleteTransaction(ctx context.Context, accountID, uuid string) error {
-	if !a.connected {
-		if err := a.Initialize(ctx); err != nil {
-			return err
+// verifyStoredRow fetches the row for the given UUID through session c and verifies its
+// inclusion proof
+func (a *ImmuDBAdapter) verifyStoredRow(ctx context.Context, c client.ImmuClient, uuid string) error {
+	result, err := c.SQLQuery(ctx, a.queries.selectByUUID, map[string]interface{}{"uuid": uuid}, true)
+	if err != nil {
+		return fmt.Errorf("failed to read transaction for verification: %w", err)
+	}
+
+	if len(result.Rows) == 0 {
+		return fmt.Errorf("verification: %w: %s", databases.ErrTransactionNotFound, uuid)
+	}
+
+	if err := c.VerifyRow(ctx, result.Rows[0], a.tableName, primaryKeyValues(uuid)); err != nil {
+		return fmt.Errorf("failed to verify transaction: %w", err)
+	}
+
+	return nil
+}
+
+// primaryKeyValues builds the primary key values used by VerifyRow
+func primaryKeyValues(uuid string) []*schema.SQLValue {
+	return []*schema.SQLValue{
+		{Value: &schema.SQLValue_S{S: uuid}},
+	}
+}
+
+// UpdateTransaction modifies the amount and metadata of an existing transaction
+func (a *ImmuDBAdapter) UpdateTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) (err error) {
+	defer a.recordOperation("update", time.Now(), &err)
+
+	metadata, err := encodeMetadata(transaction.Metadata)
+	if err != nil {
+		return err
+	}
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.release(c)
+
+	params := map[string]interface{}{
+		"uuid":     transaction.UUID,
+		"amount":   transaction.Amount,
+		"metadata": metadata,
+	}
+
+	if options != nil && options.Condition != "" {
+		err = a.execConditional(ctx, c, options.Condition, transaction.UUID, a.queries.update, params)
+	} else {
+		_, err = c.SQLExec(ctx, a.queries.update, params)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	return nil
+}
+
+// existenceCondition matches the DynamoDB-style conditions ImmuDB supports. Every
+// column is always set, so an attribute exists exactly when the row does.
+var existenceCondition = regexp.MustCompile(`^\s*(attribute_exists|attribute_not_exists)\(\s*\w+\s*\)\s*$`)
+
+// execConditional runs query within a read-write transaction after checking that the
+// row for uuid exists, or doesn't, as condition requires. The check is part of the
+// transaction's read set, so when concurrent conditional writes to the same row race,
+// all but the first to commit fail with a read conflict, reported as a failed condition.
+func (a *ImmuDBAdapter) execConditional(ctx context.Context, c client.ImmuClient, condition, uuid, query string, params map[string]interface{}) error {
+	match := existenceCondition.FindStringSubmatch(condition)
+	if match == nil {
+		return fmt.Errorf("immudb condition %q: %w", condition, databases.ErrNotSupported)
+	}
+	mustExist := match[1] == "attribute_exists"
+
+	tx, err := c.NewTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	result, err := tx.SQLQuery(ctx, a.queries.selectByUUID, map[string]interface{}{"uuid": uuid})
+	if err != nil {
+		tx.Rollback(ctx)
+		return fmt.Errorf("failed to check condition: %w", err)
+	}
+	if exists := len(result.Rows) > 0; exists != mustExist {
+		tx.Rollback(ctx)
+		return fmt.Errorf("%w: %s", databases.ErrConditionFailed, condition)
+	}
+
+	if err := tx.SQLExec(ctx, query, params); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	if _, err := tx.Commit(ctx); err != nil {
+		// Errors arrive over gRPC, so they are matched by message
+		if msg := err.Error(); strings.Contains(msg, "tx read conflict") || strings.Contains(msg, "key already exists") {
+			return fmt.Errorf("%w: %w", databases.ErrConditionFailed, err)
 		}
+		return err
 	}
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE uuid = ?", a.tableName)
+	return nil
+}
+
+// DeleteTransaction removes a transaction by its UUID. A condition is checked in the
+// same transaction as the delete; ReturnOldItem is ignored.
+func (a *ImmuDBAdapter) DeleteTransaction(ctx context.Context, accountID, uuid string, options *databases.DeleteOptions) (err error) {
+	defer a.recordOperation("delete", time.Now(), &err)
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.release(c)
 
 	params := map[string]interface{}{
 		"uuid": uuid,
 	}
 
-	_, err := a.client.SQLExec(ctx, query, params)
+	if options != nil && options.Condition != "" {
+		err = a.execConditional(ctx, c, options.Condition, uuid, a.queries.delete, params)
+	} else {
+		_, err = c.SQLExec(ctx, a.queries.delete, params)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete transaction: %w", err)
 	}
@@ -244,20 +554,22 @@ func (a *ImmuDBAdapter) DeleteTransaction(ctx context.Context, accountID, uuid s
 }
 
 // QueryTransactionsByAccount retrieves all transactions for a specific account
-func (a *ImmuDBAdapter) QueryTransactionsByAccount(ctx context.Context, accountID string, options *databases.QueryOptions) ([]*databases.Transaction, error) {
-	if !a.connected {
-		if err := a.Initialize(ctx); err != nil {
-			return nil, err
-		}
+func (a *ImmuDBAdapter) QueryTransactionsByAccount(ctx context.Context, accountID string, options *databases.QueryOptions) (_ []*databases.Transaction, err error) {
+	defer a.recordOperation("query", time.Now(), &err)
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer a.release(c)
 
-	query := fmt.Sprintf("SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM %s WHERE account_id = ?", a.tableName)
+	query := fmt.Sprintf("SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM %s WHERE account_id = @account_id", a.tableName)
 
 	params := map[string]interface{}{
 		"account_id": accountID,
 	}
 
-	result, err := a.client.SQLQuery(ctx, query, params, true)
+	result, err := c.SQLQuery(ctx, query, params, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query transactions: %w", err)
 	}
@@ -268,10 +580,10 @@ func (a *ImmuDBAdapter) QueryTransactionsByAccount(ctx context.Context, accountI
 		transaction := &databases.Transaction{
 			UUID:            row.Values[0].GetS(),
 			AccountID:       row.Values[1].GetS(),
-			Timestamp:       time.Unix(row.Values[2].GetN(), 0),
+			Timestamp:       time.Unix(0, row.Values[2].GetN()),
 			Amount:          float64(row.Values[3].GetF()),
 			TransactionType: databases.TransactionType(row.Values[4].GetS()),
-			Metadata:        row.Values[5].GetS(),
+			Metadata:        decodeMetadata(row.Values[5].GetS()),
 		}
 
 		transactions = append(transactions, transaction)
@@ -280,23 +592,74 @@ func (a *ImmuDBAdapter) QueryTransactionsByAccount(ctx context.Context, accountI
 	return transactions, nil
 }
 
-// QueryTransactionsByTimeRange retrieves transactions within a specific time range
-func (a *ImmuDBAdapter) QueryTransactionsByTimeRange(ctx context.Context, accountID string, startTime, endTime time.Time, options *databases.QueryOptions) ([]*databases.Transaction, error) {
-	if !a.connected {
-		if err := a.Initialize(ctx); err != nil {
-			return nil, err
+// CountTransactionsByAccount counts the transactions of a specific account
+func (a *ImmuDBAdapter) CountTransactionsByAccount(ctx context.Context, accountID string) (_ int64, err error) {
+	defer a.recordOperation("query", time.Now(), &err)
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer a.release(c)
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE account_id = @account_id", a.tableName)
+
+	params := map[string]interface{}{
+		"account_id": accountID,
+	}
+
+	result, err := c.SQLQuery(ctx, query, params, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count transactions: %w", err)
+	}
+
+	if len(result.Rows) == 0 || len(result.Rows[0].Values) == 0 {
+		return 0, nil
+	}
+	return result.Rows[0].Values[0].GetN(), nil
+}
+
+// QueryTransactionsByAccountPaged retrieves one page of transactions for an account.
+// ImmuDB has no native cursor, so the continuation token is the row offset.
+func (a *ImmuDBAdapter) QueryTransactionsByAccountPaged(ctx context.Context, accountID string, options *databases.QueryOptions) (_ *databases.PagedTransactions, err error) {
+	defer a.recordOperation("query", time.Now(), &err)
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer a.release(c)
+
+	limit := int64(100)
+	if options != nil && options.Limit > 0 {
+		limit = options.Limit
+	}
+
+	offset := int64(0)
+	if options != nil && options.StartToken != "" {
+		parsed, err := strconv.ParseInt(options.StartToken, 10, 64)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid page token: %s", options.StartToken)
 		}
+		offset = parsed
 	}
 
-	query := fmt.Sprintf("SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM %s WHERE account_id = ? AND timestamp >= ? AND timestamp <= ?", a.tableName)
+	orderBy := "ASC"
+	if options != nil && !options.ScanIndexForward {
+		orderBy = "DESC"
+	}
+
+	// Order by the primary key so pages are stable between calls
+	query := fmt.Sprintf(
+		"SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM %s WHERE account_id = @account_id ORDER BY uuid %s LIMIT %d OFFSET %d",
+		a.tableName, orderBy, limit, offset,
+	)
 
 	params := map[string]interface{}{
-		"account_id":      accountID,
-		"start_timestamp": startTime.Unix(),
-		"end_timestamp":   endTime.Unix(),
+		"account_id": accountID,
 	}
 
-	result, err := a.client.SQLQuery(ctx, query, params, true)
+	result, err := c.SQLQuery(ctx, query, params, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query transactions: %w", err)
 	}
@@ -307,10 +670,60 @@ func (a *ImmuDBAdapter) QueryTransactionsByTimeRange(ctx context.Context, accoun
 		transaction := &databases.Transaction{
 			UUID:            row.Values[0].GetS(),
 			AccountID:       row.Values[1].GetS(),
-			Timestamp:       time.Unix(row.Values[2].GetN(), 0),
+			Timestamp:       time.Unix(0, row.Values[2].GetN()),
 			Amount:          float64(row.Values[3].GetF()),
 			TransactionType: databases.TransactionType(row.Values[4].GetS()),
-			Metadata:        row.Values[5].GetS(),
+			Metadata:        decodeMetadata(row.Values[5].GetS()),
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	page := &databases.PagedTransactions{
+		Transactions: transactions,
+	}
+
+	// A full page means there may be more rows to fetch
+	if int64(len(transactions)) == limit {
+		page.NextToken = strconv.FormatInt(offset+limit, 10)
+	}
+
+	return page, nil
+}
+
+// QueryTransactionsByTimeRange retrieves transactions within a specific time range
+func (a *ImmuDBAdapter) QueryTransactionsByTimeRange(ctx context.Context, accountID string, startTime, endTime time.Time, options *databases.QueryOptions) (_ []*databases.Transaction, err error) {
+	defer a.recordOperation("query", time.Now(), &err)
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer a.release(c)
+
+	query := fmt.Sprintf("SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM %s WHERE account_id = @account_id AND timestamp >= @start AND timestamp <= @end", a.tableName)
+
+	params := map[string]interface{}{
+		"account_id": accountID,
+		"start":      startTime.UnixNano(),
+		"end":        endTime.UnixNano(),
+	}
+
+	result, err := c.SQLQuery(ctx, query, params, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+
+	transactions := make([]*databases.Transaction, 0, len(result.Rows))
+
+	for _, row := range result.Rows {
+		transaction := &databases.Transaction{
+			UUID:            row.Values[0].GetS(),
+			AccountID:       row.Values[1].GetS(),
+			Timestamp:       time.Unix(0, row.Values[2].GetN()),
+			Amount:          float64(row.Values[3].GetF()),
+			TransactionType: databases.TransactionType(row.Values[4].GetS()),
+			Metadata:        decodeMetadata(row.Values[5].GetS()),
 		}
 
 		transactions = append(transactions, transaction)
@@ -319,13 +732,18 @@ func (a *ImmuDBAdapter) QueryTransactionsByTimeRange(ctx context.Context, accoun
 	return transactions, nil
 }
 
+// ScanTransactions implements the Database interface; ImmuDB does not support full-table scans
+func (db *ImmuDBAdapter) ScanTransactions(ctx context.Context, options *databases.ScanOptions) ([]*databases.Transaction, error) {
+	return nil, fmt.Errorf("ImmuDB scan: %w", databases.ErrNotSupported)
+}
+
+// ScanTransactionsPaged implements the Database interface; ImmuDB does not support full-table scans
+func (db *ImmuDBAdapter) ScanTransactionsPaged(ctx context.Context, options *databases.ScanOptions) (*databases.PagedTransactions, error) {
+	return nil, fmt.Errorf("ImmuDB scan: %w", databases.ErrNotSupported)
+}
+
 // BatchReadTransactions reads multiple transactions in a single operation
 func (db *ImmuDBAdapter) BatchReadTransactions(ctx context.Context, keys []struct{ AccountID, UUID string }, options *databases.BatchOptions) ([]*databases.Transaction, error) {
-	if !db.connected {
-		if err := db.Initialize(ctx); err != nil {
-			return nil, err
-		}
-	}
 
 	// For now, implement as sequential reads
 	transactions := make([]*databases.Transaction, 0, len(keys))
@@ -347,38 +765,39 @@ func (db *ImmuDBAdapter) BatchReadTransactions(ctx context.Context, keys []struc
 }
 
 // BatchWriteTransactions writes multiple transactions to the database
-func (a *ImmuDBAdapter) BatchWriteTransactions(ctx context.Context, transactions []*databases.Transaction, options *databases.BatchOptions) error {
-	if !a.connected {
-		if err := a.Initialize(ctx); err != nil {
-			return err
-		}
+func (a *ImmuDBAdapter) BatchWriteTransactions(ctx context.Context, transactions []*databases.Transaction, options *databases.BatchOptions) (err error) {
+	defer a.recordOperation("batchWrite", time.Now(), &err)
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return err
 	}
+	defer a.release(c)
 
 	// Start a transaction for batch insert
-	tx, err := a.client.NewTx(ctx)
+	tx, err := c.NewTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
 
-	// Set up the base query
-	query := fmt.Sprintf(
-		"INSERT INTO %s (uuid, account_id, timestamp, amount, transaction_type, metadata) VALUES (?, ?, ?, ?, ?, ?)",
-		a.tableName,
-	)
-
-	// Execute batch inserts
+	// Execute batch inserts, reusing the insert statement for every row
 	for _, transaction := range transactions {
+		metadata, err := encodeMetadata(transaction.Metadata)
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
 		params := map[string]interface{}{
 			"uuid":             transaction.UUID,
 			"account_id":       transaction.AccountID,
-			"timestamp":        transaction.Timestamp.Unix(),
+			"timestamp":        transaction.Timestamp.UnixNano(),
 			"amount":           transaction.Amount,
 			"transaction_type": string(transaction.TransactionType),
-			"metadata":         transaction.Metadata,
+			"metadata":         metadata,
 		}
 
 		// Fixed: SQLExec returns only one value
-		err = tx.SQLExec(ctx, query, params)
+		err = tx.SQLExec(ctx, a.queries.insert, params)
 		if err != nil {
 			tx.Rollback(ctx)
 			return fmt.Errorf("failed to insert transaction: %w", err)
@@ -397,22 +816,149 @@ func (a *ImmuDBAdapter) BatchWriteTransactions(ctx context.Context, transactions
 
 // ExecuteTransactWrite executes a transaction with multiple operations
 func (db *ImmuDBAdapter) ExecuteTransactWrite(ctx context.Context, transactions []*databases.Transaction) error {
-	if !db.connected {
-		if err := db.Initialize(ctx); err != nil {
-			return err
-		}
-	}
 
 	// For ImmuDB, we can use the BatchWriteTransactions since it already uses transactions
 	return db.BatchWriteTransactions(ctx, transactions, &databases.BatchOptions{})
 }
 
+// readOnlyTx starts an immudb transaction in read-only mode
+func readOnlyTx() client.TxOption {
+	return func(req *schema.NewTxRequest) error {
+		req.Mode = schema.TxMode_ReadOnly
+		return nil
+	}
+}
+
+// ExecuteTransactRead reads all keys within a single read-only transaction so they
+// come from the same snapshot. Keys that do not exist are omitted from the result.
+func (a *ImmuDBAdapter) ExecuteTransactRead(ctx context.Context, keys []struct{ AccountID, UUID string }) (_ []*databases.Transaction, err error) {
+	defer a.recordOperation("transactRead", time.Now(), &err)
+
+	c, err := a.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer a.release(c)
+
+	if len(keys) == 0 {
+		return []*databases.Transaction{}, nil
+	}
+
+	tx, err := c.NewTx(ctx, readOnlyTx())
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	// Read-only transactions have nothing to commit
+	defer tx.Rollback(ctx)
+
+	transactions := make([]*databases.Transaction, 0, len(keys))
+	for _, key := range keys {
+		result, err := tx.SQLQuery(ctx, a.queries.selectByUUID, map[string]interface{}{"uuid": key.UUID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transaction %s: %w", key.UUID, err)
+		}
+		if len(result.Rows) == 0 {
+			continue
+		}
+
+		row := result.Rows[0]
+		transactions = append(transactions, &databases.Transaction{
+			UUID:            row.Values[0].GetS(),
+			AccountID:       row.Values[1].GetS(),
+			Timestamp:       time.Unix(0, row.Values[2].GetN()),
+			Amount:          float64(row.Values[3].GetF()),
+			TransactionType: databases.TransactionType(row.Values[4].GetS()),
+			Metadata:        decodeMetadata(row.Values[5].GetS()),
+		})
+	}
+
+	return transactions, nil
+}
+
+// operationMetricKeys maps an operation kind to its counter and average latency metric names
+var operationMetricKeys = map[string]struct{ count, latency string }{
+	"read":         {"readOperations", "averageReadLatency"},
+	"write":        {"writeOperations", "averageWriteLatency"},
+	"update":       {"updateOperations", "averageUpdateLatency"},
+	"delete":       {"deleteOperations", "averageDeleteLatency"},
+	"query":        {"queryOperations", "averageQueryLatency"},
+	"batchWrite":   {"batchWriteOperations", "averageBatchWriteLatency"},
+	"transactRead": {"transactReadOperations", "averageTransactReadLatency"},
+}
+
+// recordOperation updates the operation counters and running average latency.
+// It is deferred by each CRUD method with a pointer to the method's error result.
+func (a *ImmuDBAdapter) recordOperation(kind string, start time.Time, err *error) {
+	elapsed := time.Since(start)
+	keys := operationMetricKeys[kind]
+
+	a.metricsMu.Lock()
+	defer a.metricsMu.Unlock()
+
+	count := a.metrics[keys.count].(int) + 1
+	a.metrics[keys.count] = count
+	a.metrics["totalOperations"] = a.metrics["totalOperations"].(int) + 1
+	if err != nil && *err != nil {
+		a.metrics["failedOperations"] = a.metrics["failedOperations"].(int) + 1
+	}
+
+	a.latencies[kind] += elapsed
+	a.metrics[keys.latency] = a.latencies[kind] / time.Duration(count)
+}
+
 // GetMetrics returns metrics collected by the adapter
 func (db *ImmuDBAdapter) GetMetrics() map[string]interface{} {
-	return db.metrics
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
+	// Return a copy to avoid race conditions
+	metrics := make(map[string]interface{}, len(db.metrics))
+	for k, v := range db.metrics {
+		metrics[k] = v
+	}
+	return metrics
 }
 
 // ResetMetrics resets all metrics
 func (db *ImmuDBAdapter) ResetMetrics() {
-	db.metrics = make(map[string]interface{})
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
+	db.metrics = map[string]interface{}{
+		"failedOperations": 0,
+		"totalOperations":  0,
+	}
+	for _, keys := range operationMetricKeys {
+		db.metrics[keys.count] = 0
+		db.metrics[keys.latency] = time.Duration(0)
+	}
+	db.latencies = make(map[string]time.Duration)
+}
+
+// encodeMetadata serializes transaction metadata as JSON for the VARCHAR metadata
+// column, since immudb only binds scalar SQL parameters. Byte slices are encoded as
+// base64 strings, matching encoding/json.
+func encodeMetadata(metadata interface{}) (string, error) {
+	switch v := metadata.(type) {
+	case nil:
+		return "null", nil
+	case json.RawMessage:
+		return string(v), nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeMetadata parses JSON metadata written by encodeMetadata. Values that
+// are not valid JSON (e.g. written by older versions) are returned as-is.
+func decodeMetadata(value string) interface{} {
+	var metadata interface{}
+	if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+		return value
+	}
+	return metadata
 }
@@ -0,0 +1,95 @@
+//go:build integration
+
+package immudb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// newTestDatabase connects to the ImmuDB server at IMMUDB_ADDRESS (default: 127.0.0.1)
+// with a table unique to the test. The test is skipped when no server is reachable.
+func newTestDatabase(t *testing.T) *ImmuDBAdapter {
+	t.Helper()
+
+	address := os.Getenv("IMMUDB_ADDRESS")
+	if address == "" {
+		address = "127.0.0.1"
+	}
+	tableName := fmt.Sprintf("test_%d", time.Now().UnixNano())
+
+	db, err := NewImmuDBFactory().CreateDatabase(map[string]interface{}{"address": address, "tableName": tableName})
+	if err != nil {
+		t.Fatalf("CreateDatabase() error = %v", err)
+	}
+	ctx := context.Background()
+	if err := db.Initialize(ctx); err != nil {
+		t.Skipf("ImmuDB is not reachable at %s: %v", address, err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db.(*ImmuDBAdapter)
+}
+
+// TestPrebuiltQueries runs every statement built by newImmuDBQueries against the server
+func TestPrebuiltQueries(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	transaction := &databases.Transaction{
+		AccountID:       "account-1",
+		UUID:            "tx-1",
+		Timestamp:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Amount:          42.5,
+		TransactionType: databases.Deposit,
+	}
+	if err := db.WriteTransaction(ctx, transaction, nil); err != nil {
+		t.Fatalf("WriteTransaction() error = %v", err)
+	}
+
+	transaction.Amount = 50
+	if err := db.UpdateTransaction(ctx, transaction, nil); err != nil {
+		t.Fatalf("UpdateTransaction() error = %v", err)
+	}
+	got, err := db.ReadTransaction(ctx, transaction.AccountID, transaction.UUID, nil)
+	if err != nil {
+		t.Fatalf("ReadTransaction() error = %v", err)
+	}
+	if got.AccountID != transaction.AccountID || got.Amount != transaction.Amount ||
+		got.TransactionType != transaction.TransactionType || !got.Timestamp.Equal(transaction.Timestamp) {
+		t.Errorf("ReadTransaction() = %+v, want %+v", got, transaction)
+	}
+
+	// Batch writes reuse the insert statement within one transaction
+	batch := make([]*databases.Transaction, 5)
+	for i := range batch {
+		batch[i] = &databases.Transaction{
+			AccountID:       "account-1",
+			UUID:            fmt.Sprintf("tx-batch-%d", i),
+			Timestamp:       transaction.Timestamp.Add(time.Duration(i) * time.Minute),
+			Amount:          float64(i),
+			TransactionType: databases.Withdrawal,
+		}
+	}
+	if err := db.BatchWriteTransactions(ctx, batch, nil); err != nil {
+		t.Fatalf("BatchWriteTransactions() error = %v", err)
+	}
+	for _, want := range batch {
+		if _, err := db.ReadTransaction(ctx, want.AccountID, want.UUID, nil); err != nil {
+			t.Errorf("ReadTransaction(%s) error = %v", want.UUID, err)
+		}
+	}
+
+	if err := db.DeleteTransaction(ctx, transaction.AccountID, transaction.UUID, nil); err != nil {
+		t.Fatalf("DeleteTransaction() error = %v", err)
+	}
+	if _, err := db.ReadTransaction(ctx, transaction.AccountID, transaction.UUID, nil); !errors.Is(err, databases.ErrTransactionNotFound) {
+		t.Errorf("ReadTransaction() after delete error = %v, want ErrTransactionNotFound", err)
+	}
+}
@@ -0,0 +1,55 @@
+package immudb
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewImmuDBQueries(t *testing.T) {
+	got := newImmuDBQueries("ledger")
+	want := immuDBQueries{
+		selectByUUID: "SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM ledger WHERE uuid = @uuid",
+		insert:       "INSERT INTO ledger (uuid, account_id, timestamp, amount, transaction_type, metadata) VALUES (@uuid, @account_id, @timestamp, @amount, @transaction_type, @metadata)",
+		update:       "UPDATE ledger SET amount = @amount, metadata = @metadata WHERE uuid = @uuid",
+		delete:       "DELETE FROM ledger WHERE uuid = @uuid",
+	}
+	if got != want {
+		t.Errorf("newImmuDBQueries() = %+v, want %+v", got, want)
+	}
+}
+
+// querySink keeps the compiler from optimizing away the statements built by the benchmarks
+var querySink string
+
+// BenchmarkQueries compares formatting each statement on every call, as the hot paths
+// used to, with reading the statements built once by Initialize
+func BenchmarkQueries(b *testing.B) {
+	const tableName = "transactions"
+	queries := newImmuDBQueries(tableName)
+
+	statements := []struct {
+		name     string
+		format   string
+		prebuilt string
+	}{
+		{"select", "SELECT uuid, account_id, timestamp, amount, transaction_type, metadata FROM %s WHERE uuid = @uuid", queries.selectByUUID},
+		{"insert", "INSERT INTO %s (uuid, account_id, timestamp, amount, transaction_type, metadata) VALUES (@uuid, @account_id, @timestamp, @amount, @transaction_type, @metadata)", queries.insert},
+		{"update", "UPDATE %s SET amount = @amount, metadata = @metadata WHERE uuid = @uuid", queries.update},
+		{"delete", "DELETE FROM %s WHERE uuid = @uuid", queries.delete},
+	}
+
+	for _, statement := range statements {
+		b.Run(statement.name+"/formatted", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				querySink = fmt.Sprintf(statement.format, tableName)
+			}
+		})
+		b.Run(statement.name+"/prebuilt", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				querySink = statement.prebuilt
+			}
+		})
+	}
+}
@@ -0,0 +1,629 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// RedisDatabase implements the Database interface for Redis as a key-value baseline.
+// Each transaction is stored as a JSON value under tx:{accountID}:{uuid}, and a
+// per-account sorted set scored by timestamp supports account and time-range queries.
+type RedisDatabase struct {
+	client      *goredis.Client
+	keyPrefix   string
+	metrics     map[string]interface{}
+	metricsMu   sync.Mutex
+	initialized bool
+}
+
+// RedisConfig holds the configuration for a Redis database
+type RedisConfig struct {
+	Address   string
+	Password  string
+	DB        int
+	KeyPrefix string
+}
+
+// RedisFactory creates Redis database instances
+type RedisFactory struct{}
+
+func init() {
+	databases.RegisterFactory("redis", NewRedisFactory())
+}
+
+// NewRedisFactory creates a new Redis factory
+func NewRedisFactory() *RedisFactory {
+	return &RedisFactory{}
+}
+
+// CreateDatabase implements the DatabaseFactory interface
+func (f *RedisFactory) CreateDatabase(config map[string]interface{}) (databases.Database, error) {
+	// Extract configuration
+	dbConfig := RedisConfig{
+		Address: "localhost:6379", // Default address
+		DB:      0,
+	}
+
+	if address, ok := config["address"].(string); ok {
+		dbConfig.Address = address
+	}
+	if endpoint, ok := config["endpoint"].(string); ok && endpoint != "" {
+		dbConfig.Address = endpoint
+	}
+	if password, ok := config["password"].(string); ok {
+		dbConfig.Password = password
+	}
+	switch v := config["db"].(type) {
+	case int:
+		dbConfig.DB = v
+	case float64:
+		dbConfig.DB = int(v)
+	}
+	if keyPrefix, ok := config["keyPrefix"].(string); ok {
+		dbConfig.KeyPrefix = keyPrefix
+	}
+
+	return NewRedisDatabase(dbConfig)
+}
+
+// NewRedisDatabase creates a new Redis database instance
+func NewRedisDatabase(config RedisConfig) (*RedisDatabase, error) {
+	db := &RedisDatabase{
+		client: goredis.NewClient(&goredis.Options{
+			Addr:     config.Address,
+			Password: config.Password,
+			DB:       config.DB,
+		}),
+		keyPrefix:   config.KeyPrefix,
+		initialized: false,
+	}
+	db.ResetMetrics()
+
+	return db, nil
+}
+
+// Initialize implements the Database interface
+func (db *RedisDatabase) Initialize(ctx context.Context) error {
+	if db.initialized {
+		return nil
+	}
+
+	// Check that the server is reachable
+	if err := db.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	db.initialized = true
+	return nil
+}
+
+// Close implements the Database interface
+func (db *RedisDatabase) Close() error {
+	db.initialized = false
+	return db.client.Close()
+}
+
+// ReadTransaction implements the Database interface
+func (db *RedisDatabase) ReadTransaction(ctx context.Context, accountID, uuid string, options *databases.ReadOptions) (_ *databases.Transaction, err error) {
+	defer db.recordOperation("readOperations", &err)
+
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	return db.getTransaction(ctx, accountID, uuid)
+}
+
+// WriteTransaction implements the Database interface. Conditions are not supported,
+// so a write with a condition returns databases.ErrNotSupported.
+func (db *RedisDatabase) WriteTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) (err error) {
+	defer db.recordOperation("writeOperations", &err)
+
+	if !db.initialized {
+		return errors.New("database not initialized")
+	}
+
+	if transaction == nil {
+		return errors.New("transaction cannot be nil")
+	}
+
+	if options != nil && options.Condition != "" {
+		return fmt.Errorf("Redis conditional write: %w", databases.ErrNotSupported)
+	}
+
+	// Store the value and index entry atomically
+	_, err = db.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		return db.queueWrite(ctx, pipe, transaction)
+	})
+	if err != nil {
+		return fmt.Errorf("write operation failed: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTransaction implements the Database interface. As with writes, an update with
+// a condition returns databases.ErrNotSupported.
+func (db *RedisDatabase) UpdateTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) (err error) {
+	defer db.recordOperation("writeOperations", &err)
+
+	if !db.initialized {
+		return errors.New("database not initialized")
+	}
+
+	if transaction == nil {
+		return errors.New("transaction cannot be nil")
+	}
+
+	if options != nil && options.Condition != "" {
+		return fmt.Errorf("Redis conditional update: %w", databases.ErrNotSupported)
+	}
+
+	// Only the amount and metadata are updated, matching the other adapters
+	existing, err := db.getTransaction(ctx, transaction.AccountID, transaction.UUID)
+	if err != nil {
+		return err
+	}
+	existing.Amount = transaction.Amount
+	existing.Metadata = transaction.Metadata
+
+	value, err := json.Marshal(existing)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+
+	err = db.client.Set(ctx, db.transactionKey(existing.AccountID, existing.UUID), value, 0).Err()
+	if err != nil {
+		return fmt.Errorf("SET operation failed: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTransaction implements the Database interface. Like writes, deletes do not
+// support conditions or returning the old item, so a delete with either returns
+// databases.ErrNotSupported.
+func (db *RedisDatabase) DeleteTransaction(ctx context.Context, accountID, uuid string, options *databases.DeleteOptions) (err error) {
+	defer db.recordOperation("writeOperations", &err)
+
+	if !db.initialized {
+		return errors.New("database not initialized")
+	}
+
+	if options != nil && (options.Condition != "" || options.ReturnOldItem) {
+		return fmt.Errorf("Redis conditional delete: %w", databases.ErrNotSupported)
+	}
+
+	_, err = db.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, db.transactionKey(accountID, uuid))
+		pipe.ZRem(ctx, db.accountIndexKey(accountID), uuid)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("delete operation failed: %w", err)
+	}
+
+	return nil
+}
+
+// QueryTransactionsByAccount implements the Database interface
+func (db *RedisDatabase) QueryTransactionsByAccount(ctx context.Context, accountID string, options *databases.QueryOptions) (_ []*databases.Transaction, err error) {
+	defer db.recordOperation("queryOperations", &err)
+
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	limit := int64(100)
+	reverse := false
+	if options != nil {
+		if options.Limit > 0 {
+			limit = options.Limit
+		}
+		reverse = !options.ScanIndexForward
+	}
+
+	uuids, err := db.client.ZRangeArgs(ctx, goredis.ZRangeArgs{
+		Key:   db.accountIndexKey(accountID),
+		Start: 0,
+		Stop:  limit - 1,
+		Rev:   reverse,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("ZRANGE operation failed: %w", err)
+	}
+
+	return db.fetchTransactions(ctx, accountID, uuids)
+}
+
+// CountTransactionsByAccount implements the Database interface using the size of the
+// account's sorted set
+func (db *RedisDatabase) CountTransactionsByAccount(ctx context.Context, accountID string) (_ int64, err error) {
+	defer db.recordOperation("queryOperations", &err)
+
+	if !db.initialized {
+		return 0, errors.New("database not initialized")
+	}
+
+	count, err := db.client.ZCard(ctx, db.accountIndexKey(accountID)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("ZCARD operation failed: %w", err)
+	}
+	return count, nil
+}
+
+// QueryTransactionsByAccountPaged implements the Database interface.
+// The continuation token is the offset into the account's sorted set.
+func (db *RedisDatabase) QueryTransactionsByAccountPaged(ctx context.Context, accountID string, options *databases.QueryOptions) (_ *databases.PagedTransactions, err error) {
+	defer db.recordOperation("queryOperations", &err)
+
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	limit := int64(100)
+	reverse := false
+	offset := int64(0)
+	if options != nil {
+		if options.Limit > 0 {
+			limit = options.Limit
+		}
+		reverse = !options.ScanIndexForward
+		if options.StartToken != "" {
+			parsed, err := strconv.ParseInt(options.StartToken, 10, 64)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("invalid page token: %s", options.StartToken)
+			}
+			offset = parsed
+		}
+	}
+
+	uuids, err := db.client.ZRangeArgs(ctx, goredis.ZRangeArgs{
+		Key:   db.accountIndexKey(accountID),
+		Start: offset,
+		Stop:  offset + limit - 1,
+		Rev:   reverse,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("ZRANGE operation failed: %w", err)
+	}
+
+	transactions, err := db.fetchTransactions(ctx, accountID, uuids)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &databases.PagedTransactions{
+		Transactions: transactions,
+	}
+
+	// A full page means there may be more entries to fetch
+	if int64(len(uuids)) == limit {
+		page.NextToken = strconv.FormatInt(offset+limit, 10)
+	}
+
+	return page, nil
+}
+
+// QueryTransactionsByTimeRange implements the Database interface
+func (db *RedisDatabase) QueryTransactionsByTimeRange(ctx context.Context, accountID string, startTime, endTime time.Time, options *databases.QueryOptions) (_ []*databases.Transaction, err error) {
+	defer db.recordOperation("queryOperations", &err)
+
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	limit := int64(100)
+	reverse := false
+	if options != nil {
+		if options.Limit > 0 {
+			limit = options.Limit
+		}
+		reverse = !options.ScanIndexForward
+	}
+
+	// With Rev set, Redis expects the range bounds in descending order
+	minScore := strconv.FormatInt(timestampScore(startTime), 10)
+	maxScore := strconv.FormatInt(timestampScore(endTime), 10)
+	start, stop := minScore, maxScore
+	if reverse {
+		start, stop = maxScore, minScore
+	}
+
+	uuids, err := db.client.ZRangeArgs(ctx, goredis.ZRangeArgs{
+		Key:     db.accountIndexKey(accountID),
+		Start:   start,
+		Stop:    stop,
+		ByScore: true,
+		Rev:     reverse,
+		Count:   limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("ZRANGEBYSCORE operation failed: %w", err)
+	}
+
+	return db.fetchTransactions(ctx, accountID, uuids)
+}
+
+// ScanTransactions implements the Database interface; Redis does not support full-table scans
+func (db *RedisDatabase) ScanTransactions(ctx context.Context, options *databases.ScanOptions) ([]*databases.Transaction, error) {
+	return nil, fmt.Errorf("Redis scan: %w", databases.ErrNotSupported)
+}
+
+// ScanTransactionsPaged implements the Database interface; Redis does not support full-table scans
+func (db *RedisDatabase) ScanTransactionsPaged(ctx context.Context, options *databases.ScanOptions) (*databases.PagedTransactions, error) {
+	return nil, fmt.Errorf("Redis scan: %w", databases.ErrNotSupported)
+}
+
+// BatchReadTransactions implements the Database interface
+func (db *RedisDatabase) BatchReadTransactions(ctx context.Context, keys []struct{ AccountID, UUID string }, options *databases.BatchOptions) (_ []*databases.Transaction, err error) {
+	defer db.recordOperation("batchReadOperations", &err)
+
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	if len(keys) == 0 {
+		return []*databases.Transaction{}, nil
+	}
+
+	// Issue all GETs in a single pipeline round trip
+	cmds := make([]*goredis.StringCmd, len(keys))
+	_, err = db.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, db.transactionKey(key.AccountID, key.UUID))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, fmt.Errorf("pipelined GET failed: %w", err)
+	}
+
+	transactions := make([]*databases.Transaction, 0, len(keys))
+	for _, cmd := range cmds {
+		value, err := cmd.Bytes()
+		if err != nil {
+			continue // Skip keys that were not found
+		}
+
+		var transaction databases.Transaction
+		if err := json.Unmarshal(value, &transaction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+		}
+		transactions = append(transactions, &transaction)
+	}
+
+	return transactions, nil
+}
+
+// BatchWriteTransactions implements the Database interface
+func (db *RedisDatabase) BatchWriteTransactions(ctx context.Context, transactions []*databases.Transaction, options *databases.BatchOptions) (err error) {
+	defer db.recordOperation("batchWriteOperations", &err)
+
+	if !db.initialized {
+		return errors.New("database not initialized")
+	}
+
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	// Pipeline all writes without MULTI/EXEC; there are no atomicity guarantees
+	_, err = db.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, transaction := range transactions {
+			if err := db.queueWrite(ctx, pipe, transaction); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("pipelined write failed: %w", err)
+	}
+
+	return nil
+}
+
+// ExecuteTransactWrite implements the Database interface
+func (db *RedisDatabase) ExecuteTransactWrite(ctx context.Context, transactions []*databases.Transaction) (err error) {
+	defer db.recordOperation("batchWriteOperations", &err)
+
+	if !db.initialized {
+		return errors.New("database not initialized")
+	}
+
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	// MULTI/EXEC applies all writes atomically
+	_, err = db.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, transaction := range transactions {
+			if err := db.queueWrite(ctx, pipe, transaction); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("MULTI/EXEC operation failed: %w", err)
+	}
+
+	return nil
+}
+
+// ExecuteTransactRead implements the Database interface
+func (db *RedisDatabase) ExecuteTransactRead(ctx context.Context, keys []struct{ AccountID, UUID string }) (_ []*databases.Transaction, err error) {
+	defer db.recordOperation("batchReadOperations", &err)
+
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	if len(keys) == 0 {
+		return []*databases.Transaction{}, nil
+	}
+
+	// MULTI/EXEC returns all values from a single point in time
+	cmds := make([]*goredis.StringCmd, len(keys))
+	_, err = db.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, db.transactionKey(key.AccountID, key.UUID))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, fmt.Errorf("MULTI/EXEC operation failed: %w", err)
+	}
+
+	transactions := make([]*databases.Transaction, 0, len(keys))
+	for _, cmd := range cmds {
+		value, err := cmd.Bytes()
+		if err != nil {
+			continue // Skip keys that were not found
+		}
+
+		var transaction databases.Transaction
+		if err := json.Unmarshal(value, &transaction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+		}
+		transactions = append(transactions, &transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetMetrics implements the Database interface
+func (db *RedisDatabase) GetMetrics() map[string]interface{} {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
+	// Return a copy to avoid race conditions
+	metrics := make(map[string]interface{})
+	for k, v := range db.metrics {
+		metrics[k] = v
+	}
+	return metrics
+}
+
+// ResetMetrics implements the Database interface
+func (db *RedisDatabase) ResetMetrics() {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
+	db.metrics = map[string]interface{}{
+		"readOperations":       0,
+		"writeOperations":      0,
+		"queryOperations":      0,
+		"batchReadOperations":  0,
+		"batchWriteOperations": 0,
+		"failedOperations":     0,
+		"totalOperations":      0,
+	}
+}
+
+// Helper methods
+
+// recordOperation counts a call in the given operation counter and totalOperations,
+// and failed calls in failedOperations. It is deferred by each data method with a
+// pointer to the method's error result.
+func (db *RedisDatabase) recordOperation(metric string, err *error) {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
+	db.metrics[metric] = db.metrics[metric].(int) + 1
+	db.metrics["totalOperations"] = db.metrics["totalOperations"].(int) + 1
+	if *err != nil {
+		db.metrics["failedOperations"] = db.metrics["failedOperations"].(int) + 1
+	}
+}
+
+// getTransaction loads a single transaction with GET
+func (db *RedisDatabase) getTransaction(ctx context.Context, accountID, uuid string) (*databases.Transaction, error) {
+	value, err := db.client.Get(ctx, db.transactionKey(accountID, uuid)).Bytes()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return nil, fmt.Errorf("%w: %s", databases.ErrTransactionNotFound, uuid)
+		}
+		return nil, fmt.Errorf("GET operation failed: %w", err)
+	}
+
+	var transaction databases.Transaction
+	if err := json.Unmarshal(value, &transaction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+	}
+
+	return &transaction, nil
+}
+
+// queueWrite adds the SET and sorted set index commands for a transaction to a pipeline
+func (db *RedisDatabase) queueWrite(ctx context.Context, pipe goredis.Pipeliner, transaction *databases.Transaction) error {
+	value, err := json.Marshal(transaction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+
+	pipe.Set(ctx, db.transactionKey(transaction.AccountID, transaction.UUID), value, 0)
+	pipe.ZAdd(ctx, db.accountIndexKey(transaction.AccountID), goredis.Z{
+		Score:  float64(timestampScore(transaction.Timestamp)),
+		Member: transaction.UUID,
+	})
+	return nil
+}
+
+// fetchTransactions loads the transactions for the given UUIDs with a single MGET
+func (db *RedisDatabase) fetchTransactions(ctx context.Context, accountID string, uuids []string) ([]*databases.Transaction, error) {
+	if len(uuids) == 0 {
+		return []*databases.Transaction{}, nil
+	}
+
+	keys := make([]string, len(uuids))
+	for i, uuid := range uuids {
+		keys[i] = db.transactionKey(accountID, uuid)
+	}
+
+	values, err := db.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("MGET operation failed: %w", err)
+	}
+
+	transactions := make([]*databases.Transaction, 0, len(values))
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue // Index entry without a value
+		}
+
+		var transaction databases.Transaction
+		if err := json.Unmarshal([]byte(str), &transaction); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal transaction: %w", err)
+		}
+		transactions = append(transactions, &transaction)
+	}
+
+	return transactions, nil
+}
+
+// transactionKey returns the key holding a single transaction
+func (db *RedisDatabase) transactionKey(accountID, uuid string) string {
+	return fmt.Sprintf("%stx:%s:%s", db.keyPrefix, accountID, uuid)
+}
+
+// accountIndexKey returns the sorted set indexing an account's transactions by timestamp
+func (db *RedisDatabase) accountIndexKey(accountID string) string {
+	return fmt.Sprintf("%saccount:%s:transactions", db.keyPrefix, accountID)
+}
+
+// timestampScore converts a timestamp to a sorted set score. Microseconds are
+// used because they fit exactly in a float64, unlike nanoseconds.
+func timestampScore(t time.Time) int64 {
+	return t.UnixMicro()
+}
@@ -0,0 +1,231 @@
+//go:build integration
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
+)
+
+// newTestDatabase connects to the Redis server at REDIS_ADDRESS (default:
+// localhost:6379) under a key prefix unique to the test, and removes the test's keys
+// when it finishes. The test is skipped when no server is reachable.
+func newTestDatabase(t *testing.T) *RedisDatabase {
+	t.Helper()
+
+	address := os.Getenv("REDIS_ADDRESS")
+	if address == "" {
+		address = "localhost:6379"
+	}
+	prefix := fmt.Sprintf("test-%s-%d:", t.Name(), time.Now().UnixNano())
+
+	db, err := NewRedisDatabase(RedisConfig{Address: address, KeyPrefix: prefix})
+	if err != nil {
+		t.Fatalf("NewRedisDatabase() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := db.Initialize(ctx); err != nil {
+		t.Skipf("Redis is not reachable at %s: %v", address, err)
+	}
+
+	t.Cleanup(func() {
+		keys, err := db.client.Keys(ctx, prefix+"*").Result()
+		if err == nil && len(keys) > 0 {
+			db.client.Del(ctx, keys...)
+		}
+		db.Close()
+	})
+
+	return db
+}
+
+// testTransaction returns a transaction for the account with a timestamp offset
+// by the given number of minutes from base
+func testTransaction(accountID string, i int, base time.Time) *databases.Transaction {
+	return &databases.Transaction{
+		AccountID:       accountID,
+		UUID:            fmt.Sprintf("tx-%d", i),
+		Timestamp:       base.Add(time.Duration(i) * time.Minute),
+		Amount:          float64(i) + 0.5,
+		TransactionType: databases.Deposit,
+		Metadata:        map[string]interface{}{"index": float64(i)},
+	}
+}
+
+func TestReadWriteTransaction(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	want := testTransaction("account-1", 1, base)
+	if err := db.WriteTransaction(ctx, want, nil); err != nil {
+		t.Fatalf("WriteTransaction() error = %v", err)
+	}
+
+	got, err := db.ReadTransaction(ctx, want.AccountID, want.UUID, nil)
+	if err != nil {
+		t.Fatalf("ReadTransaction() error = %v", err)
+	}
+	if got.UUID != want.UUID || got.AccountID != want.AccountID || got.Amount != want.Amount ||
+		got.TransactionType != want.TransactionType || !got.Timestamp.Equal(want.Timestamp) {
+		t.Errorf("ReadTransaction() = %+v, want %+v", got, want)
+	}
+
+	if _, err := db.ReadTransaction(ctx, want.AccountID, "tx-missing", nil); !errors.Is(err, databases.ErrTransactionNotFound) {
+		t.Errorf("ReadTransaction() of a missing key error = %v, want ErrTransactionNotFound", err)
+	}
+}
+
+func TestConditionalOperationsNotSupported(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	transaction := testTransaction("account-1", 1, time.Now())
+	options := &databases.WriteOptions{Condition: "attribute_not_exists(uuid)"}
+
+	if err := db.WriteTransaction(ctx, transaction, options); !databases.IsUnsupportedOperation(err) {
+		t.Errorf("WriteTransaction() with a condition error = %v, want ErrNotSupported", err)
+	}
+	if err := db.UpdateTransaction(ctx, transaction, options); !databases.IsUnsupportedOperation(err) {
+		t.Errorf("UpdateTransaction() with a condition error = %v, want ErrNotSupported", err)
+	}
+	if _, err := db.ReadTransaction(ctx, transaction.AccountID, transaction.UUID, nil); !errors.Is(err, databases.ErrTransactionNotFound) {
+		t.Errorf("ReadTransaction() after a rejected write error = %v, want ErrTransactionNotFound", err)
+	}
+
+	if err := db.WriteTransaction(ctx, transaction, nil); err != nil {
+		t.Fatalf("WriteTransaction() error = %v", err)
+	}
+	deleteOptions := []*databases.DeleteOptions{
+		{Condition: "attribute_exists(uuid)"},
+		{ReturnOldItem: true},
+	}
+	for _, options := range deleteOptions {
+		if err := db.DeleteTransaction(ctx, transaction.AccountID, transaction.UUID, options); !databases.IsUnsupportedOperation(err) {
+			t.Errorf("DeleteTransaction(%+v) error = %v, want ErrNotSupported", options, err)
+		}
+	}
+	if _, err := db.ReadTransaction(ctx, transaction.AccountID, transaction.UUID, nil); err != nil {
+		t.Errorf("ReadTransaction() after rejected deletes error = %v", err)
+	}
+}
+
+func TestQueryTransactionsByTimeRange(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 5; i++ {
+		if err := db.WriteTransaction(ctx, testTransaction("account-1", i, base), nil); err != nil {
+			t.Fatalf("WriteTransaction() error = %v", err)
+		}
+	}
+	// Another account's transactions in the same range must not be returned
+	if err := db.WriteTransaction(ctx, testTransaction("account-2", 2, base), nil); err != nil {
+		t.Fatalf("WriteTransaction() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		options *databases.QueryOptions
+		want    []string
+	}{
+		{"ascending", &databases.QueryOptions{ScanIndexForward: true}, []string{"tx-1", "tx-2", "tx-3"}},
+		{"descending", &databases.QueryOptions{ScanIndexForward: false}, []string{"tx-3", "tx-2", "tx-1"}},
+		{"limit", &databases.QueryOptions{ScanIndexForward: true, Limit: 2}, []string{"tx-1", "tx-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := db.QueryTransactionsByTimeRange(ctx, "account-1", base.Add(time.Minute), base.Add(3*time.Minute), tt.options)
+			if err != nil {
+				t.Fatalf("QueryTransactionsByTimeRange() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("QueryTransactionsByTimeRange() returned %d transactions, want %d", len(got), len(tt.want))
+			}
+			for i, transaction := range got {
+				if transaction.UUID != tt.want[i] {
+					t.Errorf("transaction %d = %s, want %s", i, transaction.UUID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBatchPipelines(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := make([]*databases.Transaction, 10)
+	keys := make([]struct{ AccountID, UUID string }, 0, len(transactions)+1)
+	for i := range transactions {
+		transactions[i] = testTransaction("account-1", i, base)
+		keys = append(keys, struct{ AccountID, UUID string }{"account-1", transactions[i].UUID})
+	}
+	// Missing keys are skipped instead of failing the batch
+	keys = append(keys, struct{ AccountID, UUID string }{"account-1", "tx-missing"})
+
+	if err := db.BatchWriteTransactions(ctx, transactions, nil); err != nil {
+		t.Fatalf("BatchWriteTransactions() error = %v", err)
+	}
+
+	got, err := db.BatchReadTransactions(ctx, keys, nil)
+	if err != nil {
+		t.Fatalf("BatchReadTransactions() error = %v", err)
+	}
+	if len(got) != len(transactions) {
+		t.Errorf("BatchReadTransactions() returned %d transactions, want %d", len(got), len(transactions))
+	}
+
+	// Batch writes also update the account index
+	count, err := db.CountTransactionsByAccount(ctx, "account-1")
+	if err != nil {
+		t.Fatalf("CountTransactionsByAccount() error = %v", err)
+	}
+	if count != int64(len(transactions)) {
+		t.Errorf("CountTransactionsByAccount() = %d, want %d", count, len(transactions))
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	transaction := testTransaction("account-1", 1, base)
+
+	db.WriteTransaction(ctx, transaction, nil)
+	db.ReadTransaction(ctx, transaction.AccountID, transaction.UUID, nil)
+	db.ReadTransaction(ctx, transaction.AccountID, "tx-missing", nil)
+	db.QueryTransactionsByAccount(ctx, transaction.AccountID, nil)
+	db.BatchWriteTransactions(ctx, []*databases.Transaction{transaction}, nil)
+	db.BatchReadTransactions(ctx, []struct{ AccountID, UUID string }{{transaction.AccountID, transaction.UUID}}, nil)
+
+	want := map[string]int{
+		"readOperations":       2,
+		"writeOperations":      1,
+		"queryOperations":      1,
+		"batchReadOperations":  1,
+		"batchWriteOperations": 1,
+		"failedOperations":     1,
+		"totalOperations":      6,
+	}
+	got := db.GetMetrics()
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("GetMetrics()[%q] = %v, want %d", key, got[key], value)
+		}
+	}
+
+	db.ResetMetrics()
+	if got := db.GetMetrics()["totalOperations"]; got != 0 {
+		t.Errorf("totalOperations after ResetMetrics() = %v, want 0", got)
+	}
+}
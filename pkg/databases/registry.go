@@ -0,0 +1,59 @@
+package databases
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]DatabaseFactory)
+)
+
+// RegisterFactory makes a database factory available by name. Adapter packages call it
+// from init, so importing an adapter package registers it. Names are case-insensitive;
+// registering a name twice or a nil factory panics.
+func RegisterFactory(name string, f DatabaseFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if f == nil {
+		panic("databases: RegisterFactory factory is nil")
+	}
+	key := strings.ToLower(name)
+	if _, dup := factories[key]; dup {
+		panic("databases: RegisterFactory called twice for " + key)
+	}
+	factories[key] = f
+}
+
+// GetFactory returns the factory registered under name
+func GetFactory(name string) (DatabaseFactory, error) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	f, ok := factories[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database type: %s (registered: %s)", name, strings.Join(factoryNames(), ", "))
+	}
+	return f, nil
+}
+
+// Factories returns the sorted names of the registered factories
+func Factories() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	return factoryNames()
+}
+
+// factoryNames lists the registered names; the caller holds factoriesMu
+func factoryNames() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
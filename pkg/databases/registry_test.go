@@ -0,0 +1,115 @@
+package databases
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeFactory is a DatabaseFactory that creates no database. The name keeps the
+// struct non-empty so distinct factories compare unequal.
+type fakeFactory struct{ name string }
+
+func (f *fakeFactory) CreateDatabase(config map[string]interface{}) (Database, error) {
+	return nil, nil
+}
+
+// registerTestFactory registers a fake factory under name unless an earlier run of the
+// test already did, since the registry cannot unregister, and returns the registered one
+func registerTestFactory(t *testing.T, name string) DatabaseFactory {
+	t.Helper()
+	if f, err := GetFactory(name); err == nil {
+		return f
+	}
+	f := &fakeFactory{name: name}
+	RegisterFactory(name, f)
+	return f
+}
+
+// expectPanic fails the test if fn does not panic
+func expectPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s did not panic", name)
+		}
+	}()
+	fn()
+}
+
+func TestRegisterFactory(t *testing.T) {
+	registered := registerTestFactory(t, "Registry-Test")
+
+	tests := []struct {
+		name    string
+		lookup  string
+		want    DatabaseFactory
+		wantErr bool
+	}{
+		{"registered name", "registry-test", registered, false},
+		{"case-insensitive", "REGISTRY-TEST", registered, false},
+		{"unknown name", "registry-test-missing", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetFactory(tt.lookup)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("GetFactory(%q) error = nil, want an error", tt.lookup)
+				}
+				if !strings.Contains(err.Error(), "unsupported database type: "+tt.lookup) ||
+					!strings.Contains(err.Error(), "registry-test") {
+					t.Errorf("GetFactory(%q) error = %q, want the name and the registered types", tt.lookup, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetFactory(%q) error = %v", tt.lookup, err)
+			}
+			if got != tt.want {
+				t.Errorf("GetFactory(%q) = %v, want %v", tt.lookup, got, tt.want)
+			}
+		})
+	}
+
+	if !contains(Factories(), "registry-test") {
+		t.Errorf("Factories() = %v, want it to list registry-test", Factories())
+	}
+}
+
+func TestRegisterFactoryPanics(t *testing.T) {
+	registerTestFactory(t, "registry-test-dup")
+
+	expectPanic(t, "registering a name twice", func() {
+		RegisterFactory("REGISTRY-TEST-DUP", &fakeFactory{})
+	})
+	expectPanic(t, "registering a nil factory", func() {
+		RegisterFactory("registry-test-nil", nil)
+	})
+
+	if contains(Factories(), "registry-test-nil") {
+		t.Error("a nil factory was registered")
+	}
+}
+
+func TestFactoriesSorted(t *testing.T) {
+	registerTestFactory(t, "registry-test-b")
+	registerTestFactory(t, "registry-test-a")
+
+	names := Factories()
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Fatalf("Factories() = %v, want sorted names", names)
+		}
+	}
+}
+
+// contains reports whether names includes name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
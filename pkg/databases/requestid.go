@@ -0,0 +1,50 @@
+package databases
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestTrace collects the request ID of the last service call made with its context,
+// so operations can be correlated with X-Ray and CloudWatch
+type RequestTrace struct {
+	mu        sync.Mutex
+	requestID string
+}
+
+type requestTraceKey struct{}
+
+// WithRequestTrace returns a context whose service calls record their request ID in
+// the returned trace. Adapters that support it record IDs with SetRequestID.
+func WithRequestTrace(ctx context.Context) (context.Context, *RequestTrace) {
+	trace := &RequestTrace{}
+	return context.WithValue(ctx, requestTraceKey{}, trace), trace
+}
+
+// SetRequestID records requestID in the context's trace, if there is one
+func SetRequestID(ctx context.Context, requestID string) {
+	trace, ok := ctx.Value(requestTraceKey{}).(*RequestTrace)
+	if !ok || requestID == "" {
+		return
+	}
+	trace.mu.Lock()
+	trace.requestID = requestID
+	trace.mu.Unlock()
+}
+
+// RequestID returns the last recorded request ID, or an empty string
+func (t *RequestTrace) RequestID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requestID
+}
+
+// Metrics returns the recorded request ID as per-operation custom metrics, or nil
+// when no ID was recorded
+func (t *RequestTrace) Metrics() map[string]interface{} {
+	requestID := t.RequestID()
+	if requestID == "" {
+		return nil
+	}
+	return map[string]interface{}{"awsRequestId": requestID}
+}
@@ -2,14 +2,18 @@ package timestream
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/timestreamquery"
+	querytypes "github.com/aws/aws-sdk-go-v2/service/timestreamquery/types"
 	"github.com/aws/aws-sdk-go-v2/service/timestreamwrite"
 	"github.com/aws/aws-sdk-go-v2/service/timestreamwrite/types"
 	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases"
@@ -17,25 +21,57 @@ import (
 
 // TimestreamDatabase implements the Database interface for AWS Timestream
 type TimestreamDatabase struct {
-	writeClient  *timestreamwrite.Client
-	queryClient  *timestreamquery.Client
-	databaseName string
-	tableName    string
-	metrics      map[string]interface{}
-	initialized  bool
+	writeClient     *timestreamwrite.Client
+	queryClient     *timestreamquery.Client
+	databaseName    string
+	tableName       string
+	retention       types.RetentionProperties
+	updateRetention bool
+	clampTimestamps bool
+	metrics         map[string]interface{}
+	metricsMu       sync.Mutex
+	latencies       map[string]time.Duration // Total latency per operation kind, for the running averages
+	initialized     bool
 }
 
 // TimestreamConfig holds configuration for the Timestream database
 type TimestreamConfig struct {
-	Region       string
-	DatabaseName string
-	TableName    string
-	Endpoint     string
+	Region                string
+	DatabaseName          string
+	TableName             string
+	Endpoint              string
+	MemoryRetentionHours  int64 // Memory store retention for created tables
+	MagneticRetentionDays int64 // Magnetic store retention for created tables
+	UpdateRetention       bool  // Apply the retention settings to an existing table
+	ClampTimestamps       bool  // Move timestamps outside the writable window into it instead of rejecting them
 }
 
+// Retention limits accepted by Timestream
+const (
+	defaultMemoryRetentionHours  = 24
+	defaultMagneticRetentionDays = 30
+	maxMemoryRetentionHours      = 8766
+	maxMagneticRetentionDays     = 73000
+)
+
+// maxFutureSkew is how far ahead of the current time Timestream accepts a record
+const maxFutureSkew = 15 * time.Minute
+
+// clampMargin keeps clamped timestamps clear of the window edges so they are still
+// writable when the request reaches Timestream
+const clampMargin = time.Minute
+
+// ErrTimestampOutsideRetention is returned for records Timestream would reject because
+// their time is older than the memory store retention or too far in the future
+var ErrTimestampOutsideRetention = errors.New("timestamp outside the writable retention window")
+
 // TimestreamFactory creates Timestream database instances
 type TimestreamFactory struct{}
 
+func init() {
+	databases.RegisterFactory("timestream", NewTimestreamFactory())
+}
+
 // NewTimestreamFactory creates a new Timestream factory
 func NewTimestreamFactory() *TimestreamFactory {
 	return &TimestreamFactory{}
@@ -45,9 +81,11 @@ func NewTimestreamFactory() *TimestreamFactory {
 func (f *TimestreamFactory) CreateDatabase(config map[string]interface{}) (databases.Database, error) {
 	// Extract configuration
 	dbConfig := TimestreamConfig{
-		Region:       "us-east-1", // Default region
-		DatabaseName: "BenchmarkDB",
-		TableName:    "Transactions",
+		Region:                "us-east-1", // Default region
+		DatabaseName:          "BenchmarkDB",
+		TableName:             "Transactions",
+		MemoryRetentionHours:  defaultMemoryRetentionHours,
+		MagneticRetentionDays: defaultMagneticRetentionDays,
 	}
 
 	if region, ok := config["region"].(string); ok {
@@ -62,18 +100,55 @@ func (f *TimestreamFactory) CreateDatabase(config map[string]interface{}) (datab
 	if endpoint, ok := config["endpoint"].(string); ok {
 		dbConfig.Endpoint = endpoint
 	}
+	if hours, ok := intConfig(config, "memoryRetentionHours"); ok {
+		if hours < 1 || hours > maxMemoryRetentionHours {
+			return nil, fmt.Errorf("memoryRetentionHours must be between 1 and %d, got %d", maxMemoryRetentionHours, hours)
+		}
+		dbConfig.MemoryRetentionHours = hours
+	}
+	if days, ok := intConfig(config, "magneticRetentionDays"); ok {
+		if days < 1 || days > maxMagneticRetentionDays {
+			return nil, fmt.Errorf("magneticRetentionDays must be between 1 and %d, got %d", maxMagneticRetentionDays, days)
+		}
+		dbConfig.MagneticRetentionDays = days
+	}
+	if updateRetention, ok := config["updateRetention"].(bool); ok {
+		dbConfig.UpdateRetention = updateRetention
+	}
+	if clampTimestamps, ok := config["clampTimestamps"].(bool); ok {
+		dbConfig.ClampTimestamps = clampTimestamps
+	}
 
 	return NewTimestreamDatabase(dbConfig)
 }
 
+// intConfig reads an integer config value, accepting the float64 produced by JSON decoding
+func intConfig(config map[string]interface{}, key string) (int64, bool) {
+	switch v := config[key].(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
 // NewTimestreamDatabase creates a new AWS Timestream database instance
 func NewTimestreamDatabase(config TimestreamConfig) (*TimestreamDatabase, error) {
 	db := &TimestreamDatabase{
 		databaseName: config.DatabaseName,
 		tableName:    config.TableName,
-		metrics:      make(map[string]interface{}),
-		initialized:  false,
+		retention: types.RetentionProperties{
+			MemoryStoreRetentionPeriodInHours:  aws.Int64(config.MemoryRetentionHours),
+			MagneticStoreRetentionPeriodInDays: aws.Int64(config.MagneticRetentionDays),
+		},
+		updateRetention: config.UpdateRetention,
+		clampTimestamps: config.ClampTimestamps,
+		initialized:     false,
 	}
+	db.ResetMetrics()
 
 	// Create AWS configuration
 	var err error
@@ -127,7 +202,6 @@ func (db *TimestreamDatabase) Initialize(ctx context.Context) error {
 	}
 
 	db.initialized = true
-	db.ResetMetrics()
 	return nil
 }
 
@@ -138,8 +212,16 @@ func (db *TimestreamDatabase) Close() error {
 	return nil
 }
 
+// CanReinitialize implements the databases.Reinitializable interface; Close keeps the
+// clients, so Initialize only checks the database and table again
+func (db *TimestreamDatabase) CanReinitialize() bool {
+	return true
+}
+
 // ReadTransaction implements the Database interface
-func (db *TimestreamDatabase) ReadTransaction(ctx context.Context, accountID, uuid string, options *databases.ReadOptions) (*databases.Transaction, error) {
+func (db *TimestreamDatabase) ReadTransaction(ctx context.Context, accountID, uuid string, options *databases.ReadOptions) (_ *databases.Transaction, err error) {
+	defer db.recordOperation("read", time.Now(), &err)
+
 	if !db.initialized {
 		return nil, errors.New("database not initialized")
 	}
@@ -150,7 +232,7 @@ func (db *TimestreamDatabase) ReadTransaction(ctx context.Context, accountID, uu
 		FROM "%s"."%s"
 		WHERE account_id = '%s' AND uuid = '%s'
 		LIMIT 1
-	`, db.databaseName, db.tableName, accountID, uuid)
+	`, db.databaseName, db.tableName, escapeTimestreamLiteral(accountID), escapeTimestreamLiteral(uuid))
 
 	// Execute the query
 	result, err := db.queryClient.Query(ctx, &timestreamquery.QueryInput{
@@ -162,7 +244,7 @@ func (db *TimestreamDatabase) ReadTransaction(ctx context.Context, accountID, uu
 
 	// Check if we got a result
 	if len(result.Rows) == 0 {
-		return nil, fmt.Errorf("transaction not found")
+		return nil, fmt.Errorf("%w: %s", databases.ErrTransactionNotFound, uuid)
 	}
 
 	// Parse the result
@@ -183,7 +265,7 @@ func (db *TimestreamDatabase) ReadTransaction(ctx context.Context, accountID, uu
 		return nil, err
 	}
 	txType := databases.TransactionType(*row.Data[4].ScalarValue)
-	txMetadata := *row.Data[5].ScalarValue
+	txMetadata := decodeMetadata(*row.Data[5].ScalarValue)
 
 	// Create and return the transaction
 	transaction := &databases.Transaction{
@@ -199,7 +281,9 @@ func (db *TimestreamDatabase) ReadTransaction(ctx context.Context, accountID, uu
 }
 
 // WriteTransaction implements the Database interface
-func (db *TimestreamDatabase) WriteTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) error {
+func (db *TimestreamDatabase) WriteTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) (err error) {
+	defer db.recordOperation("write", time.Now(), &err)
+
 	if !db.initialized {
 		return errors.New("database not initialized")
 	}
@@ -208,35 +292,20 @@ func (db *TimestreamDatabase) WriteTransaction(ctx context.Context, transaction
 		return errors.New("transaction cannot be nil")
 	}
 
+	// Timestream writes are unconditional; a record for the same dimensions and time is
+	// an upsert, so there is nothing to check a condition against
+	if options != nil && options.Condition != "" {
+		return fmt.Errorf("timestream conditional write: %w", databases.ErrNotSupported)
+	}
+
 	// Prepare record for Timestream
-	record := types.Record{
-		Dimensions: []types.Dimension{
-			{
-				Name:  aws.String("uuid"),
-				Value: aws.String(transaction.UUID),
-			},
-			{
-				Name:  aws.String("account_id"),
-				Value: aws.String(transaction.AccountID),
-			},
-			{
-				Name:  aws.String("transaction_type"),
-				Value: aws.String(string(transaction.TransactionType)),
-			},
-			{
-				Name:  aws.String("metadata"),
-				Value: aws.String(fmt.Sprintf("%v", transaction.Metadata)),
-			},
-		},
-		MeasureName:      aws.String("amount"),
-		MeasureValue:     aws.String(fmt.Sprintf("%f", transaction.Amount)),
-		MeasureValueType: types.MeasureValueTypeDouble,
-		Time:             aws.String(strconv.FormatInt(transaction.Timestamp.UnixNano(), 10)),
-		TimeUnit:         types.TimeUnitNanoseconds,
+	record, err := db.recordFor(transaction)
+	if err != nil {
+		return err
 	}
 
 	// Write the record to Timestream
-	_, err := db.writeClient.WriteRecords(ctx, &timestreamwrite.WriteRecordsInput{
+	_, err = db.writeClient.WriteRecords(ctx, &timestreamwrite.WriteRecordsInput{
 		DatabaseName: aws.String(db.databaseName),
 		TableName:    aws.String(db.tableName),
 		Records:      []types.Record{record},
@@ -244,20 +313,31 @@ func (db *TimestreamDatabase) WriteTransaction(ctx context.Context, transaction
 	if err != nil {
 		return fmt.Errorf("failed to write record: %w", err)
 	}
+	db.addDataPoints(1)
 
 	return nil
 }
 
+// UpdateTransaction implements the Database interface
+func (db *TimestreamDatabase) UpdateTransaction(ctx context.Context, transaction *databases.Transaction, options *databases.WriteOptions) error {
+	// Timestream is append-only and has no in-place update, so an update is
+	// recorded as a new record for the same dimensions. Queries ordered by time
+	// will see the most recent value.
+	return db.WriteTransaction(ctx, transaction, options)
+}
+
 // DeleteTransaction implements the Database interface
-func (db *TimestreamDatabase) DeleteTransaction(ctx context.Context, accountID, uuid string) error {
+func (db *TimestreamDatabase) DeleteTransaction(ctx context.Context, accountID, uuid string, options *databases.DeleteOptions) error {
 	// Timestream doesn't support direct record deletion
 	// Typically, time-series databases rely on retention policies for data management
 	// This is a limitation of Timestream
-	return fmt.Errorf("timestream does not support direct record deletion; use retention policies instead")
+	return fmt.Errorf("timestream delete (use retention policies instead): %w", databases.ErrNotSupported)
 }
 
 // QueryTransactionsByAccount implements the Database interface
-func (db *TimestreamDatabase) QueryTransactionsByAccount(ctx context.Context, accountID string, options *databases.QueryOptions) ([]*databases.Transaction, error) {
+func (db *TimestreamDatabase) QueryTransactionsByAccount(ctx context.Context, accountID string, options *databases.QueryOptions) (_ []*databases.Transaction, err error) {
+	defer db.recordOperation("query", time.Now(), &err)
+
 	if !db.initialized {
 		return nil, errors.New("database not initialized")
 	}
@@ -281,7 +361,7 @@ func (db *TimestreamDatabase) QueryTransactionsByAccount(ctx context.Context, ac
 		WHERE account_id = '%s'
 		ORDER BY time %s
 		LIMIT %d
-	`, db.databaseName, db.tableName, accountID, orderBy, limit)
+	`, db.databaseName, db.tableName, escapeTimestreamLiteral(accountID), orderBy, limit)
 
 	// Execute the query
 	result, err := db.queryClient.Query(ctx, &timestreamquery.QueryInput{
@@ -310,7 +390,7 @@ func (db *TimestreamDatabase) QueryTransactionsByAccount(ctx context.Context, ac
 			continue // Skip rows with invalid amounts
 		}
 		txType := databases.TransactionType(*row.Data[4].ScalarValue)
-		txMetadata := *row.Data[5].ScalarValue
+		txMetadata := decodeMetadata(*row.Data[5].ScalarValue)
 
 		// Create transaction and add to results
 		transaction := &databases.Transaction{
@@ -327,8 +407,106 @@ func (db *TimestreamDatabase) QueryTransactionsByAccount(ctx context.Context, ac
 	return transactions, nil
 }
 
+// CountTransactionsByAccount implements the Database interface
+func (db *TimestreamDatabase) CountTransactionsByAccount(ctx context.Context, accountID string) (_ int64, err error) {
+	defer db.recordOperation("query", time.Now(), &err)
+
+	if !db.initialized {
+		return 0, errors.New("database not initialized")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM "%s"."%s"
+		WHERE account_id = '%s'
+		AND measure_name = 'amount'
+	`, db.databaseName, db.tableName, escapeTimestreamLiteral(accountID))
+
+	// Execute the query
+	result, err := db.queryClient.Query(ctx, &timestreamquery.QueryInput{
+		QueryString: aws.String(query),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count query failed: %w", err)
+	}
+
+	if len(result.Rows) == 0 || len(result.Rows[0].Data) == 0 || result.Rows[0].Data[0].ScalarValue == nil {
+		return 0, nil
+	}
+
+	count, err := strconv.ParseInt(*result.Rows[0].Data[0].ScalarValue, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse count: %w", err)
+	}
+	return count, nil
+}
+
+// QueryTransactionsByAccountPaged implements the Database interface
+func (db *TimestreamDatabase) QueryTransactionsByAccountPaged(ctx context.Context, accountID string, options *databases.QueryOptions) (_ *databases.PagedTransactions, err error) {
+	defer db.recordOperation("query", time.Now(), &err)
+
+	if !db.initialized {
+		return nil, errors.New("database not initialized")
+	}
+
+	// Set default options if not provided
+	limit := int64(100)
+	if options != nil && options.Limit > 0 {
+		limit = options.Limit
+	}
+
+	orderBy := "ASC" // Default sort order
+	if options != nil && !options.ScanIndexForward {
+		orderBy = "DESC"
+	}
+
+	// The page size is controlled by MaxRows rather than a LIMIT clause, and the
+	// query string must stay identical across pages for NextToken to be valid
+	query := fmt.Sprintf(`
+		SELECT uuid, account_id, time, measure_value::double AS amount, transaction_type, metadata
+		FROM "%s"."%s"
+		WHERE account_id = '%s'
+		ORDER BY time %s
+	`, db.databaseName, db.tableName, escapeTimestreamLiteral(accountID), orderBy)
+
+	input := &timestreamquery.QueryInput{
+		QueryString: aws.String(query),
+		MaxRows:     aws.Int32(int32(limit)),
+	}
+	if options != nil && options.StartToken != "" {
+		input.NextToken = aws.String(options.StartToken)
+	}
+
+	// Execute the query
+	result, err := db.queryClient.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	// Parse the results
+	transactions := make([]*databases.Transaction, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		transaction, err := rowToTransaction(row)
+		if err != nil {
+			continue // Skip invalid rows
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	page := &databases.PagedTransactions{
+		Transactions: transactions,
+	}
+	if result.NextToken != nil {
+		page.NextToken = *result.NextToken
+	}
+
+	return page, nil
+}
+
 // QueryTransactionsByTimeRange implements the Database interface
-func (db *TimestreamDatabase) QueryTransactionsByTimeRange(ctx context.Context, accountID string, startTime, endTime time.Time, options *databases.QueryOptions) ([]*databases.Transaction, error) {
+func (db *TimestreamDatabase) QueryTransactionsByTimeRange(ctx context.Context, accountID string, startTime, endTime time.Time, options *databases.QueryOptions) (_ []*databases.Transaction, err error) {
+	defer db.recordOperation("query", time.Now(), &err)
+
 	if !db.initialized {
 		return nil, errors.New("database not initialized")
 	}
@@ -356,7 +534,7 @@ func (db *TimestreamDatabase) QueryTransactionsByTimeRange(ctx context.Context,
 		AND time BETWEEN %d AND %d
 		ORDER BY time %s
 		LIMIT %d
-	`, db.databaseName, db.tableName, accountID, startTimeNanos, endTimeNanos, orderBy, limit)
+	`, db.databaseName, db.tableName, escapeTimestreamLiteral(accountID), startTimeNanos, endTimeNanos, orderBy, limit)
 
 	// Execute the query
 	result, err := db.queryClient.Query(ctx, &timestreamquery.QueryInput{
@@ -385,7 +563,7 @@ func (db *TimestreamDatabase) QueryTransactionsByTimeRange(ctx context.Context,
 			continue // Skip rows with invalid amounts
 		}
 		txType := databases.TransactionType(*row.Data[4].ScalarValue)
-		txMetadata := *row.Data[5].ScalarValue
+		txMetadata := decodeMetadata(*row.Data[5].ScalarValue)
 
 		// Create transaction and add to results
 		transaction := &databases.Transaction{
@@ -402,8 +580,71 @@ func (db *TimestreamDatabase) QueryTransactionsByTimeRange(ctx context.Context,
 	return transactions, nil
 }
 
+// aggregateFunctions lists the aggregation functions accepted by AggregateTransactions
+var aggregateFunctions = map[string]bool{
+	"SUM":   true,
+	"AVG":   true,
+	"MIN":   true,
+	"MAX":   true,
+	"COUNT": true,
+}
+
+// AggregateTransactions implements the databases.Aggregator interface by aggregating the
+// amounts of an account's transactions within the time range
+func (db *TimestreamDatabase) AggregateTransactions(ctx context.Context, accountID string, startTime, endTime time.Time, aggFunc string) (_ float64, err error) {
+	defer db.recordOperation("query", time.Now(), &err)
+
+	if !db.initialized {
+		return 0, errors.New("database not initialized")
+	}
+
+	aggFunc = strings.ToUpper(aggFunc)
+	if !aggregateFunctions[aggFunc] {
+		return 0, fmt.Errorf("unsupported aggregation function %q", aggFunc)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s(measure_value::double)
+		FROM "%s"."%s"
+		WHERE account_id = '%s'
+		AND measure_name = 'amount'
+		AND time BETWEEN from_nanoseconds(%d) AND from_nanoseconds(%d)
+	`, aggFunc, db.databaseName, db.tableName, escapeTimestreamLiteral(accountID), startTime.UnixNano(), endTime.UnixNano())
+
+	// Execute the query
+	result, err := db.queryClient.Query(ctx, &timestreamquery.QueryInput{
+		QueryString: aws.String(query),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("aggregate query failed: %w", err)
+	}
+
+	// Aggregates over an empty range return a single NULL row
+	if len(result.Rows) == 0 || len(result.Rows[0].Data) == 0 || result.Rows[0].Data[0].ScalarValue == nil {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseFloat(*result.Rows[0].Data[0].ScalarValue, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse aggregate value: %w", err)
+	}
+	return value, nil
+}
+
+// ScanTransactions implements the Database interface; Timestream does not support full-table scans
+func (db *TimestreamDatabase) ScanTransactions(ctx context.Context, options *databases.ScanOptions) ([]*databases.Transaction, error) {
+	return nil, fmt.Errorf("Timestream scan: %w", databases.ErrNotSupported)
+}
+
+// ScanTransactionsPaged implements the Database interface; Timestream does not support full-table scans
+func (db *TimestreamDatabase) ScanTransactionsPaged(ctx context.Context, options *databases.ScanOptions) (*databases.PagedTransactions, error) {
+	return nil, fmt.Errorf("Timestream scan: %w", databases.ErrNotSupported)
+}
+
 // BatchReadTransactions implements the Database interface
-func (db *TimestreamDatabase) BatchReadTransactions(ctx context.Context, keys []struct{ AccountID, UUID string }, options *databases.BatchOptions) ([]*databases.Transaction, error) {
+func (db *TimestreamDatabase) BatchReadTransactions(ctx context.Context, keys []struct{ AccountID, UUID string }, options *databases.BatchOptions) (_ []*databases.Transaction, err error) {
+	defer db.recordOperation("batchRead", time.Now(), &err)
+
 	if !db.initialized {
 		return nil, errors.New("database not initialized")
 	}
@@ -412,30 +653,77 @@ func (db *TimestreamDatabase) BatchReadTransactions(ctx context.Context, keys []
 		return []*databases.Transaction{}, nil
 	}
 
-	// Timestream does not have a native batch read API, so we'll implement this using multiple individual reads
-	// For better performance in a production system, you might want to use a more sophisticated approach
+	// Timestream has no native batch read API, so issue a single IN query per account
+	// instead of one round trip per key
+	uuidsByAccount := make(map[string][]string)
+	var accountOrder []string
+	for _, key := range keys {
+		if _, ok := uuidsByAccount[key.AccountID]; !ok {
+			accountOrder = append(accountOrder, key.AccountID)
+		}
+		uuidsByAccount[key.AccountID] = append(uuidsByAccount[key.AccountID], key.UUID)
+	}
 
-	transactions := make([]*databases.Transaction, 0, len(keys))
-	readOptions := &databases.ReadOptions{
-		ConsistentRead: true,
+	// Index results by account and UUID so they can be mapped back to the requested keys
+	found := make(map[struct{ AccountID, UUID string }]*databases.Transaction, len(keys))
+
+	for _, accountID := range accountOrder {
+		quoted := make([]string, 0, len(uuidsByAccount[accountID]))
+		for _, uuid := range uuidsByAccount[accountID] {
+			quoted = append(quoted, "'"+escapeTimestreamLiteral(uuid)+"'")
+		}
+
+		query := fmt.Sprintf(`
+		SELECT uuid, account_id, time, measure_value::double AS amount, transaction_type, metadata
+		FROM "%s"."%s"
+		WHERE account_id = '%s' AND uuid IN (%s)
+	`, db.databaseName, db.tableName, escapeTimestreamLiteral(accountID), strings.Join(quoted, ", "))
+
+		// Follow NextToken until the full result set has been read
+		input := &timestreamquery.QueryInput{
+			QueryString: aws.String(query),
+		}
+		for {
+			result, err := db.queryClient.Query(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("batch query failed: %w", err)
+			}
+
+			for _, row := range result.Rows {
+				transaction, err := rowToTransaction(row)
+				if err != nil {
+					continue // Skip invalid rows
+				}
+
+				// Keep the most recent record when a transaction has been rewritten
+				key := struct{ AccountID, UUID string }{transaction.AccountID, transaction.UUID}
+				if existing, ok := found[key]; !ok || transaction.Timestamp.After(existing.Timestamp) {
+					found[key] = transaction
+				}
+			}
+
+			if result.NextToken == nil {
+				break
+			}
+			input.NextToken = result.NextToken
+		}
 	}
 
-	// Use a simple sequential implementation for now
+	// Return results in request order, skipping keys that were not found
+	transactions := make([]*databases.Transaction, 0, len(keys))
 	for _, key := range keys {
-		transaction, err := db.ReadTransaction(ctx, key.AccountID, key.UUID, readOptions)
-		if err != nil {
-			// Log the error but continue with other transactions
-			fmt.Printf("Error reading transaction %s: %v\n", key.UUID, err)
-			continue
+		if transaction, ok := found[key]; ok {
+			transactions = append(transactions, transaction)
 		}
-		transactions = append(transactions, transaction)
 	}
 
 	return transactions, nil
 }
 
 // BatchWriteTransactions implements the Database interface
-func (db *TimestreamDatabase) BatchWriteTransactions(ctx context.Context, transactions []*databases.Transaction, options *databases.BatchOptions) error {
+func (db *TimestreamDatabase) BatchWriteTransactions(ctx context.Context, transactions []*databases.Transaction, options *databases.BatchOptions) (err error) {
+	defer db.recordOperation("batchWrite", time.Now(), &err)
+
 	if !db.initialized {
 		return errors.New("database not initialized")
 	}
@@ -458,30 +746,9 @@ func (db *TimestreamDatabase) BatchWriteTransactions(ctx context.Context, transa
 		// Prepare the batch of records
 		records := make([]types.Record, 0, len(batchTransactions))
 		for _, transaction := range batchTransactions {
-			record := types.Record{
-				Dimensions: []types.Dimension{
-					{
-						Name:  aws.String("uuid"),
-						Value: aws.String(transaction.UUID),
-					},
-					{
-						Name:  aws.String("account_id"),
-						Value: aws.String(transaction.AccountID),
-					},
-					{
-						Name:  aws.String("transaction_type"),
-						Value: aws.String(string(transaction.TransactionType)),
-					},
-					{
-						Name:  aws.String("metadata"),
-						Value: aws.String(fmt.Sprintf("%v", transaction.Metadata)),
-					},
-				},
-				MeasureName:      aws.String("amount"),
-				MeasureValue:     aws.String(fmt.Sprintf("%f", transaction.Amount)),
-				MeasureValueType: types.MeasureValueTypeDouble,
-				Time:             aws.String(strconv.FormatInt(transaction.Timestamp.UnixNano(), 10)),
-				TimeUnit:         types.TimeUnitNanoseconds,
+			record, err := db.recordFor(transaction)
+			if err != nil {
+				return err
 			}
 			records = append(records, record)
 		}
@@ -495,6 +762,7 @@ func (db *TimestreamDatabase) BatchWriteTransactions(ctx context.Context, transa
 		if err != nil {
 			return fmt.Errorf("failed to write batch: %w", err)
 		}
+		db.addDataPoints(len(records))
 	}
 
 	return nil
@@ -511,8 +779,17 @@ func (db *TimestreamDatabase) ExecuteTransactWrite(ctx context.Context, transact
 	return db.BatchWriteTransactions(ctx, transactions, &databases.BatchOptions{})
 }
 
+// ExecuteTransactRead implements the Database interface
+func (db *TimestreamDatabase) ExecuteTransactRead(ctx context.Context, keys []struct{ AccountID, UUID string }) ([]*databases.Transaction, error) {
+	// Timestream has no transactional read API
+	return nil, fmt.Errorf("timestream transact read: %w", databases.ErrNotSupported)
+}
+
 // GetMetrics implements the Database interface
 func (db *TimestreamDatabase) GetMetrics() map[string]interface{} {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
 	// Return a copy to avoid race conditions
 	metrics := make(map[string]interface{})
 	for k, v := range db.metrics {
@@ -523,19 +800,55 @@ func (db *TimestreamDatabase) GetMetrics() map[string]interface{} {
 
 // ResetMetrics implements the Database interface
 func (db *TimestreamDatabase) ResetMetrics() {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
 	db.metrics = map[string]interface{}{
-		"readOperations":       0,
-		"writeOperations":      0,
-		"queryOperations":      0,
-		"batchReadOperations":  0,
-		"batchWriteOperations": 0,
-		"failedOperations":     0,
-		"totalOperations":      0,
-		"totalDataPoints":      0,
-		"averageReadLatency":   time.Duration(0),
-		"averageWriteLatency":  time.Duration(0),
-		"averageQueryLatency":  time.Duration(0),
+		"failedOperations": 0,
+		"totalOperations":  0,
+		"totalDataPoints":  0,
+	}
+	for _, keys := range operationMetricKeys {
+		db.metrics[keys.count] = 0
+		db.metrics[keys.latency] = time.Duration(0)
 	}
+	db.latencies = make(map[string]time.Duration)
+}
+
+// operationMetricKeys maps an operation kind to its counter and average latency metrics
+var operationMetricKeys = map[string]struct{ count, latency string }{
+	"read":       {"readOperations", "averageReadLatency"},
+	"write":      {"writeOperations", "averageWriteLatency"},
+	"query":      {"queryOperations", "averageQueryLatency"},
+	"batchRead":  {"batchReadOperations", "averageBatchReadLatency"},
+	"batchWrite": {"batchWriteOperations", "averageBatchWriteLatency"},
+}
+
+// recordOperation updates the operation counters and running average latency.
+// It is deferred by each data method with a pointer to the method's error result.
+func (db *TimestreamDatabase) recordOperation(kind string, start time.Time, err *error) {
+	elapsed := time.Since(start)
+	keys := operationMetricKeys[kind]
+
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+
+	count := db.metrics[keys.count].(int) + 1
+	db.metrics[keys.count] = count
+	db.metrics["totalOperations"] = db.metrics["totalOperations"].(int) + 1
+	if *err != nil {
+		db.metrics["failedOperations"] = db.metrics["failedOperations"].(int) + 1
+	}
+
+	db.latencies[kind] += elapsed
+	db.metrics[keys.latency] = db.latencies[kind] / time.Duration(count)
+}
+
+// addDataPoints counts records written to Timestream
+func (db *TimestreamDatabase) addDataPoints(n int) {
+	db.metricsMu.Lock()
+	defer db.metricsMu.Unlock()
+	db.metrics["totalDataPoints"] = db.metrics["totalDataPoints"].(int) + n
 }
 
 // Helper methods
@@ -570,7 +883,7 @@ func (db *TimestreamDatabase) ensureDatabaseExists(ctx context.Context) error {
 // ensureTableExists checks if the table exists and creates it if it doesn't
 func (db *TimestreamDatabase) ensureTableExists(ctx context.Context) error {
 	// Try to describe the table to check if it exists
-	_, err := db.writeClient.DescribeTable(ctx, &timestreamwrite.DescribeTableInput{
+	output, err := db.writeClient.DescribeTable(ctx, &timestreamwrite.DescribeTableInput{
 		DatabaseName: aws.String(db.databaseName),
 		TableName:    aws.String(db.tableName),
 	})
@@ -579,15 +892,8 @@ func (db *TimestreamDatabase) ensureTableExists(ctx context.Context) error {
 	if err != nil {
 		var notFoundErr *types.ResourceNotFoundException
 		if errors.As(err, &notFoundErr) {
-			// Table doesn't exist, create it with default retention settings
-			_, err = db.writeClient.CreateTable(ctx, &timestreamwrite.CreateTableInput{
-				DatabaseName: aws.String(db.databaseName),
-				TableName:    aws.String(db.tableName),
-				RetentionProperties: &types.RetentionProperties{
-					MagneticStoreRetentionPeriodInDays: aws.Int64(30), // 30 days in magnetic store
-					MemoryStoreRetentionPeriodInHours:  aws.Int64(24), // 24 hours in memory store
-				},
-			})
+			// Table doesn't exist, create it with the configured retention settings
+			_, err = db.writeClient.CreateTable(ctx, newCreateTableInput(db.databaseName, db.tableName, db.retention))
 			if err != nil {
 				return fmt.Errorf("failed to create table: %w", err)
 			}
@@ -596,10 +902,173 @@ func (db *TimestreamDatabase) ensureTableExists(ctx context.Context) error {
 		return fmt.Errorf("error checking table existence: %w", err)
 	}
 
-	// Table exists
+	// Table exists; adjust its retention only when asked to, since it affects stored data
+	if db.updateRetention && !sameRetention(output.Table.RetentionProperties, &db.retention) {
+		_, err = db.writeClient.UpdateTable(ctx, newUpdateTableInput(db.databaseName, db.tableName, db.retention))
+		if err != nil {
+			return fmt.Errorf("failed to update table retention: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// newCreateTableInput builds the CreateTable request for the transactions table
+func newCreateTableInput(databaseName, tableName string, retention types.RetentionProperties) *timestreamwrite.CreateTableInput {
+	return &timestreamwrite.CreateTableInput{
+		DatabaseName:        aws.String(databaseName),
+		TableName:           aws.String(tableName),
+		RetentionProperties: &retention,
+	}
+}
+
+// newUpdateTableInput builds the UpdateTable request that applies the retention settings
+func newUpdateTableInput(databaseName, tableName string, retention types.RetentionProperties) *timestreamwrite.UpdateTableInput {
+	return &timestreamwrite.UpdateTableInput{
+		DatabaseName:        aws.String(databaseName),
+		TableName:           aws.String(tableName),
+		RetentionProperties: &retention,
+	}
+}
+
+// sameRetention reports whether an existing table's retention matches the wanted settings
+func sameRetention(current, wanted *types.RetentionProperties) bool {
+	if current == nil {
+		return false
+	}
+	return aws.ToInt64(current.MemoryStoreRetentionPeriodInHours) == aws.ToInt64(wanted.MemoryStoreRetentionPeriodInHours) &&
+		aws.ToInt64(current.MagneticStoreRetentionPeriodInDays) == aws.ToInt64(wanted.MagneticStoreRetentionPeriodInDays)
+}
+
+// recordFor converts a Transaction into a Timestream record after checking that its
+// timestamp falls in the window Timestream accepts writes for: no older than the memory
+// store retention and at most maxFutureSkew ahead. With clampTimestamps, timestamps
+// outside the window are moved just inside it; the caller's transaction is not modified.
+func (db *TimestreamDatabase) recordFor(transaction *databases.Transaction) (types.Record, error) {
+	now := time.Now()
+	retention := time.Duration(aws.ToInt64(db.retention.MemoryStoreRetentionPeriodInHours)) * time.Hour
+	oldest := now.Add(-retention)
+	newest := now.Add(maxFutureSkew)
+
+	timestamp := transaction.Timestamp
+	switch {
+	case timestamp.Before(oldest):
+		if !db.clampTimestamps {
+			return types.Record{}, fmt.Errorf("%w: %s is older than the %v memory store retention (set clampTimestamps to write it at the oldest allowed time)",
+				ErrTimestampOutsideRetention, timestamp.Format(time.RFC3339), retention)
+		}
+		timestamp = oldest.Add(clampMargin)
+	case timestamp.After(newest):
+		if !db.clampTimestamps {
+			return types.Record{}, fmt.Errorf("%w: %s is more than %v in the future (set clampTimestamps to write it at the current time)",
+				ErrTimestampOutsideRetention, timestamp.Format(time.RFC3339), maxFutureSkew)
+		}
+		timestamp = now
+	}
+
+	if !timestamp.Equal(transaction.Timestamp) {
+		clamped := *transaction
+		clamped.Timestamp = timestamp
+		transaction = &clamped
+	}
+	return transactionToRecord(transaction)
+}
+
+// transactionToRecord converts a Transaction into a Timestream record
+func transactionToRecord(transaction *databases.Transaction) (types.Record, error) {
+	metadata, err := encodeMetadata(transaction.Metadata)
+	if err != nil {
+		return types.Record{}, err
+	}
+
+	return types.Record{
+		Dimensions: []types.Dimension{
+			{
+				Name:  aws.String("uuid"),
+				Value: aws.String(transaction.UUID),
+			},
+			{
+				Name:  aws.String("account_id"),
+				Value: aws.String(transaction.AccountID),
+			},
+			{
+				Name:  aws.String("transaction_type"),
+				Value: aws.String(string(transaction.TransactionType)),
+			},
+			{
+				Name:  aws.String("metadata"),
+				Value: aws.String(metadata),
+			},
+		},
+		MeasureName:      aws.String("amount"),
+		MeasureValue:     aws.String(fmt.Sprintf("%f", transaction.Amount)),
+		MeasureValueType: types.MeasureValueTypeDouble,
+		Time:             aws.String(strconv.FormatInt(transaction.Timestamp.UnixNano(), 10)),
+		TimeUnit:         types.TimeUnitNanoseconds,
+	}, nil
+}
+
+// encodeMetadata serializes transaction metadata as JSON so structured values
+// survive being stored in a string dimension. Byte slices are encoded as
+// base64 strings, matching encoding/json.
+func encodeMetadata(metadata interface{}) (string, error) {
+	switch v := metadata.(type) {
+	case nil:
+		return "null", nil
+	case json.RawMessage:
+		return string(v), nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeMetadata parses JSON metadata written by encodeMetadata. Values that
+// are not valid JSON (e.g. written by older versions) are returned as-is.
+func decodeMetadata(value string) interface{} {
+	var metadata interface{}
+	if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+		return value
+	}
+	return metadata
+}
+
+// rowToTransaction converts a query result row into a Transaction. The row is
+// expected to contain uuid, account_id, time, amount, transaction_type, and metadata.
+func rowToTransaction(row querytypes.Row) (*databases.Transaction, error) {
+	if len(row.Data) < 6 {
+		return nil, fmt.Errorf("invalid result format")
+	}
+
+	txTimestamp, err := parseTimestreamTime(*row.Data[2].ScalarValue)
+	if err != nil {
+		return nil, err
+	}
+	txAmount, err := strconv.ParseFloat(*row.Data[3].ScalarValue, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &databases.Transaction{
+		UUID:            *row.Data[0].ScalarValue,
+		AccountID:       *row.Data[1].ScalarValue,
+		Timestamp:       txTimestamp,
+		Amount:          txAmount,
+		TransactionType: databases.TransactionType(*row.Data[4].ScalarValue),
+		Metadata:        decodeMetadata(*row.Data[5].ScalarValue),
+	}, nil
+}
+
+// escapeTimestreamLiteral escapes a value for use inside a single-quoted SQL
+// string literal by doubling any single quotes, so untrusted input such as
+// account IDs cannot terminate the literal early
+func escapeTimestreamLiteral(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
 // parseTimestreamTime converts a Timestream time string to a Go time.Time
 func parseTimestreamTime(timeStr string) (time.Time, error) {
 	// Try parsing as nanoseconds since epoch
@@ -33,15 +33,6 @@ for i in $(seq 1 $MAX_RETRIES); do
   sleep $RETRY_INTERVAL
 done
 
-# Use immuadmin and immudb client to set up the database
-# This is a simple setup - in a real implementation, you would use the Go client
-# to create the tables with proper indexes
-
-echo "Setting up database $DB_NAME and table $TABLE_NAME..."
-
-# Note: For a production implementation, use the ImmuDB Go client to:
-# 1. Create the database if it doesn't exist
-# 2. Create the table schema with proper indexes
-# 3. Set up any additional configuration needed
-
-echo "ImmuDB setup completed. The Go client will handle database and table creation during initialization." 
\ No newline at end of file
+# The setup binary creates the table and indexes through the Go client once this
+# script has confirmed the server is reachable
+echo "ImmuDB is ready for database $DB_NAME and table $TABLE_NAME."
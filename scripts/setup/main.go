@@ -1,17 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/pedro-hbl/lambda-gopher-benchmark/pkg/databases/immudb"
 )
 
 const (
 	dynamoDBSetupScript   = "/scripts/dynamodb.sh"
 	immuDBSetupScript     = "/scripts/immudb.sh"
-	timestreamSetupScript = "/scripts/timestream.sh"
+	timestreamSetupBinary = "/usr/local/bin/timestream-setup"
 )
 
 func main() {
@@ -30,10 +34,8 @@ func main() {
 		switch db {
 		case "all":
 			setupDynamoDB()
-			// These will be enabled when implemented
-			// setupImmuDB()
-			// setupTimestream()
-			return
+			setupImmuDB()
+			setupTimestream()
 		case "dynamodb":
 			setupDynamoDB()
 		case "immudb":
@@ -53,16 +55,52 @@ func setupDynamoDB() {
 
 func setupImmuDB() {
 	log.Println("Setting up ImmuDB...")
-	log.Println("ImmuDB setup is not yet implemented.")
-	// When implemented:
-	// runScript(immuDBSetupScript)
+	// The script waits until the server accepts connections
+	runScript(immuDBSetupScript)
+	createImmuDBSchema()
+}
+
+// createImmuDBSchema creates the transactions table and its indexes through the ImmuDB
+// adapter, whose Initialize owns the schema, configured from the same environment
+// variables as immudb.sh
+func createImmuDBSchema() {
+	port, err := strconv.Atoi(getEnv("IMMUDB_PORT", "3322"))
+	if err != nil {
+		log.Fatalf("Invalid IMMUDB_PORT: %v", err)
+	}
+
+	db, err := immudb.NewImmuDBFactory().CreateDatabase(map[string]interface{}{
+		"address":   getEnv("IMMUDB_ADDRESS", "127.0.0.1"),
+		"port":      port,
+		"username":  getEnv("IMMUDB_USERNAME", "immudb"),
+		"password":  getEnv("IMMUDB_PASSWORD", "immudb"),
+		"database":  getEnv("DB_NAME", "defaultdb"),
+		"tableName": getEnv("DB_TABLE_NAME", "Transactions"),
+		"poolSize":  1,
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure ImmuDB: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.Initialize(context.Background()); err != nil {
+		log.Fatalf("Failed to create ImmuDB schema: %v", err)
+	}
+	log.Println("ImmuDB schema created successfully")
 }
 
 func setupTimestream() {
 	log.Println("Setting up AWS Timestream...")
-	log.Println("Timestream setup is not yet implemented.")
-	// When implemented:
-	// runScript(timestreamSetupScript)
+	// tools/timestream-setup reads AWS_REGION, TIMESTREAM_ENDPOINT, DB_DATABASE_NAME and DB_TABLE_NAME
+	runCommand(timestreamSetupBinary)
+}
+
+// getEnv gets an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
 }
 
 func runScript(scriptPath string) {
@@ -81,15 +119,19 @@ func runScript(scriptPath string) {
 		log.Fatalf("Failed to make script executable: %v", err)
 	}
 
-	// Run the script
-	cmd := exec.Command(scriptPath)
+	runCommand(scriptPath)
+}
+
+// runCommand runs a setup script or binary, streaming its output
+func runCommand(path string) {
+	cmd := exec.Command(path)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	log.Printf("Running script: %s", scriptPath)
+	log.Printf("Running: %s", path)
 	if err := cmd.Run(); err != nil {
-		log.Fatalf("Failed to run setup script: %v", err)
+		log.Fatalf("Failed to run %s: %v", path, err)
 	}
 
-	log.Printf("Script %s completed successfully", scriptPath)
+	log.Printf("%s completed successfully", path)
 }
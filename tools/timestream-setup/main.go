@@ -2,15 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/timestreamwrite"
 	"github.com/aws/aws-sdk-go-v2/service/timestreamwrite/types"
+	"github.com/aws/smithy-go"
+)
+
+// Retry settings for create calls, which can fail transiently on throttling or while a
+// newly created database is not yet visible
+const (
+	createAttempts  = 5
+	createBaseSleep = time.Second
 )
 
 func main() {
@@ -19,6 +30,11 @@ func main() {
 	endpoint := getEnv("TIMESTREAM_ENDPOINT", "")
 	databaseName := getEnv("DB_DATABASE_NAME", "BenchmarkDB")
 	tableName := getEnv("DB_TABLE_NAME", "Transactions")
+	retention := &types.RetentionProperties{
+		MemoryStoreRetentionPeriodInHours:  aws.Int64(getEnvInt("MEMORY_RETENTION_HOURS", 24)),
+		MagneticStoreRetentionPeriodInDays: aws.Int64(getEnvInt("MAGNETIC_RETENTION_DAYS", 30)),
+	}
+	updateRetention := getEnv("UPDATE_RETENTION", "false") == "true"
 
 	log.Printf("Setting up Timestream database: %s, table: %s", databaseName, tableName)
 
@@ -50,7 +66,7 @@ func main() {
 	}
 
 	// Create table if it doesn't exist
-	if err := createTableIfNotExists(ctx, writeSvc, databaseName, tableName); err != nil {
+	if err := createTableIfNotExists(ctx, writeSvc, databaseName, tableName, retention, updateRetention); err != nil {
 		log.Fatalf("Failed to create table: %v", err)
 	}
 
@@ -70,8 +86,11 @@ func createDatabaseIfNotExists(ctx context.Context, client *timestreamwrite.Clie
 			log.Printf("Database %s does not exist, creating...", databaseName)
 
 			// Database doesn't exist, create it
-			_, err = client.CreateDatabase(ctx, &timestreamwrite.CreateDatabaseInput{
-				DatabaseName: aws.String(databaseName),
+			err = retry(createAttempts, createBaseSleep, func() error {
+				_, err := client.CreateDatabase(ctx, &timestreamwrite.CreateDatabaseInput{
+					DatabaseName: aws.String(databaseName),
+				})
+				return err
 			})
 			if err != nil {
 				return fmt.Errorf("failed to create database: %w", err)
@@ -86,8 +105,9 @@ func createDatabaseIfNotExists(ctx context.Context, client *timestreamwrite.Clie
 	return nil
 }
 
-// createTableIfNotExists creates the table if it doesn't already exist
-func createTableIfNotExists(ctx context.Context, client *timestreamwrite.Client, databaseName, tableName string) error {
+// createTableIfNotExists creates the table if it doesn't already exist, and applies the
+// retention settings to an existing table when updateRetention is set
+func createTableIfNotExists(ctx context.Context, client *timestreamwrite.Client, databaseName, tableName string, retention *types.RetentionProperties, updateRetention bool) error {
 	// Try to describe the table to check if it exists
 	_, err := client.DescribeTable(ctx, &timestreamwrite.DescribeTableInput{
 		DatabaseName: aws.String(databaseName),
@@ -100,13 +120,13 @@ func createTableIfNotExists(ctx context.Context, client *timestreamwrite.Client,
 			log.Printf("Table %s does not exist in database %s, creating...", tableName, databaseName)
 
 			// Table doesn't exist, create it
-			_, err = client.CreateTable(ctx, &timestreamwrite.CreateTableInput{
-				DatabaseName: aws.String(databaseName),
-				TableName:    aws.String(tableName),
-				RetentionProperties: &types.RetentionProperties{
-					MagneticStoreRetentionPeriodInDays: aws.Int64(30), // 30 days in magnetic store
-					MemoryStoreRetentionPeriodInHours:  aws.Int64(24), // 24 hours in memory store
-				},
+			err = retry(createAttempts, createBaseSleep, func() error {
+				_, err := client.CreateTable(ctx, &timestreamwrite.CreateTableInput{
+					DatabaseName:        aws.String(databaseName),
+					TableName:           aws.String(tableName),
+					RetentionProperties: retention,
+				})
+				return err
 			})
 			if err != nil {
 				return fmt.Errorf("failed to create table: %w", err)
@@ -118,22 +138,33 @@ func createTableIfNotExists(ctx context.Context, client *timestreamwrite.Client,
 	}
 
 	log.Printf("Table %s already exists in database %s", tableName, databaseName)
+
+	if updateRetention {
+		_, err = client.UpdateTable(ctx, &timestreamwrite.UpdateTableInput{
+			DatabaseName:        aws.String(databaseName),
+			TableName:           aws.String(tableName),
+			RetentionProperties: retention,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update table retention: %w", err)
+		}
+		log.Printf("Table %s retention set to %d hours in memory, %d days in magnetic store", tableName,
+			aws.ToInt64(retention.MemoryStoreRetentionPeriodInHours), aws.ToInt64(retention.MagneticStoreRetentionPeriodInDays))
+	}
 	return nil
 }
 
-// isResourceNotFound checks if an error is a ResourceNotFoundException
+// isResourceNotFound checks if an error is a ResourceNotFoundException. Errors that
+// aren't deserialized into the typed exception, as from some emulators, are matched on
+// their API error code.
 func isResourceNotFound(err error) bool {
-	return err != nil && (isErrorWithCode(err, "ResourceNotFoundException") ||
-		isErrorWithCode(err, "ValidationException") ||
-		isErrorWithCode(err, "InvalidEndpointException"))
-}
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return true
+	}
 
-// isErrorWithCode checks if an error contains a specific code
-func isErrorWithCode(err error, code string) bool {
-	errStr := err.Error()
-	return errStr != "" && (errStr == code ||
-		errStr == "operation error Timestream: CreateDatabase, "+code ||
-		errStr == "operation error Timestream: CreateTable, "+code)
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "ResourceNotFoundException"
 }
 
 // getEnv gets an environment variable or returns a default value
@@ -145,13 +176,27 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
-// retry retries a function with exponential backoff
-// TODO: This function is not currently used but kept for future implementation of retry logic
+// getEnvInt gets an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return n
+}
+
+// retry retries a function with exponential backoff. Each wait is jittered to between
+// half and all of the current delay so concurrent setups don't retry in lockstep.
 func retry(attempts int, sleep time.Duration, f func() error) error {
 	if err := f(); err != nil {
 		if attempts--; attempts > 0 {
-			log.Printf("Retrying after error: %v", err)
-			time.Sleep(sleep)
+			wait := sleep/2 + time.Duration(rand.Int63n(int64(sleep/2)+1))
+			log.Printf("Retrying in %v after error: %v", wait, err)
+			time.Sleep(wait)
 			return retry(attempts, 2*sleep, f)
 		}
 		return err